@@ -0,0 +1,259 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// User roles stored in users.role
+const (
+	userRoleUser  = "user"
+	userRoleAdmin = "admin"
+)
+
+// requireAdmin lets only administrators through
+func (s *Server) requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if currentUser(c).Role != userRoleAdmin {
+			writeError(c, apperr.Forbidden("Administrator access required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// adminUserResponse is a user as seen by administrators
+type adminUserResponse struct {
+	ID                    int32      `json:"id"`
+	Email                 string     `json:"email"`
+	FullName              string     `json:"full_name"`
+	Role                  string     `json:"role"`
+	EmailVerified         bool       `json:"email_verified"`
+	DisabledAt            *time.Time `json:"disabled_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+	ProjectCount          int64      `json:"project_count"`
+	DatasetCount          int64      `json:"dataset_count"`
+	ModelCount            int64      `json:"model_count"`
+}
+
+func newAdminUserResponse(row db.SearchUsersWithCountsRow) adminUserResponse {
+	rsp := adminUserResponse{
+		ID:                    row.ID,
+		Email:                 row.Email,
+		FullName:              row.FullName.String,
+		Role:                  row.Role,
+		EmailVerified:         row.EmailVerifiedAt.Valid,
+		PasswordResetRequired: row.PasswordResetRequired,
+		CreatedAt:             row.CreatedAt.Time,
+		ProjectCount:          row.ProjectCount,
+		DatasetCount:          row.DatasetCount,
+		ModelCount:            row.ModelCount,
+	}
+	if row.DisabledAt.Valid {
+		rsp.DisabledAt = &row.DisabledAt.Time
+	}
+	return rsp
+}
+
+type listUsersRequest struct {
+	pageRequest
+	Search string `form:"search"`
+}
+
+// adminListUsers lists users page by page, optionally searching email and name
+func (s *Server) adminListUsers(c *gin.Context) {
+	var req listUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	cursor, ok := readCursor(c, req.Cursor, "id")
+	if !ok {
+		return
+	}
+
+	rows, err := s.Db.SearchUsersWithCounts(c.Request.Context(), db.SearchUsersWithCountsParams{
+		Search:        optionalText(req.Search),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch users"))
+		return
+	}
+
+	total, err := s.Db.CountUsers(c.Request.Context(), db.CountUsersParams{
+		Search:        optionalText(req.Search),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count users"))
+		return
+	}
+
+	users := make([]adminUserResponse, len(rows))
+	for i, row := range rows {
+		users[i] = newAdminUserResponse(row)
+	}
+	writePage(c, users, req.Limit, total, func(user adminUserResponse) pageCursor {
+		return pageCursor{Sort: "id", ID: user.ID}
+	})
+}
+
+type targetUserRequest struct {
+	UserID int32 `uri:"user_id" binding:"required,min=1"`
+}
+
+// targetUser loads the user named by the :user_id parameter. Admins can't act
+// on their own account here, so they can't lock themselves out by mistake.
+func (s *Server) targetUser(c *gin.Context) (db.User, bool) {
+	var req targetUserRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return db.User{}, false
+	}
+	if req.UserID == currentUserID(c) {
+		writeError(c, apperr.BadRequest("Administrators can't change their own account here"))
+		return db.User{}, false
+	}
+
+	user, err := s.Db.GetUserByID(c.Request.Context(), req.UserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("User not found"))
+			return db.User{}, false
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
+// adminDisableUser blocks the account and ends all its sessions
+func (s *Server) adminDisableUser(c *gin.Context) {
+	user, ok := s.targetUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.Db.DisableUser(c.Request.Context(), user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to disable user"))
+		return
+	}
+
+	if _, err := s.Db.RevokeUserSessions(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// adminEnableUser lifts a previous disable
+func (s *Server) adminEnableUser(c *gin.Context) {
+	user, ok := s.targetUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.Db.EnableUser(c.Request.Context(), user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to enable user"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// adminForcePasswordReset makes the user choose a new password: their
+// sessions end, their API keys are revoked, password login is refused and a
+// reset link is emailed
+func (s *Server) adminForcePasswordReset(c *gin.Context) {
+	user, ok := s.targetUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.Db.SetUserPasswordResetRequired(c.Request.Context(), db.SetUserPasswordResetRequiredParams{
+		ID:                    user.ID,
+		PasswordResetRequired: true,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to update user"))
+		return
+	}
+
+	if _, err := s.Db.RevokeUserSessions(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke sessions"))
+		return
+	}
+
+	// کلیدهای API هم مانند سشن‌ها با رمز عبور افشاشده ساخته شده‌اند
+	if _, err := s.Db.RevokeUserApiKeys(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke API keys"))
+		return
+	}
+
+	if err := s.sendPasswordResetEmail(c.Request.Context(), user); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to send reset email"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "The user has to reset their password before logging in again"})
+}
+
+type setUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// adminSetUserRole grants or removes administrator rights
+func (s *Server) adminSetUserRole(c *gin.Context) {
+	user, ok := s.targetUser(c)
+	if !ok {
+		return
+	}
+
+	var req setUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user, err := s.Db.SetUserRole(c.Request.Context(), db.SetUserRoleParams{
+		ID:   user.ID,
+		Role: req.Role,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to update role"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// adminDeleteUser deletes the user and everything they own
+func (s *Server) adminDeleteUser(c *gin.Context) {
+	user, ok := s.targetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := s.Db.DeleteUserTx(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to delete user"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "User deleted successfully"})
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	admin := store.addAdmin()
+	target := store.addUser()
+	_, adminSecret := store.addAPIKey(admin, noProject, scopeRead, scopeWrite)
+	server := newTestServer(t, store, nil)
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/admin/users"},
+		{http.MethodPost, "/admin/users/" + itoa(target.ID) + "/disable"},
+		{http.MethodPost, "/admin/users/" + itoa(target.ID) + "/enable"},
+		{http.MethodPost, "/admin/users/" + itoa(target.ID) + "/force-password-reset"},
+		{http.MethodPut, "/admin/users/" + itoa(target.ID) + "/role"},
+		{http.MethodDelete, "/admin/users/" + itoa(target.ID)},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+route.path, func(t *testing.T) {
+			recorder := serve(server, route.method, apiPrefix+route.path, nil, func(request *http.Request) {
+				addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+			})
+			requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+
+			// کلید API مدیر هم به مسیرهای مدیریت دسترسی ندارد
+			recorder = serve(server, route.method, apiPrefix+route.path, nil, func(request *http.Request) {
+				request.Header.Set(authorizationHeaderKey, "Bearer "+adminSecret)
+			})
+			requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+
+			recorder = serve(server, route.method, apiPrefix+route.path, nil, nil)
+			requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+		})
+	}
+}
+
+func TestAdminTargetUser(t *testing.T) {
+	store := newFakeStore()
+	admin := store.addAdmin()
+	server := newTestServer(t, store, nil)
+
+	authorize := func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, admin, time.Minute)
+	}
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/admin/users/1000/disable", nil, authorize)
+	requireErrorCode(t, recorder, http.StatusNotFound, apperr.CodeNotFound)
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/admin/users/"+itoa(admin.ID)+"/disable", nil, authorize)
+	requireErrorCode(t, recorder, http.StatusBadRequest, apperr.CodeBadRequest)
+}
+
+func TestAdminForcePasswordReset(t *testing.T) {
+	store := newFakeStore()
+	admin := store.addAdmin()
+	target := store.addUser()
+	_, secret := store.addAPIKey(target, noProject, scopeRead)
+	targetSession, _ := store.addSession(target)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/me", nil, bearer(secret))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/admin/users/"+itoa(target.ID)+"/force-password-reset", strings.NewReader("{}"), func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, admin, time.Minute)
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// سشن‌ها و کلیدهای API کاربر دیگر کار نمی‌کنند
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, bearer(secret))
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, func(request *http.Request) {
+		addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, target, targetSession.ID, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// apiKeyPrefix marks a bearer credential as a personal API key
+	apiKeyPrefix = "sfp_"
+	// apiKeyBytes is the amount of randomness in an API key secret
+	apiKeyBytes = 32
+	// apiKeyDisplayLength is how much of the key is kept in clear for display
+	apiKeyDisplayLength = len(apiKeyPrefix) + 6
+
+	authorizationAPIKeyKey = "api_key"
+)
+
+// API key scopes
+const (
+	scopeRead    = "read"
+	scopeWrite   = "write"
+	scopePredict = "predict"
+)
+
+// predictRoutes need the predict scope instead of write
+var predictRoutes = map[string]bool{
+	http.MethodPost + " " + apiPrefix + "/projects/:project_id/predictions": true,
+}
+
+var (
+	errAPIKeyInvalid = errors.New("API key is invalid")
+	errAPIKeyExpired = errors.New("API key has expired")
+)
+
+// verifyAPIKey looks up an active API key by its secret
+func (s *Server) verifyAPIKey(ctx context.Context, secret string) (db.ApiKey, error) {
+	key, err := s.Db.GetApiKeyBySecretHash(ctx, util.HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return key, errAPIKeyInvalid
+		}
+		return key, err
+	}
+
+	if key.RevokedAt.Valid {
+		return key, errAPIKeyInvalid
+	}
+	if key.ExpiresAt.Valid && time.Now().UTC().After(key.ExpiresAt.Time) {
+		return key, errAPIKeyExpired
+	}
+
+	return key, nil
+}
+
+// requiredScope returns the scope an API key needs for the current route
+func requiredScope(c *gin.Context) string {
+	if predictRoutes[c.Request.Method+" "+c.FullPath()] {
+		return scopePredict
+	}
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return scopeRead
+	}
+	return scopeWrite
+}
+
+func hasScope(key db.ApiKey, scope string) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// inKeyProject tells whether the route works on the project a restricted key
+// is bound to. Only the routes under /projects/:project_id of that project
+// qualify: every other route can reach the owner's data outside the project.
+func inKeyProject(c *gin.Context, key db.ApiKey) bool {
+	projectID, err := strconv.ParseInt(c.Param("project_id"), 10, 32)
+	return err == nil && int32(projectID) == key.ProjectID.Int32
+}
+
+// authenticateAPIKey is the API key branch of authMiddleware. Besides
+// verifying the key it enforces its scopes and project restriction.
+func (s *Server) authenticateAPIKey(c *gin.Context, secret string) {
+	key, err := s.verifyAPIKey(c.Request.Context(), secret)
+	if err != nil {
+		if errors.Is(err, errAPIKeyInvalid) || errors.Is(err, errAPIKeyExpired) {
+			writeError(c, apperr.Unauthorized(err.Error()))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to verify API key"))
+		return
+	}
+
+	if scope := requiredScope(c); !hasScope(key, scope) {
+		writeError(c, apperr.Forbidden("API key is missing the "+scope+" scope"))
+		return
+	}
+
+	if key.ProjectID.Valid && !inKeyProject(c, key) {
+		writeError(c, apperr.Forbidden("API key is restricted to project "+strconv.Itoa(int(key.ProjectID.Int32))))
+		return
+	}
+
+	if !s.loadActiveUser(c, key.UserID) {
+		return
+	}
+
+	if err := s.Db.TouchApiKey(c.Request.Context(), key.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to update API key"))
+		return
+	}
+
+	c.Set(authorizationAPIKeyKey, key)
+	c.Set(authorizationUserIDKey, key.UserID)
+	c.Next()
+}
+
+// userTokenOnly rejects requests authenticated with an API key, so a key
+// can't be used to mint more keys or manage the account's sessions
+func (s *Server) userTokenOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(authorizationAPIKeyKey); ok {
+			writeError(c, apperr.Forbidden("This endpoint can't be used with an API key"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// apiKeyResponse is the public view of an API key. Secret is only set
+// in the response to createAPIKey.
+type apiKeyResponse struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Secret     string     `json:"secret,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ProjectID  *int32     `json:"project_id"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(key db.ApiKey) apiKeyResponse {
+	rsp := apiKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt.Time,
+	}
+	if key.ProjectID.Valid {
+		rsp.ProjectID = &key.ProjectID.Int32
+	}
+	if key.ExpiresAt.Valid {
+		rsp.ExpiresAt = &key.ExpiresAt.Time
+	}
+	if key.LastUsedAt.Valid {
+		rsp.LastUsedAt = &key.LastUsedAt.Time
+	}
+	return rsp
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=read write predict"`
+	// کلید محدود به پروژه فقط روی مسیرهای همان پروژه کار می‌کند
+	ProjectID     *int32 `json:"project_id"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// createAPIKey creates a key and returns its secret. The secret is never shown again.
+func (s *Server) createAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	userID := currentUserID(c)
+
+	// کلید فقط به پروژه‌ای محدود می‌شود که کاربر به آن دسترسی دارد
+	if req.ProjectID != nil {
+		project, err := s.Db.GetProjectByID(c.Request.Context(), *req.ProjectID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(c, apperr.NotFound("project not found"))
+				return
+			}
+			writeError(c, apperr.Wrap(err, "Failed to load project"))
+			return
+		}
+		role, err := s.projectRoleOf(c.Request.Context(), project, userID)
+		if err != nil {
+			writeError(c, apperr.Wrap(err, "Failed to check project access"))
+			return
+		}
+		if role == roleNone {
+			writeError(c, apperr.NotFound("project not found"))
+			return
+		}
+	}
+
+	random, err := util.RandomSecret(apiKeyBytes)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create API key"))
+		return
+	}
+	secret := apiKeyPrefix + random
+
+	arg := db.CreateApiKeyParams{
+		UserID:     userID,
+		Name:       req.Name,
+		Prefix:     secret[:apiKeyDisplayLength],
+		SecretHash: util.HashSecret(secret),
+		Scopes:     req.Scopes,
+		ProjectID:  optionalInt4(req.ProjectID),
+	}
+	if req.ExpiresInDays > 0 {
+		arg.ExpiresAt = pgtype.Timestamp{
+			Time:  time.Now().UTC().AddDate(0, 0, req.ExpiresInDays),
+			Valid: true,
+		}
+	}
+
+	key, err := s.Db.CreateApiKey(c.Request.Context(), arg)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create API key"))
+		return
+	}
+
+	rsp := newAPIKeyResponse(key)
+	rsp.Secret = secret
+	c.JSON(http.StatusCreated, rsp)
+}
+
+// listAPIKeys returns the caller's active API keys page by page
+func (s *Server) listAPIKeys(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "id")
+	if !ok {
+		return
+	}
+
+	keys, err := s.Db.ListApiKeysByUserID(c.Request.Context(), db.ListApiKeysByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch API keys"))
+		return
+	}
+
+	total, err := s.Db.CountApiKeysByUserID(c.Request.Context(), db.CountApiKeysByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count API keys"))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, len(keys))
+	for i, key := range keys {
+		rsp[i] = newAPIKeyResponse(key)
+	}
+	writePage(c, rsp, req.Limit, total, func(key apiKeyResponse) pageCursor {
+		return pageCursor{Sort: "id", ID: key.ID}
+	})
+}
+
+// revokeAPIKey
+func (s *Server) revokeAPIKey(c *gin.Context) {
+	keyID, ok := paramID(c, "api_key_id")
+	if !ok {
+		return
+	}
+
+	revoked, err := s.Db.RevokeApiKey(c.Request.Context(), db.RevokeApiKeyParams{
+		ID:     keyID,
+		UserID: currentUserID(c),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke API key"))
+		return
+	}
+	if revoked == 0 {
+		writeError(c, apperr.NotFound("API key not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "API key revoked successfully"})
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func bearer(secret string) func(*http.Request) {
+	return func(request *http.Request) {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+secret)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	dataset := store.addDataset(user)
+	project := store.addProject(user, visibilityPrivate)
+	_, readOnly := store.addAPIKey(user, noProject, scopeRead)
+	_, readWrite := store.addAPIKey(user, noProject, scopeRead, scopeWrite)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/datasets/"+itoa(dataset.ID), nil, bearer(readOnly))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(server, http.MethodDelete, apiPrefix+"/datasets/"+itoa(dataset.ID), nil, bearer(readOnly))
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+
+	// ساخت پیش‌بینی به دسترسی predict نیاز دارد، نه write
+	body := strings.NewReader(`{"dataset_id":1,"model_id":1}`)
+	recorder = serve(server, http.MethodPost, apiPrefix+"/projects/"+itoa(project.ID)+"/predictions", body, bearer(readWrite))
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+	require.Contains(t, recorder.Body.String(), scopePredict)
+}
+
+func TestAPIKeyRejected(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	revoked, revokedSecret := store.addAPIKey(user, noProject, scopeRead)
+	revoked.RevokedAt = now()
+	store.apiKeys[revoked.ID] = revoked
+
+	expired, expiredSecret := store.addAPIKey(user, noProject, scopeRead)
+	expired.ExpiresAt = pgtype.Timestamp{Time: time.Now().UTC().Add(-time.Minute), Valid: true}
+	store.apiKeys[expired.ID] = expired
+
+	disabled := store.addUser()
+	_, disabledSecret := store.addAPIKey(disabled, noProject, scopeRead)
+	_, err := store.DisableUser(context.Background(), disabled.ID)
+	require.NoError(t, err)
+
+	for name, secret := range map[string]string{
+		"Revoked":      revokedSecret,
+		"Expired":      expiredSecret,
+		"DisabledUser": disabledSecret,
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := serve(server, http.MethodGet, apiPrefix+"/me", nil, bearer(secret))
+			requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+		})
+	}
+}
+
+func TestAPIKeyProjectRestriction(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	project := store.addProject(user, visibilityPrivate)
+	other := store.addProject(user, visibilityPrivate)
+	dataset := store.addDataset(user)
+	model := store.addModel(user)
+	prediction := store.addPrediction(user)
+	_, secret := store.addAPIKey(user, pgtype.Int4{Int32: project.ID, Valid: true}, scopeRead, scopeWrite, scopePredict)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/projects/"+itoa(project.ID), nil, bearer(secret))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// هر مسیری بیرون از پروژه کلید، حتی برای داده‌های خود صاحب کلید، رد می‌شود
+	denied := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/projects/" + itoa(other.ID)},
+		{http.MethodDelete, "/projects/" + itoa(other.ID)},
+		{http.MethodGet, "/datasets/" + itoa(dataset.ID)},
+		{http.MethodDelete, "/datasets/" + itoa(dataset.ID)},
+		{http.MethodGet, "/models/" + itoa(model.ID)},
+		{http.MethodDelete, "/models/" + itoa(model.ID)},
+		{http.MethodGet, "/predictions/" + itoa(prediction.ID)},
+		{http.MethodGet, "/datasets"},
+		{http.MethodPost, "/datasets"},
+		{http.MethodGet, "/models"},
+		{http.MethodGet, "/predictions"},
+		{http.MethodGet, "/projects"},
+		{http.MethodPost, "/projects"},
+		{http.MethodPost, "/teams"},
+		{http.MethodGet, "/teams"},
+		{http.MethodGet, "/me"},
+	}
+	for _, route := range denied {
+		t.Run(route.method+route.path, func(t *testing.T) {
+			recorder := serve(server, route.method, apiPrefix+route.path, strings.NewReader("{}"), bearer(secret))
+			requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+		})
+	}
+
+	// داده‌ها دست نخورده باقی مانده‌اند
+	_, err := store.GetDatasetByID(context.Background(), dataset.ID)
+	require.NoError(t, err)
+}
+
+// datasetUpload builds a multipart dataset upload and the setup that sends it
+func datasetUpload(t *testing.T, name string, content string) (*bytes.Buffer, func(*http.Request)) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("name", name))
+	file, err := writer.CreateFormFile("content", "data.csv")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return body, func(request *http.Request) {
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+	}
+}
+
+func TestAPIKeyProjectUpload(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	project := store.addProject(user, visibilityPrivate)
+	other := store.addProject(user, visibilityPrivate)
+	_, secret := store.addAPIKey(user, pgtype.Int4{Int32: project.ID, Valid: true}, scopeRead, scopeWrite)
+	server := newTestServer(t, store, nil)
+
+	upload := func(path string) *httptest.ResponseRecorder {
+		body, setContentType := datasetUpload(t, "uploaded", "a,b\n1,2\n")
+		return serve(server, http.MethodPost, apiPrefix+path, body, func(request *http.Request) {
+			bearer(secret)(request)
+			setContentType(request)
+		})
+	}
+
+	// کلید محدود به پروژه می‌تواند مستقیماً در پروژه خودش دیتاست بارگذاری کند
+	recorder := upload("/projects/" + itoa(project.ID) + "/datasets/upload")
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	var rsp uploadDatasetResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	dataset, err := store.GetDatasetByID(context.Background(), rsp.DatasetID)
+	require.NoError(t, err)
+	require.Equal(t, ownerID(user), dataset.UserID)
+
+	attached, err := store.IsDatasetInProject(context.Background(), db.IsDatasetInProjectParams{
+		ProjectID: project.ID,
+		DatasetID: dataset.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, attached)
+
+	recorder = upload("/projects/" + itoa(other.ID) + "/datasets/upload")
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+
+	recorder = upload("/datasets")
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+}
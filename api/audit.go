@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Audited resources
+const (
+	auditProject       = "project"
+	auditProjectMember = "project_member"
+	auditDataset       = "dataset"
+	auditModel         = "model"
+	auditPrediction    = "prediction"
+)
+
+// Actions written to the logs table by the audit trail
+const (
+	actionProjectCreated         = "project_created"
+	actionProjectUpdated         = "project_updated"
+	actionProjectDeleted         = "project_deleted"
+	actionProjectMemberAdded     = "project_member_added"
+	actionProjectMemberUpdated   = "project_member_updated"
+	actionProjectMemberRemoved   = "project_member_removed"
+	actionProjectDatasetAttached = "project_dataset_attached"
+	actionProjectDatasetDetached = "project_dataset_detached"
+	actionProjectModelAttached   = "project_model_attached"
+	actionDatasetCreated         = "dataset_created"
+	actionDatasetDeleted         = "dataset_deleted"
+	actionModelDeleted           = "model_deleted"
+	actionPredictionCreated      = "prediction_created"
+	actionPredictionDeleted      = "prediction_deleted"
+)
+
+const auditEventKey = "audit_event"
+
+// auditEvent describes a change made by a handler. Before is nil for
+// creations and After is nil for deletions.
+type auditEvent struct {
+	Action     string
+	ProjectID  pgtype.Int4
+	Resource   string
+	ResourceID int32
+	Before     any
+	After      any
+}
+
+// auditChange is one changed field of the resource
+type auditChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// auditDetails is stored as JSON in logs.details
+type auditDetails struct {
+	Resource   string                 `json:"resource"`
+	ResourceID int32                  `json:"resource_id"`
+	Changes    map[string]auditChange `json:"changes"`
+}
+
+// auditIgnoredFields are left out of the diff; dataset content can be large
+var auditIgnoredFields = map[string]bool{"content": true}
+
+// audit is called by handlers to describe the change they made.
+// auditMiddleware writes it once the handler has finished successfully.
+func audit(c *gin.Context, event auditEvent) {
+	c.Set(auditEventKey, event)
+}
+
+// projectAuditID is the project column of an audit record
+func projectAuditID(projectID int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: projectID, Valid: true}
+}
+
+// auditMiddleware records the change described by the handler, together with
+// the acting user, the client IP, the request ID and the trace ID. Failed requests change
+// nothing and aren't recorded.
+func (s *Server) auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		value, ok := c.Get(auditEventKey)
+		if !ok || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		event := value.(auditEvent)
+
+		changes, err := auditDiff(event.Before, event.After)
+		if err != nil {
+			logger(c).Error("Error building audit diff", "error", err)
+			return
+		}
+		details, err := json.Marshal(auditDetails{
+			Resource:   event.Resource,
+			ResourceID: event.ResourceID,
+			Changes:    changes,
+		})
+		if err != nil {
+			logger(c).Error("Error encoding audit details", "error", err)
+			return
+		}
+
+		// تغییر انجام شده است؛ خطای ثبت لاگ فقط گزارش می‌شود و قطع اتصال
+		// کلاینت یا پایان مهلت درخواست نباید مانع ثبت آن شود
+		_, err = s.Db.CreateLog(context.WithoutCancel(c.Request.Context()), db.CreateLogParams{
+			UserID:    pgtype.Int4{Int32: currentUserID(c), Valid: true},
+			ProjectID: event.ProjectID,
+			Action:    pgtype.Text{String: event.Action, Valid: true},
+			Details:   pgtype.Text{String: string(details), Valid: true},
+			IpAddress: pgtype.Text{String: c.ClientIP(), Valid: true},
+			RequestID: pgtype.Text{String: requestID(c), Valid: requestID(c) != ""},
+			TraceID:   traceID(c),
+		})
+		if err != nil {
+			logger(c).Error("Error writing audit log", "error", err)
+		}
+	}
+}
+
+// auditDiff compares the JSON form of two versions of a resource and returns
+// the fields that differ
+func auditDiff(before, after any) (map[string]auditChange, error) {
+	oldFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]auditChange{}
+	for name, oldValue := range oldFields {
+		if newValue, ok := newFields[name]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[name] = auditChange{Old: oldValue, New: newValue}
+		}
+	}
+	for name, newValue := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			changes[name] = auditChange{New: newValue}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(resource any) (map[string]any, error) {
+	if resource == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for name := range auditIgnoredFields {
+		delete(fields, name)
+	}
+	return fields, nil
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditClientIP(t *testing.T) {
+	for name, tc := range map[string]struct {
+		trustedProxies []string
+		ip             string
+	}{
+		// بدون پراکسی مورد اعتماد، X-Forwarded-For جعلی در لاگ ثبت نمی‌شود
+		"Spoofed": {ip: "192.0.2.1"},
+		// httptest همه درخواست‌ها را از 192.0.2.1 می‌فرستد
+		"TrustedProxy": {trustedProxies: []string{"192.0.2.1"}, ip: "198.51.100.7"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newFakeStore()
+			user := store.addUser()
+			project := store.addProject(user, visibilityPrivate)
+			server := newTestServer(t, store, func(cfg *config.Config) {
+				cfg.TrustedProxies = tc.trustedProxies
+			})
+
+			body, setContentType := datasetUpload(t, "audited", "a,b\n1,2\n")
+			recorder := serve(server, http.MethodPost, apiPrefix+"/projects/"+itoa(project.ID)+"/datasets/upload", body, func(request *http.Request) {
+				addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+				forwardedFor("198.51.100.7")(request)
+				setContentType(request)
+			})
+			require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+			require.Equal(t, 1, store.countLogs(actionDatasetCreated))
+			for _, log := range store.logs {
+				require.Equal(t, tc.ip, log.IpAddress.String)
+			}
+		})
+	}
+}
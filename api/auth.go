@@ -0,0 +1,337 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// refreshTokenBytes is the amount of randomness in an opaque refresh token
+const refreshTokenBytes = 32
+
+var (
+	errAccountDisabled       = errors.New("account is disabled")
+	errPasswordResetRequired = errors.New("a password reset is required, check your email for the reset link")
+)
+
+// passwordLoginAllowed tells whether the account may start a session with its password
+func passwordLoginAllowed(user db.User) error {
+	if user.DisabledAt.Valid {
+		return errAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return errPasswordResetRequired
+	}
+	return nil
+}
+
+// rejectLogin answers 403 for an account that isn't allowed to log in and logs the attempt
+func (s *Server) rejectLogin(c *gin.Context, user db.User, err error) {
+	s.logLoginAttempt(c, pgtype.Int4{Int32: user.ID, Valid: true}, actionLoginFailed, user.Email, err.Error())
+	writeError(c, apperr.Forbidden(err.Error()))
+}
+
+// loginResponse is returned by login and refresh
+type loginResponse struct {
+	UserID                int32        `json:"user_id"`
+	SessionID             int32        `json:"session_id"`
+	AccessToken           string       `json:"access_token"`
+	AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+	User                  userResponse `json:"user"`
+}
+
+// sessionResponse is the public view of a session, without the token hash
+type sessionResponse struct {
+	ID         int32     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	ClientIP   string    `json:"client_ip"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		ClientIP:   session.ClientIp,
+		ExpiresAt:  session.ExpiresAt.Time,
+		LastUsedAt: session.LastUsedAt.Time,
+		CreatedAt:  session.CreatedAt.Time,
+	}
+}
+
+// startSession creates a new session for the user and issues a fresh
+// access/refresh token pair for it
+func (s *Server) startSession(c *gin.Context, user db.User) (loginResponse, error) {
+	refreshToken, err := util.RandomSecret(refreshTokenBytes)
+	if err != nil {
+		return loginResponse{}, err
+	}
+
+	session, err := s.Db.CreateSession(c.Request.Context(), db.CreateSessionParams{
+		UserID:           user.ID,
+		RefreshTokenHash: util.HashSecret(refreshToken),
+		UserAgent:        c.Request.UserAgent(),
+		ClientIp:         c.ClientIP(),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().UTC().Add(s.config.RefreshTokenDuration),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return loginResponse{}, err
+	}
+
+	return s.issueTokens(user, session, refreshToken)
+}
+
+// issueTokens builds the login response for an existing session
+func (s *Server) issueTokens(user db.User, session db.Session, refreshToken string) (loginResponse, error) {
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.ID, session.ID, token.TokenTypeAccess, s.config.AccessTokenDuration)
+	if err != nil {
+		return loginResponse{}, err
+	}
+
+	return loginResponse{
+		UserID:                user.ID,
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt.Time,
+		User:                  newUserResponse(user),
+	}, nil
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// lookupSession finds the active session that belongs to a refresh token
+func (s *Server) lookupSession(ctx context.Context, refreshToken string) (db.Session, error) {
+	session, err := s.Db.GetSessionByRefreshTokenHash(ctx, util.HashSecret(refreshToken))
+	if err != nil {
+		return session, err
+	}
+
+	if session.IsRevoked {
+		return session, errors.New("session is revoked")
+	}
+	if time.Now().UTC().After(session.ExpiresAt.Time) {
+		return session, errors.New("session has expired")
+	}
+
+	return session, nil
+}
+
+// refreshAccessToken rotates the refresh token and issues a new access token
+func (s *Server) refreshAccessToken(c *gin.Context) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	session, err := s.lookupSession(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.checkRefreshTokenReuse(c, req.RefreshToken)
+		}
+		writeError(c, apperr.Unauthorized("Invalid refresh token"))
+		return
+	}
+
+	user, err := s.Db.GetUserByID(c.Request.Context(), session.UserID)
+	if err != nil {
+		writeError(c, apperr.Unauthorized("Invalid refresh token"))
+		return
+	}
+	if user.DisabledAt.Valid {
+		writeError(c, apperr.Unauthorized(errAccountDisabled.Error()))
+		return
+	}
+
+	// هر بار refresh، توکن جدید صادر و توکن قبلی باطل می‌شود
+	refreshToken, err := util.RandomSecret(refreshTokenBytes)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create refresh token"))
+		return
+	}
+
+	// فقط اگر توکن قبلی هنوز همان توکن سشن باشد؛ از دو refresh همزمان فقط یکی موفق می‌شود
+	rotated, err := s.Db.RotateSessionRefreshToken(c.Request.Context(), db.RotateSessionRefreshTokenParams{
+		ID:                  session.ID,
+		RefreshTokenHash:    util.HashSecret(refreshToken),
+		UserAgent:           c.Request.UserAgent(),
+		ClientIp:            c.ClientIP(),
+		OldRefreshTokenHash: session.RefreshTokenHash,
+		Now:                 pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			s.revokeReusedSession(c, session)
+			writeError(c, apperr.Unauthorized("Invalid refresh token"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to refresh session"))
+		return
+	}
+
+	rsp, err := s.issueTokens(user, rotated, refreshToken)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create access token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+// checkRefreshTokenReuse revokes the session a refresh token was rotated out
+// of. Only a copy of the token can be presented after its rotation, and we
+// can't tell whether the copy or the new token is the thief's.
+func (s *Server) checkRefreshTokenReuse(c *gin.Context, refreshToken string) {
+	session, err := s.Db.GetSessionByPreviousRefreshTokenHash(c.Request.Context(), pgtype.Text{
+		String: util.HashSecret(refreshToken),
+		Valid:  true,
+	})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger(c).Error("Error looking up rotated refresh token", "error", err)
+		}
+		return
+	}
+	if !session.IsRevoked {
+		s.revokeReusedSession(c, session)
+	}
+}
+
+// revokeReusedSession ends a session whose refresh token was used twice.
+// Two uses of the same refresh token mean it was probably copied, so
+// neither copy may keep the session alive.
+func (s *Server) revokeReusedSession(c *gin.Context, session db.Session) {
+	_, err := s.Db.RevokeSession(c.Request.Context(), db.RevokeSessionParams{
+		ID:     session.ID,
+		UserID: session.UserID,
+	})
+	if err != nil {
+		logger(c).Error("Error revoking reused session", "session_id", session.ID, "error", err)
+		return
+	}
+	logger(c).Warn("Refresh token reused, session revoked", "session_id", session.ID, "user_id", session.UserID)
+}
+
+// logout revokes the session that owns the given refresh token
+func (s *Server) logout(c *gin.Context) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	session, err := s.lookupSession(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		writeError(c, apperr.Unauthorized("Invalid refresh token"))
+		return
+	}
+
+	_, err = s.Db.RevokeSession(c.Request.Context(), db.RevokeSessionParams{
+		ID:     session.ID,
+		UserID: session.UserID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke session"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Logged out successfully"})
+}
+
+type logoutAllResponse struct {
+	Message string `json:"message"`
+	Revoked int64  `json:"revoked"`
+}
+
+// logoutAll revokes every session of the authenticated user
+func (s *Server) logoutAll(c *gin.Context) {
+	revoked, err := s.Db.RevokeUserSessions(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, logoutAllResponse{Message: "Logged out from all devices", Revoked: revoked})
+}
+
+// listSessions returns the active sessions of the authenticated user, newest
+// first and page by page
+func (s *Server) listSessions(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "-id")
+	if !ok {
+		return
+	}
+
+	sessions, err := s.Db.ListSessionsByUserID(c.Request.Context(), db.ListSessionsByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch sessions"))
+		return
+	}
+
+	total, err := s.Db.CountSessionsByUserID(c.Request.Context(), db.CountSessionsByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count sessions"))
+		return
+	}
+
+	rsp := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		rsp[i] = newSessionResponse(session)
+	}
+	writePage(c, rsp, req.Limit, total, func(session sessionResponse) pageCursor {
+		return pageCursor{Sort: "-id", ID: session.ID}
+	})
+}
+
+// revokeSession revokes one of the authenticated user's sessions
+func (s *Server) revokeSession(c *gin.Context) {
+	sessionID, ok := paramID(c, "session_id")
+	if !ok {
+		return
+	}
+
+	revoked, err := s.Db.RevokeSession(c.Request.Context(), db.RevokeSessionParams{
+		ID:     sessionID,
+		UserID: currentUserID(c),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to revoke session"))
+		return
+	}
+	if revoked == 0 {
+		writeError(c, apperr.NotFound("Session not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Session revoked successfully"})
+}
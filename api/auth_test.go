@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func refreshBody(refreshToken string) *strings.Reader {
+	return strings.NewReader(`{"refresh_token":"` + refreshToken + `"}`)
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	session, refreshToken := store.addSession(user)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(refreshToken), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp loginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, session.ID, rsp.SessionID)
+	require.NotEqual(t, refreshToken, rsp.RefreshToken)
+
+	// توکن دسترسی جدید به همان سشن تعلق دارد
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, func(request *http.Request) {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+rsp.AccessToken)
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestRefreshAccessTokenReplayed(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	session, refreshToken := store.addSession(user)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(refreshToken), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp loginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+
+	// توکن چرخیده‌شده فقط در دست یک کپی است؛ ارائه آن کل سشن را باطل می‌کند
+	recorder = serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(refreshToken), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	session, err := store.GetSessionByID(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsRevoked)
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(rsp.RefreshToken), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, func(request *http.Request) {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+rsp.AccessToken)
+	})
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
+
+func TestRefreshAccessTokenRejected(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	revoked, revokedToken := store.addSession(user)
+	_, err := store.RevokeSession(context.Background(), db.RevokeSessionParams{ID: revoked.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	expiredToken := util.RandomString(32)
+	_, err = store.CreateSession(context.Background(), db.CreateSessionParams{
+		UserID:           user.ID,
+		RefreshTokenHash: util.HashSecret(expiredToken),
+		ExpiresAt:        now(),
+	})
+	require.NoError(t, err)
+
+	for name, refreshToken := range map[string]string{
+		"Unknown": util.RandomString(32),
+		"Revoked": revokedToken,
+		"Expired": expiredToken,
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(refreshToken), nil)
+			requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+		})
+	}
+}
+
+// racingStore rotates the refresh token of a session right before the
+// handler does, like a second request with the same token would
+type racingStore struct {
+	*fakeStore
+}
+
+func (store racingStore) RotateSessionRefreshToken(ctx context.Context, arg db.RotateSessionRefreshTokenParams) (db.Session, error) {
+	first := arg
+	first.RefreshTokenHash = util.HashSecret(util.RandomString(32))
+	if _, err := store.fakeStore.RotateSessionRefreshToken(ctx, first); err != nil {
+		return db.Session{}, err
+	}
+	return store.fakeStore.RotateSessionRefreshToken(ctx, arg)
+}
+
+func TestRefreshAccessTokenReused(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	session, refreshToken := store.addSession(user)
+	server := newTestServer(t, racingStore{store}, nil)
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/auth/refresh", refreshBody(refreshToken), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	// سشنی که توکن آن دو بار استفاده شده کاملاً باطل می‌شود
+	session, err := store.GetSessionByID(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsRevoked)
+}
+
+func TestLogoutEndsAccessTokens(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	session, refreshToken := store.addSession(user)
+	server := newTestServer(t, store, nil)
+
+	authorize := func(request *http.Request) {
+		addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, session.ID, time.Minute)
+	}
+	recorder := serve(server, http.MethodGet, apiPrefix+"/me", nil, authorize)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/auth/logout", refreshBody(refreshToken), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, authorize)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/auth/logout", refreshBody(refreshToken), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
+
+func TestLogoutAllEndsAccessTokens(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	other, _ := store.addSession(user)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/auth/logout-all", nil, func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp logoutAllResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.EqualValues(t, 2, rsp.Revoked)
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, func(request *http.Request) {
+		addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, other.ID, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
+
+func TestRevokeSession(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	stranger := store.addUser()
+	session, _ := store.addSession(user)
+	strangerSession, _ := store.addSession(stranger)
+	server := newTestServer(t, store, nil)
+
+	authorize := func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+	}
+
+	recorder := serve(server, http.MethodDelete, apiPrefix+"/sessions/abc", nil, authorize)
+	requireErrorCode(t, recorder, http.StatusBadRequest, apperr.CodeBadRequest)
+
+	// سشن کاربر دیگر برای این کاربر وجود ندارد
+	recorder = serve(server, http.MethodDelete, apiPrefix+"/sessions/"+itoa(strangerSession.ID), nil, authorize)
+	requireErrorCode(t, recorder, http.StatusNotFound, apperr.CodeNotFound)
+
+	recorder = serve(server, http.MethodDelete, apiPrefix+"/sessions/"+itoa(session.ID), nil, authorize)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, func(request *http.Request) {
+		addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, session.ID, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Context keys under which the authorization middlewares store the loaded resource
+const (
+	projectKey    = "project"
+	datasetKey    = "dataset"
+	modelKey      = "model"
+	predictionKey = "prediction"
+	teamKey       = "team"
+)
+
+// resourceLoader loads a resource by ID and returns it together with its owner's user ID
+type resourceLoader func(ctx context.Context, id int32) (resource any, ownerID int32, err error)
+
+// requireOwner parses the ID from the URL parameter, loads the resource and
+// aborts with 404 if it doesn't exist or 403 if the caller doesn't own it.
+// On success the resource is stored in the context under key.
+func (s *Server) requireOwner(param, key string, load resourceLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c, param)
+		if !ok {
+			return
+		}
+
+		resource, ownerID, err := load(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(c, apperr.NotFound(key+" not found"))
+				return
+			}
+			writeError(c, apperr.Wrap(err, "Failed to load "+key))
+			return
+		}
+
+		if ownerID != currentUserID(c) {
+			writeError(c, apperr.Forbidden("You don't have access to this "+key))
+			return
+		}
+
+		c.Set(key, resource)
+		c.Next()
+	}
+}
+
+// paramID parses a positive ID from a URL parameter. IDs outside the int32
+// range are rejected instead of wrapping around to another row.
+func paramID(c *gin.Context, param string) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 32)
+	if err != nil || id < 1 {
+		writeError(c, apperr.BadRequest("Invalid "+param+" format"))
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// ownerOf returns the owner of a resource whose user_id column is nullable.
+// Rows without an owner belong to nobody, so no caller can match them.
+func ownerOf(userID pgtype.Int4) int32 {
+	if !userID.Valid {
+		return 0
+	}
+	return userID.Int32
+}
+
+func (s *Server) loadDataset(ctx context.Context, id int32) (any, int32, error) {
+	dataset, err := s.Db.GetDatasetByID(ctx, id)
+	return dataset, ownerOf(dataset.UserID), err
+}
+
+func (s *Server) loadModel(ctx context.Context, id int32) (any, int32, error) {
+	model, err := s.Db.GetModelByID(ctx, id)
+	return model, ownerOf(model.UserID), err
+}
+
+func (s *Server) loadPrediction(ctx context.Context, id int32) (any, int32, error) {
+	prediction, err := s.Db.GetPredictionByID(ctx, id)
+	return prediction, ownerOf(prediction.UserID), err
+}
+
+func (s *Server) loadTeam(ctx context.Context, id int32) (any, int32, error) {
+	team, err := s.Db.GetTeamByID(ctx, id)
+	return team, team.OwnerUserID, err
+}
+
+// isTeamMember reports whether the user has accepted membership of the team
+func (s *Server) isTeamMember(ctx context.Context, teamID, userID int32) (bool, error) {
+	member, err := s.Db.GetTeamMember(ctx, db.GetTeamMemberParams{
+		TeamID: teamID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return member.AcceptedAt.Valid, nil
+}
+
+// teamMember only lets accepted members of :team_id through
+func (s *Server) teamMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		teamID, ok := paramID(c, "team_id")
+		if !ok {
+			return
+		}
+
+		team, err := s.Db.GetTeamByID(c.Request.Context(), teamID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(c, apperr.NotFound("team not found"))
+				return
+			}
+			writeError(c, apperr.Wrap(err, "Failed to load team"))
+			return
+		}
+
+		member, err := s.isTeamMember(c.Request.Context(), team.ID, currentUserID(c))
+		if err != nil {
+			writeError(c, apperr.Wrap(err, "Failed to check team membership"))
+			return
+		}
+		if !member {
+			writeError(c, apperr.NotFound("team not found"))
+			return
+		}
+
+		c.Set(teamKey, team)
+		c.Next()
+	}
+}
+
+// teamOwner only lets the owner of :team_id through
+func (s *Server) teamOwner() gin.HandlerFunc {
+	return s.requireOwner("team_id", teamKey, s.loadTeam)
+}
+
+// datasetOwner only lets the owner of :dataset_id through
+func (s *Server) datasetOwner() gin.HandlerFunc {
+	return s.requireOwner("dataset_id", datasetKey, s.loadDataset)
+}
+
+// modelOwner only lets the owner of :model_id through
+func (s *Server) modelOwner() gin.HandlerFunc {
+	return s.requireOwner("model_id", modelKey, s.loadModel)
+}
+
+// predictionOwner only lets the owner of :prediction_id through
+func (s *Server) predictionOwner() gin.HandlerFunc {
+	return s.requireOwner("prediction_id", predictionKey, s.loadPrediction)
+}
+
+// Accessors for the resources stored by the middlewares above
+
+func authorizedProject(c *gin.Context) db.Project {
+	return c.MustGet(projectKey).(db.Project)
+}
+
+func authorizedDataset(c *gin.Context) db.Dataset {
+	return c.MustGet(datasetKey).(db.Dataset)
+}
+
+func authorizedModel(c *gin.Context) db.Model {
+	return c.MustGet(modelKey).(db.Model)
+}
+
+func authorizedPrediction(c *gin.Context) db.Prediction {
+	return c.MustGet(predictionKey).(db.Prediction)
+}
+
+func authorizedTeam(c *gin.Context) db.Team {
+	return c.MustGet(teamKey).(db.Team)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireOwner(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	stranger := store.addUser()
+
+	resources := map[string]int32{
+		"datasets":    store.addDataset(owner).ID,
+		"models":      store.addModel(owner).ID,
+		"predictions": store.addPrediction(owner).ID,
+	}
+
+	for resource, id := range resources {
+		t.Run(resource, func(t *testing.T) {
+			server := newTestServer(t, store, nil)
+			path := apiPrefix + "/" + resource + "/"
+
+			get := func(user string, param string) int {
+				caller := owner
+				if user == "stranger" {
+					caller = stranger
+				}
+				recorder := serve(server, http.MethodGet, path+param, nil, func(request *http.Request) {
+					addAuthorization(t, request, server, authorizationTypeBearer, caller, time.Minute)
+				})
+				return recorder.Code
+			}
+
+			require.Equal(t, http.StatusOK, get("owner", itoa(id)))
+			require.Equal(t, http.StatusForbidden, get("stranger", itoa(id)))
+			require.Equal(t, http.StatusNotFound, get("owner", "999999"))
+
+			// شناسه‌های خارج از بازه int32 نباید به ردیف دیگری برسند
+			for _, param := range []string{"abc", "0", "-1", "4294967297", "99999999999999999999"} {
+				recorder := serve(server, http.MethodGet, path+param, nil, func(request *http.Request) {
+					addAuthorization(t, request, server, authorizationTypeBearer, owner, time.Minute)
+				})
+				requireErrorCode(t, recorder, http.StatusBadRequest, apperr.CodeBadRequest)
+			}
+		})
+	}
+}
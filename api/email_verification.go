@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/mail"
+	"github.com/faezefz/SFP_website/throttle"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// verificationTokenBytes is the amount of randomness in an email verification token
+const verificationTokenBytes = 32
+
+// resendPolicy limits how often a user can ask for another verification
+// email. Every request counts, and the window is short because a user who
+// lost the first email shouldn't wait long.
+var resendPolicy = throttle.Policy{
+	FreeFailures:    2,
+	BaseDelay:       time.Minute,
+	MaxFailures:     5,
+	LockoutDuration: 15 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+// sendVerificationEmail creates a verification token for the user and mails the link
+func (s *Server) sendVerificationEmail(ctx context.Context, user db.User) error {
+	verificationToken, err := util.RandomSecret(verificationTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Db.CreateEmailVerificationToken(ctx, db.CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(verificationToken),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().UTC().Add(s.config.EmailVerificationDuration),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.config.AppBaseURL + "/verify-email?token=" + url.QueryEscape(verificationToken)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome! Please confirm your email address by opening this link:\n%s\n\n"+
+			"The link expires in %s. If you didn't sign up, you can ignore this email.\n",
+			link, s.config.EmailVerificationDuration),
+	})
+}
+
+type verifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// verifyEmail confirms the email address with the token from the verification link
+func (s *Server) verifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	invalid := apperr.BadRequest("Invalid or expired verification token")
+
+	verificationToken, err := s.Db.GetEmailVerificationTokenByHash(c.Request.Context(), util.HashSecret(req.Token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, invalid)
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch verification token"))
+		return
+	}
+	if verificationToken.UsedAt.Valid || time.Now().UTC().After(verificationToken.ExpiresAt.Time) {
+		writeError(c, invalid)
+		return
+	}
+
+	user, err := s.Db.VerifyEmailTx(c.Request.Context(), verificationToken.ID, verificationToken.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrTokenAlreadyUsed) {
+			writeError(c, invalid)
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to verify email"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// resendVerificationEmail sends a fresh verification link to the current user
+func (s *Server) resendVerificationEmail(c *gin.Context) {
+	user, err := s.Db.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		writeError(c, apperr.BadRequest("Email is already verified"))
+		return
+	}
+
+	reservation, err := s.resendLimiter.Reserve(c.Request.Context(), "verify-resend:"+strconv.Itoa(int(user.ID)))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to check verification emails"))
+		return
+	}
+	if reservation.Wait > 0 {
+		retryAfter := int(math.Ceil(reservation.Wait.Seconds()))
+		writeError(c, apperr.RateLimited("Too many verification emails, please try again later", retryAfter))
+		return
+	}
+
+	if err := s.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to send verification email"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Verification email has been sent"})
+}
+
+// requireVerifiedEmail rejects users who haven't verified their email yet,
+// when RequireVerifiedEmail is enabled
+func (s *Server) requireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.config.RequireVerifiedEmail {
+			c.Next()
+			return
+		}
+
+		user, err := s.Db.GetUserByID(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+			return
+		}
+		if !user.EmailVerifiedAt.Valid {
+			writeError(c, apperr.Forbidden("Please verify your email address first"))
+			return
+		}
+
+		c.Next()
+	}
+}
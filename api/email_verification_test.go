@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResendVerificationEmailThrottle(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	other := store.addUser()
+	for _, unverified := range []int32{user.ID, other.ID} {
+		u := store.users[unverified]
+		u.EmailVerifiedAt = pgtype.Timestamp{}
+		store.users[unverified] = u
+	}
+	server := newTestServer(t, store, nil)
+
+	resend := func(user int32) *httptest.ResponseRecorder {
+		return serve(server, http.MethodPost, apiPrefix+"/verify-email/resend", nil, func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, store.users[user], time.Minute)
+		})
+	}
+
+	for i := 0; i < resendPolicy.FreeFailures+1; i++ {
+		recorder := resend(user.ID)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	require.Len(t, sentEmails(t, server.config), resendPolicy.FreeFailures+1)
+
+	recorder := resend(user.ID)
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+	require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+	require.Len(t, sentEmails(t, server.config), resendPolicy.FreeFailures+1)
+
+	// محدودیت به ازای هر کاربر است
+	recorder = resend(other.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// خطاهای اعتبارسنجی با نام فیلد در JSON گزارش می‌شوند، نه نام فیلد Go
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(apperr.FieldName)
+	}
+}
+
+// writeError aborts the request with the error envelope. The underlying error
+// is attached with c.Error, so the access log records it.
+func writeError(c *gin.Context, err *apperr.Error) {
+	if err.Err != nil {
+		c.Error(err.Err)
+	}
+	if err.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(err.RetryAfter))
+	}
+
+	body := *err
+	body.RequestID = requestID(c)
+	c.AbortWithStatusJSON(err.Status, body)
+}
+
+// notFound answers requests that match no route
+func notFound(c *gin.Context) {
+	writeError(c, apperr.NotFound("Route not found"))
+}
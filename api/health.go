@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/faezefz/SFP_website/buildinfo"
+	"github.com/faezefz/SFP_website/db/migration"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// readinessTimeout bounds all readiness checks together
+const readinessTimeout = 3 * time.Second
+
+// readinessCheck is one dependency the server needs to handle requests
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+// readinessResponse reports each check as "ok" or "fail"
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// healthz answers as long as the process is running
+func (s *Server) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// readyz answers 503 while any dependency is unavailable, so the load
+// balancer stops sending requests to this instance. Failures are logged but
+// only reported as "fail", since the errors can name internal hosts.
+func (s *Server) readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	status, ready := http.StatusOK, "ready"
+	checks := map[string]string{}
+	for _, check := range s.readinessChecks() {
+		if err := check.check(ctx); err != nil {
+			logger(c).Warn("Readiness check failed", "check", check.name, "error", err)
+			status, ready = http.StatusServiceUnavailable, "not ready"
+			checks[check.name] = "fail"
+			continue
+		}
+		checks[check.name] = "ok"
+	}
+
+	c.JSON(status, readinessResponse{Status: ready, Checks: checks})
+}
+
+func (s *Server) readinessChecks() []readinessCheck {
+	checks := []readinessCheck{
+		{"database", s.Db.Ping},
+		{"migrations", s.checkMigrations},
+		{"storage", s.checkStorage},
+	}
+	// صف حذف حساب فقط در نمونه‌ای که آن را اجرا می‌کند بررسی می‌شود
+	if s.config.ErasureJobEnabled {
+		checks = append(checks, readinessCheck{"erasure_queue", s.checkErasureBacklog})
+	}
+	return checks
+}
+
+// checkMigrations makes sure the database schema is the one this build expects
+func (s *Server) checkMigrations(ctx context.Context) error {
+	latest, err := migration.LatestVersion()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := s.Db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d failed halfway", version)
+	}
+	if version != int64(latest) {
+		return fmt.Errorf("database is at migration %d, expected %d", version, latest)
+	}
+	return nil
+}
+
+// checkStorage makes sure files can be written to the local storage directory
+func (s *Server) checkStorage(ctx context.Context) error {
+	if err := os.MkdirAll(s.config.StorageDir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(s.config.StorageDir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// checkErasureBacklog fails when the erasure job falls too far behind
+func (s *Server) checkErasureBacklog(ctx context.Context) error {
+	due, err := s.Db.CountDueErasureRequests(ctx, pgtype.Timestamp{Time: time.Now().UTC(), Valid: true})
+	if err != nil {
+		return err
+	}
+	if due > s.config.ErasureMaxBacklog {
+		return fmt.Errorf("%d erasure requests are due, more than %d", due, s.config.ErasureMaxBacklog)
+	}
+	return nil
+}
+
+// version reports which build is running
+func (s *Server) version(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// logger returns the logger for the request, with the request ID, the route
+// and, once known, the trace, the user and the project on every line
+func logger(c *gin.Context) *slog.Logger {
+	args := []any{"request_id", requestID(c), "route", c.FullPath()}
+	if traceID := traceID(c); traceID.Valid {
+		args = append(args, "trace_id", traceID.String)
+	}
+	if userID, ok := c.Get(authorizationUserIDKey); ok {
+		args = append(args, "user_id", userID)
+	}
+	if value, ok := c.Get(projectKey); ok {
+		if project, ok := value.(db.Project); ok {
+			args = append(args, "project_id", project.ID)
+		}
+	}
+	return slog.Default().With(args...)
+}
+
+// accessLogMiddleware writes one line per request. Handlers keep the messages
+// sent to clients generic and attach the underlying error with c.Error, which
+// is logged here.
+func accessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		args := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, "error", c.Errors.String())
+		}
+		logger(c).Log(c.Request.Context(), level, "request", args...)
+	}
+}
+
+// recoveryMiddleware answers 500 when a handler panics and logs the panic
+// with its stack trace
+func recoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logger(c).Error("Handler panicked", "panic", err, "stack", string(debug.Stack()))
+		writeError(c, apperr.New(http.StatusInternalServerError, apperr.CodeInternal, "Internal server error"))
+	})
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/throttle"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Actions written to the logs table for login attempts
+const (
+	actionLoginSucceeded = "login_succeeded"
+	actionLoginFailed    = "login_failed"
+	actionLoginBlocked   = "login_blocked"
+)
+
+// ipFailureFactor lets an IP fail more often than a single email, since
+// many users can share one address behind NAT
+const ipFailureFactor = 5
+
+// newAttemptStore keeps the counted attempts on the configured store
+func newAttemptStore(config config.Config, store db.Store) throttle.Store {
+	if config.LoginThrottleStore == "postgres" {
+		return throttle.NewPostgresStore(store)
+	}
+	return throttle.NewMemoryStore()
+}
+
+// newLoginLimiters builds the per-email and per-IP login limiters
+func newLoginLimiters(config config.Config, attempts throttle.Store) (emailLimiter, ipLimiter *throttle.Limiter) {
+	policy := throttle.Policy{
+		FreeFailures:    config.LoginFreeFailures,
+		BaseDelay:       time.Second,
+		MaxFailures:     config.LoginMaxFailures,
+		LockoutDuration: config.LoginLockoutDuration,
+		Window:          config.LoginFailureWindow,
+	}
+	ipPolicy := policy
+	ipPolicy.FreeFailures *= ipFailureFactor
+	ipPolicy.MaxFailures *= ipFailureFactor
+
+	return throttle.NewLimiter(attempts, policy), throttle.NewLimiter(attempts, ipPolicy)
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipAttemptKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// loginAttemptDetails is stored as JSON in logs.details
+type loginAttemptDetails struct {
+	Email     string `json:"email"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// logLoginAttempt writes a login attempt to the logs table. A failure to
+// log must not break the login itself, so errors are only printed.
+func (s *Server) logLoginAttempt(c *gin.Context, userID pgtype.Int4, action, email, reason string) {
+	details, err := json.Marshal(loginAttemptDetails{
+		Email:     email,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Reason:    reason,
+	})
+	if err != nil {
+		logger(c).Error("Error encoding login attempt", "error", err)
+		return
+	}
+
+	// گزارش ورود حتی پس از قطع اتصال کلاینت ثبت می‌شود
+	_, err = s.Db.CreateLog(context.WithoutCancel(c.Request.Context()), db.CreateLogParams{
+		UserID:    userID,
+		Action:    pgtype.Text{String: action, Valid: true},
+		Details:   pgtype.Text{String: string(details), Valid: true},
+		IpAddress: pgtype.Text{String: c.ClientIP(), Valid: true},
+		RequestID: pgtype.Text{String: requestID(c), Valid: requestID(c) != ""},
+		TraceID:   traceID(c),
+	})
+	if err != nil {
+		logger(c).Error("Error logging login attempt", "error", err)
+	}
+}
+
+// loginAttempt is a login attempt counted against the email and the client
+// IP before the credentials are checked, so parallel requests can't all pass
+// the limiters before any failure is recorded
+type loginAttempt struct {
+	email    throttle.Reservation
+	ip       throttle.Reservation
+	emailKey string
+	ipKey    string
+	failed   bool
+}
+
+// reserveLoginAttempt counts the attempt, or answers 429 with Retry-After and
+// returns nil while the email or the client IP is backing off or locked out.
+// The caller must defer finishLoginAttempt.
+func (s *Server) reserveLoginAttempt(c *gin.Context, email string) *loginAttempt {
+	attempt := &loginAttempt{emailKey: emailAttemptKey(email), ipKey: ipAttemptKey(c)}
+
+	var err error
+	attempt.email, err = s.emailLimiter.Reserve(c.Request.Context(), attempt.emailKey)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to check login attempts"))
+		return nil
+	}
+	if attempt.email.Wait == 0 {
+		attempt.ip, err = s.ipLimiter.Reserve(c.Request.Context(), attempt.ipKey)
+		if err != nil {
+			s.finishLoginAttempt(c, &loginAttempt{emailKey: attempt.emailKey, email: attempt.email})
+			writeError(c, apperr.Wrap(err, "Failed to check login attempts"))
+			return nil
+		}
+	}
+
+	wait := max(attempt.email.Wait, attempt.ip.Wait)
+	if wait == 0 {
+		return attempt
+	}
+
+	// تلاشی که رد می‌شود شمرده نمی‌شود
+	if attempt.email.Wait == 0 {
+		s.finishLoginAttempt(c, &loginAttempt{emailKey: attempt.emailKey, email: attempt.email})
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	writeError(c, apperr.RateLimited("Too many failed login attempts, please try again later", retryAfter))
+	return nil
+}
+
+// finishLoginAttempt releases the reservation of an attempt that didn't fail
+func (s *Server) finishLoginAttempt(c *gin.Context, attempt *loginAttempt) {
+	if attempt.failed {
+		return
+	}
+
+	ctx := context.WithoutCancel(c.Request.Context())
+	if attempt.email.Failures > 0 {
+		if err := s.emailLimiter.Release(ctx, attempt.emailKey, attempt.email); err != nil {
+			logger(c).Error("Error releasing login attempt", "error", err)
+		}
+	}
+	if attempt.ip.Failures > 0 {
+		if err := s.ipLimiter.Release(ctx, attempt.ipKey, attempt.ip); err != nil {
+			logger(c).Error("Error releasing login attempt", "error", err)
+		}
+	}
+}
+
+// loginFailed keeps the failure counted against the email and the client IP.
+// The attempt that locks either of them out is logged as login_blocked once;
+// the requests refused during the lockout aren't logged.
+func (s *Server) loginFailed(c *gin.Context, attempt *loginAttempt, userID pgtype.Int4, email, reason string) {
+	attempt.failed = true
+	s.logLoginAttempt(c, userID, actionLoginFailed, email, reason)
+
+	if s.emailLimiter.StartsLockout(attempt.email) || s.ipLimiter.StartsLockout(attempt.ip) {
+		s.logLoginAttempt(c, userID, actionLoginBlocked, email, "too many failed attempts")
+	}
+}
+
+// loginSucceeded clears the failures of the email. The IP count is kept, so
+// an attacker can't reset it by logging into their own account.
+func (s *Server) loginSucceeded(c *gin.Context, user db.User) {
+	if err := s.emailLimiter.Reset(c.Request.Context(), emailAttemptKey(user.Email)); err != nil {
+		logger(c).Error("Error resetting login attempts", "error", err)
+	}
+
+	s.logLoginAttempt(c, pgtype.Int4{Int32: user.ID, Valid: true}, actionLoginSucceeded, user.Email, "")
+}
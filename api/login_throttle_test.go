@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+// throttleLogins keeps the test limits small
+func throttleLogins(cfg *config.Config) {
+	cfg.LoginFreeFailures = 2
+	cfg.LoginMaxFailures = 3
+	cfg.LoginLockoutDuration = time.Minute
+	cfg.LoginFailureWindow = time.Hour
+}
+
+func TestLoginThrottle(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, throttleLogins)
+	user := store.addUser()
+	password := util.RandomPassword()
+	hash, err := server.passwordHasher.Hash(password)
+	require.NoError(t, err)
+	user = store.setPassword(user, hash)
+
+	// یک ورود موفق تلاشی از ایمیل یا IP کم نمی‌کند
+	recorder := serve(server, http.MethodPost, apiPrefix+"/login", loginBody(user.Email, password), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	for i := 0; i < 3; i++ {
+		recorder = serve(server, http.MethodPost, apiPrefix+"/login", loginBody(user.Email, "wrong-password"), nil)
+		requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+	}
+
+	recorder = serve(server, http.MethodPost, apiPrefix+"/login", loginBody(user.Email, password), nil)
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+	require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	// قفل شدن فقط یک بار لاگ می‌شود، نه برای هر پاسخ 429
+	require.Equal(t, 3, store.countLogs(actionLoginFailed))
+	require.Equal(t, 1, store.countLogs(actionLoginBlocked))
+}
+
+func TestLoginThrottleParallel(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, throttleLogins)
+	email := util.RandomEmail()
+
+	const attempts = 20
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve(server, http.MethodPost, apiPrefix+"/login", loginBody(email, "wrong-password"), nil).Code
+		}()
+	}
+	wg.Wait()
+
+	// مانند تلاش‌های پشت سر هم، فقط تلاش‌های رایگان و یک تلاش بعد از آن‌ها بررسی می‌شوند
+	unauthorized := 0
+	for _, code := range codes {
+		if code == http.StatusUnauthorized {
+			unauthorized++
+			continue
+		}
+		require.Equal(t, http.StatusTooManyRequests, code)
+	}
+	require.Equal(t, 3, unauthorized)
+
+	// درخواست‌های رد شده لاگ نمی‌شوند
+	require.Equal(t, 3, store.countLogs(actionLoginFailed))
+	require.Equal(t, 1, store.countLogs(actionLoginBlocked))
+}
+
+func TestLoginThrottleSpoofedIP(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, throttleLogins)
+
+	// X-Forwarded-For جعلی کلید محدودیت IP را عوض نمی‌کند
+	ipFreeFailures := server.config.LoginFreeFailures * ipFailureFactor
+	for i := 0; i < ipFreeFailures+1; i++ {
+		recorder := serve(server, http.MethodPost, apiPrefix+"/login", loginBody(util.RandomEmail(), "wrong-password"), forwardedFor("198.51.100."+strconv.Itoa(i)))
+		requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+	}
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/login", loginBody(util.RandomEmail(), "wrong-password"), forwardedFor("203.0.113.1"))
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// checkCurrentPassword compares the password the user typed with their hash.
+// Users provisioned through SSO have no password and can't pass this check.
+func (s *Server) checkCurrentPassword(user db.User, password string) bool {
+	return s.passwordHasher.Verify(user.PasswordHash, password) == nil
+}
+
+// upgradePasswordHash rehashes a correct password whose hash was made with an
+// older algorithm or weaker parameters. Login goes on if this fails.
+func (s *Server) upgradePasswordHash(c *gin.Context, user db.User, password string) {
+	if !s.passwordHasher.NeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger(c).Error("Error rehashing password", "error", err)
+		return
+	}
+
+	// فقط اگر رمز عبور در این فاصله تغییر نکرده باشد
+	_, err = s.Db.RehashUserPassword(c.Request.Context(), db.RehashUserPasswordParams{
+		NewHash: hashedPassword,
+		ID:      user.ID,
+		OldHash: user.PasswordHash,
+	})
+	if err != nil {
+		logger(c).Error("Error rehashing password", "error", err)
+	}
+}
+
+// getMe returns the profile of the authenticated user
+func (s *Server) getMe(c *gin.Context) {
+	c.JSON(http.StatusOK, newUserResponse(currentUser(c)))
+}
+
+type updateMeRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	FullName string `json:"full_name"`
+}
+
+// updateMe changes the full name and email. A new email has to be verified
+// again, so a verification link is sent to it.
+func (s *Server) updateMe(c *gin.Context) {
+	var req updateMeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	// فقط تغییر بزرگی حروف، ایمیل تأییدشده را از اعتبار نمی‌اندازد؛
+	// کوئری همین تصمیم را برای email_verified_at به کار می‌برد
+	current := currentUser(c)
+	emailChanged := !strings.EqualFold(req.Email, current.Email)
+
+	// لینک‌های تأیید قبلی برای ایمیل قدیمی بودند و در همان تراکنش باطل می‌شوند
+	user, err := s.Db.UpdateUserProfileTx(c.Request.Context(), db.UpdateUserProfileParams{
+		ID:           current.ID,
+		FullName:     pgtype.Text{String: req.FullName, Valid: req.FullName != ""},
+		Email:        req.Email,
+		EmailChanged: emailChanged,
+	})
+	if err != nil {
+		if apperr.IsUniqueViolation(err) {
+			writeError(c, apperr.Conflict("Email is already in use"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to update profile"))
+		return
+	}
+
+	if emailChanged {
+		if err := s.sendVerificationEmail(c.Request.Context(), user); err != nil {
+			logger(c).Error("Error sending verification email", "error", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// changeMyPassword sets a new password after checking the current one, and
+// ends all sessions and revokes all API keys, so other devices and scripts
+// have to be signed in again
+func (s *Server) changeMyPassword(c *gin.Context) {
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user := currentUser(c)
+	if !s.checkCurrentPassword(user, req.CurrentPassword) {
+		writeError(c, apperr.Unauthorized("Current password is incorrect"))
+		return
+	}
+	if err := s.passwordPolicy.Check(req.NewPassword); err != nil {
+		writeError(c, apperr.BadRequest(err.Error()))
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to hash password"))
+		return
+	}
+
+	_, err = s.Db.ChangePasswordTx(c.Request.Context(), db.ChangePasswordTxParams{
+		User:         user,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to change password"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Password has been changed, please log in again"})
+}
+
+type deleteMeRequest struct {
+	Password string `json:"password"`
+}
+
+// deleteMe deletes the account and everything the user owns. The password
+// is asked again, unless the account has none because it came from SSO.
+func (s *Server) deleteMe(c *gin.Context) {
+	// بدنه درخواست برای حساب‌های بدون رمز عبور می‌تواند خالی باشد
+	var req deleteMeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user := currentUser(c)
+	if user.PasswordHash != "" && !s.checkCurrentPassword(user, req.Password) {
+		writeError(c, apperr.Unauthorized("Password is incorrect"))
+		return
+	}
+
+	if err := s.Db.DeleteUserTx(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to delete account"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Account deleted successfully"})
+}
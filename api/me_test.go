@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func updateMeBody(email string) *strings.Reader {
+	return strings.NewReader(`{"email":"` + email + `","full_name":"Test User"}`)
+}
+
+func TestUpdateMeEmail(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	authorize := func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+	}
+
+	// تغییر بزرگی حروف، ایمیل تأییدشده را از اعتبار نمی‌اندازد
+	recorder := serve(server, http.MethodPut, apiPrefix+"/me", updateMeBody(strings.ToUpper(user.Email)), authorize)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp userResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, strings.ToUpper(user.Email), rsp.Email)
+	require.True(t, rsp.EmailVerified)
+	require.Empty(t, sentEmails(t, server.config))
+
+	recorder = serve(server, http.MethodPut, apiPrefix+"/me", updateMeBody(util.RandomEmail()), authorize)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.False(t, rsp.EmailVerified)
+	require.Len(t, sentEmails(t, server.config), 1)
+}
+
+func TestChangeMyPassword(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+	password := util.RandomPassword()
+	hash, err := server.passwordHasher.Hash(password)
+	require.NoError(t, err)
+	user = store.setPassword(user, hash)
+	session, _ := store.addSession(user)
+	_, secret := store.addAPIKey(user, noProject, scopeRead)
+
+	authorize := func(request *http.Request) {
+		addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, session.ID, time.Minute)
+	}
+
+	newPassword := util.RandomPassword()
+	body := strings.NewReader(`{"current_password":"` + password + `","new_password":"` + newPassword + `"}`)
+	recorder := serve(server, http.MethodPost, apiPrefix+"/me/password", body, authorize)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	user, err = store.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, server.checkCurrentPassword(user, newPassword))
+
+	// سشن‌ها و کلیدهای API با رمز عبور قبلی ساخته شده بودند
+	recorder = serve(server, http.MethodGet, apiPrefix+"/me", nil, authorize)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/datasets", nil, bearer(secret))
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "sfp"
+
+// Job kinds and outcomes of sfp_job_duration_seconds
+const (
+	jobPrediction = "prediction"
+
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// domainStatsTimeout bounds the queries run on every scrape
+const domainStatsTimeout = 5 * time.Second
+
+// metrics holds the Prometheus collectors of one server. Each server has its
+// own registry, so creating several servers, e.g. in tests, doesn't clash.
+type metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	uploadSize      prometheus.Histogram
+	jobDuration     *prometheus.HistogramVec
+}
+
+func newMetrics(store db.Store) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time spent handling HTTP requests by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		uploadSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "dataset_upload_size_bytes",
+			Help:      "Size of uploaded datasets.",
+			// از ۱ کیلوبایت تا حدود ۱ گیگابایت
+			Buckets: prometheus.ExponentialBuckets(1024, 4, 11),
+		}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "job_duration_seconds",
+			Help:      "Duration of training and prediction jobs by model type and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"job", "model_type", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.uploadSize,
+		m.jobDuration,
+		newPoolCollector(store),
+		newDomainCollector(store),
+	)
+	return m
+}
+
+// metricsMiddleware counts requests and their latency per gin route. Requests
+// that match no route share the "unmatched" route, so unknown paths can't
+// create new series.
+func (s *Server) metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		s.metrics.requests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		s.metrics.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsHandler serves the metrics in the Prometheus text format
+func (s *Server) metricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+}
+
+// observeJob records how long a job took and whether it succeeded
+func (m *metrics) observeJob(job string, modelType pgtype.Text, start time.Time, err error) {
+	outcome := jobSucceeded
+	if err != nil {
+		outcome = jobFailed
+	}
+	label := modelType.String
+	if !modelType.Valid || label == "" {
+		label = "unknown"
+	}
+	m.jobDuration.WithLabelValues(job, label, outcome).Observe(time.Since(start).Seconds())
+}
+
+// poolCollector reports the statistics of the database connection pool
+type poolCollector struct {
+	store db.Store
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquires         *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+	acquireWait      *prometheus.Desc
+}
+
+func newPoolCollector(store db.Store) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		store:            store,
+		acquiredConns:    desc("acquired_conns", "Connections currently in use."),
+		idleConns:        desc("idle_conns", "Idle connections."),
+		totalConns:       desc("total_conns", "Open connections."),
+		maxConns:         desc("max_conns", "Maximum size of the pool."),
+		acquires:         desc("acquires_total", "Connections acquired from the pool."),
+		emptyAcquires:    desc("empty_acquires_total", "Acquires that had to wait because no connection was idle."),
+		canceledAcquires: desc("canceled_acquires_total", "Acquires cancelled before getting a connection."),
+		acquireWait:      desc("acquire_wait_seconds_total", "Total time spent waiting for a connection."),
+	}
+}
+
+func (collector *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.acquiredConns
+	ch <- collector.idleConns
+	ch <- collector.totalConns
+	ch <- collector.maxConns
+	ch <- collector.acquires
+	ch <- collector.emptyAcquires
+	ch <- collector.canceledAcquires
+	ch <- collector.acquireWait
+}
+
+func (collector *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := collector.store.PoolStat()
+	ch <- prometheus.MustNewConstMetric(collector.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(collector.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(collector.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(collector.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(collector.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(collector.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(collector.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(collector.acquireWait, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
+
+// domainCollector reports how much data the users have, read from the
+// database on every scrape
+type domainCollector struct {
+	store db.Store
+
+	users           *prometheus.Desc
+	datasets        *prometheus.Desc
+	models          *prometheus.Desc
+	predictions     *prometheus.Desc
+	datasetsPerUser *prometheus.Desc
+	modelsPerUser   *prometheus.Desc
+}
+
+func newDomainCollector(store db.Store) *domainCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", name), help, nil, nil)
+	}
+	return &domainCollector{
+		store:           store,
+		users:           desc("users", "Registered users."),
+		datasets:        desc("datasets", "Stored datasets."),
+		models:          desc("models", "Stored models."),
+		predictions:     desc("predictions", "Stored predictions."),
+		datasetsPerUser: desc("datasets_per_user", "Average number of datasets per user."),
+		modelsPerUser:   desc("models_per_user", "Average number of models per user."),
+	}
+}
+
+func (collector *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.users
+	ch <- collector.datasets
+	ch <- collector.models
+	ch <- collector.predictions
+	ch <- collector.datasetsPerUser
+	ch <- collector.modelsPerUser
+}
+
+func (collector *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainStatsTimeout)
+	defer cancel()
+
+	// بدون دیتابیس این متریک‌ها گزارش نمی‌شوند؛ بقیه متریک‌ها همچنان در دسترس‌اند
+	counts, err := collector.store.GetDomainCounts(ctx)
+	if err != nil {
+		slog.Error("Error collecting domain metrics", "error", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(collector.users, prometheus.GaugeValue, float64(counts.Users))
+	ch <- prometheus.MustNewConstMetric(collector.datasets, prometheus.GaugeValue, float64(counts.Datasets))
+	ch <- prometheus.MustNewConstMetric(collector.models, prometheus.GaugeValue, float64(counts.Models))
+	ch <- prometheus.MustNewConstMetric(collector.predictions, prometheus.GaugeValue, float64(counts.Predictions))
+
+	users := float64(max(counts.Users, 1))
+	ch <- prometheus.MustNewConstMetric(collector.datasetsPerUser, prometheus.GaugeValue, float64(counts.Datasets)/users)
+	ch <- prometheus.MustNewConstMetric(collector.modelsPerUser, prometheus.GaugeValue, float64(counts.Models)/users)
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	authorizationUserIDKey  = "user_id"
+	authorizationUserKey    = "authorization_user"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+	requestIDBytes  = 12
+	requestIDMaxLen = 128
+)
+
+// requestIDMiddleware gives every request an ID, taken from the X-Request-ID
+// header when a proxy already set one, and echoes it in the response
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			var err error
+			if id, err = util.RandomSecret(requestIDBytes); err != nil {
+				writeError(c, apperr.Wrap(err, "Failed to create request ID"))
+				return
+			}
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+		setSpanRequestID(c, id)
+		c.Next()
+	}
+}
+
+// validRequestID accepts IDs of printable ASCII characters without spaces, so
+// a client can't forge log lines or headers through it
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestID returns the ID set by requestIDMiddleware
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// slowRoutes upload, export or delete a lot of data and get
+// SlowRequestTimeout instead of RequestTimeout
+var slowRoutes = map[string]bool{
+	"POST " + apiPrefix + "/datasets":               true,
+	"GET " + apiPrefix + "/datasets/:dataset_id":    true,
+	"GET " + apiPrefix + "/me/export":               true,
+	"DELETE " + apiPrefix + "/me":                   true,
+	"DELETE " + apiPrefix + "/admin/users/:user_id": true,
+}
+
+// timeoutMiddleware puts a deadline on the request context. Handlers pass that
+// context to the database, so queries stop when the route runs out of time or
+// the client goes away.
+func (s *Server) timeoutMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := s.config.RequestTimeout
+		if slowRoutes[c.Request.Method+" "+c.FullPath()] {
+			timeout = s.config.SlowRequestTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// authMiddleware verifies the bearer credential, either an access token or a
+// personal API key, and stores the authenticated user ID in the context under "user_id".
+// Disabled accounts and ended sessions are rejected even while their access
+// tokens are still valid.
+func (s *Server) authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorizationHeader := c.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			writeError(c, apperr.Unauthorized("authorization header is not provided"))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) != 2 {
+			writeError(c, apperr.Unauthorized("invalid authorization header format"))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			writeError(c, apperr.Unauthorized("unsupported authorization type"))
+			return
+		}
+
+		if strings.HasPrefix(fields[1], apiKeyPrefix) {
+			s.authenticateAPIKey(c, fields[1])
+			return
+		}
+
+		payload, err := s.tokenMaker.VerifyToken(fields[1], token.TokenTypeAccess)
+		if err != nil {
+			writeError(c, apperr.Unauthorized(err.Error()))
+			return
+		}
+
+		if !s.requireActiveSession(c, payload) || !s.loadActiveUser(c, payload.UserID) {
+			return
+		}
+
+		c.Set(authorizationPayloadKey, payload)
+		c.Set(authorizationUserIDKey, payload.UserID)
+		c.Next()
+	}
+}
+
+// requireActiveSession aborts the request if the session the access token was
+// issued for has been revoked or has expired, so logging out also ends the
+// access tokens of the session
+func (s *Server) requireActiveSession(c *gin.Context, payload *token.Payload) bool {
+	session, err := s.Db.GetSessionByID(c.Request.Context(), payload.SessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.Unauthorized("session no longer exists"))
+			return false
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch session"))
+		return false
+	}
+	if session.UserID != payload.UserID || session.IsRevoked {
+		writeError(c, apperr.Unauthorized("session is revoked"))
+		return false
+	}
+	if time.Now().UTC().After(session.ExpiresAt.Time) {
+		writeError(c, apperr.Unauthorized("session has expired"))
+		return false
+	}
+	return true
+}
+
+// loadActiveUser fetches the authenticated user into the context and aborts
+// the request if the account was deleted or disabled
+func (s *Server) loadActiveUser(c *gin.Context, userID int32) bool {
+	user, err := s.Db.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.Unauthorized("user no longer exists"))
+			return false
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return false
+	}
+	if user.DisabledAt.Valid {
+		writeError(c, apperr.Unauthorized(errAccountDisabled.Error()))
+		return false
+	}
+
+	c.Set(authorizationUserKey, user)
+	return true
+}
+
+// currentUser returns the authenticated user loaded by authMiddleware
+func currentUser(c *gin.Context) db.User {
+	return c.MustGet(authorizationUserKey).(db.User)
+}
+
+// currentUserID returns the authenticated user ID set by authMiddleware
+func currentUserID(c *gin.Context) int32 {
+	return c.MustGet(authorizationUserIDKey).(int32)
+}
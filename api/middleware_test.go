@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// authTestPath is registered by the tests behind authMiddleware only
+const authTestPath = "/auth-test"
+
+// addAuthorization starts a session for user and puts an access token of it
+// in the request
+func addAuthorization(t *testing.T, request *http.Request, server *Server, authorizationType string, user db.User, duration time.Duration) {
+	session, _ := server.Db.(*fakeStore).addSession(user)
+	addSessionAuthorization(t, request, server.tokenMaker, authorizationType, user, session.ID, duration)
+}
+
+// addSessionAuthorization signs an access token of an existing session
+func addSessionAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, user db.User, sessionID int32, duration time.Duration) {
+	accessToken, payload, err := tokenMaker.CreateToken(user.ID, sessionID, token.TokenTypeAccess, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationType, accessToken))
+}
+
+// serve sends a request through the router of server
+func serve(server *Server, method, path string, body io.Reader, setup func(*http.Request)) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, body)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if setup != nil {
+		setup(request)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// requireErrorCode checks the status and the code of an error envelope
+func requireErrorCode(t *testing.T, recorder *httptest.ResponseRecorder, status int, code apperr.Code) {
+	require.Equal(t, status, recorder.Code, recorder.Body.String())
+
+	var rsp apperr.Error
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.Equal(t, code, rsp.Code)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	disabled := store.addUser()
+	_, err := store.DisableUser(context.Background(), disabled.ID)
+	require.NoError(t, err)
+	deleted := db.User{ID: 1000}
+
+	testCases := []struct {
+		name   string
+		setup  func(t *testing.T, request *http.Request, server *Server)
+		status int
+	}{
+		{
+			name: "OK",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "NoAuthorization",
+			setup:  func(t *testing.T, request *http.Request, server *Server) {},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "UnsupportedAuthorization",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, "unsupported", user, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "InvalidAuthorizationFormat",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, "", user, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "ExpiredToken",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, authorizationTypeBearer, user, -time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "ChallengeToken",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				challenge, _, err := server.tokenMaker.CreateToken(user.ID, 0, token.TokenTypeTwoFactorChallenge, time.Minute)
+				require.NoError(t, err)
+				request.Header.Set(authorizationHeaderKey, "Bearer "+challenge)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "DisabledUser",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, authorizationTypeBearer, disabled, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "DeletedUser",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server, authorizationTypeBearer, deleted, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "NoSession",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, 0, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "RevokedSession",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				session, _ := store.addSession(user)
+				_, err := store.RevokeSession(context.Background(), db.RevokeSessionParams{ID: session.ID, UserID: user.ID})
+				require.NoError(t, err)
+				addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, session.ID, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "SessionOfAnotherUser",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				session, _ := store.addSession(disabled)
+				addSessionAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user, session.ID, time.Minute)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "UnknownAPIKey",
+			setup: func(t *testing.T, request *http.Request, server *Server) {
+				request.Header.Set(authorizationHeaderKey, "Bearer "+apiKeyPrefix+"unknown")
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, store, nil)
+			server.Router.GET(authTestPath, server.authMiddleware(), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"user_id": currentUserID(c)})
+			})
+
+			recorder := serve(server, http.MethodGet, authTestPath, nil, func(request *http.Request) {
+				tc.setup(t, request, server)
+			})
+			if tc.status == http.StatusOK {
+				require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+				return
+			}
+			requireErrorCode(t, recorder, tc.status, apperr.CodeUnauthorized)
+		})
+	}
+}
+
+func TestUserTokenOnly(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	_, secret := store.addAPIKey(user, noProject, scopeRead, scopeWrite)
+
+	server := newTestServer(t, store, nil)
+
+	// کلید API نمی‌تواند کلید جدید بسازد
+	recorder := serve(server, http.MethodPost, apiPrefix+"/api-keys", strings.NewReader(`{"name":"more","scopes":["read"]}`), func(request *http.Request) {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+secret)
+	})
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+
+	recorder = serve(server, http.MethodGet, apiPrefix+"/sessions", nil, func(request *http.Request) {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+secret)
+	})
+	requireErrorCode(t, recorder, http.StatusForbidden, apperr.CodeForbidden)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// modelSummary is a model in a list, without the path of its file
+type modelSummary struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	ModelType   pgtype.Text      `json:"model_type"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type listModelsRequest struct {
+	pageRequest
+	Name string `form:"name"`
+	Sort string `form:"sort,default=id" binding:"oneof=id -id name -name created_at -created_at"`
+}
+
+// listModels lists the models of the user page by page
+func (s *Server) listModels(c *gin.Context) {
+	var req listModelsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	cursor, ok := readCursor(c, req.Cursor, req.Sort)
+	if !ok {
+		return
+	}
+
+	userID := pgtype.Int4{Int32: currentUserID(c), Valid: true}
+	rows, err := s.Db.ListModelsByUserID(c.Request.Context(), db.ListModelsByUserIDParams{
+		UserID:        userID,
+		Name:          optionalText(req.Name),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Sort:          req.Sort,
+		AfterName:     cursor.afterName(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch models"))
+		return
+	}
+
+	total, err := s.Db.CountModelsByUserID(c.Request.Context(), db.CountModelsByUserIDParams{
+		UserID:        userID,
+		Name:          optionalText(req.Name),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count models"))
+		return
+	}
+
+	models := make([]modelSummary, len(rows))
+	for i, row := range rows {
+		models[i] = modelSummary(row)
+	}
+	writePage(c, models, req.Limit, total, func(model modelSummary) pageCursor {
+		return newPageCursor(req.Sort, model.ID, model.Name, model.CreatedAt)
+	})
+}
+
+// getModel
+func (s *Server) getModel(c *gin.Context) {
+	c.JSON(http.StatusOK, authorizedModel(c))
+}
+
+// deleteModel
+func (s *Server) deleteModel(c *gin.Context) {
+	model := authorizedModel(c)
+
+	err := s.Db.DeleteModel(c.Request.Context(), model.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to delete model"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionModelDeleted,
+		Resource:   auditModel,
+		ResourceID: model.ID,
+		Before:     model,
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Model deleted successfully"})
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/sso"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	oidcStateBytes    = 32
+	oidcStateDuration = 10 * time.Minute
+
+	// oidcStateCookie holds the state in the browser that started the login
+	oidcStateCookie = "oidc_state"
+)
+
+// newSSOProvider runs OIDC discovery when single sign-on is configured
+func newSSOProvider(config config.Config) (*sso.Provider, error) {
+	if config.OIDCIssuerURL == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return sso.NewProvider(ctx, config.OIDCIssuerURL, config.OIDCClientID, config.OIDCClientSecret,
+		config.OIDCRedirectURL, config.OIDCScopes)
+}
+
+// setOIDCStateCookie binds the state to the browser, so a callback link
+// started by someone else can't log this browser into their account.
+// A negative maxAge deletes the cookie.
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, apiPrefix+"/auth/oidc", "", true, true)
+}
+
+// oidcLogin sends the user to the identity provider. The state, nonce and
+// PKCE verifier are kept in the database until the callback; the state is
+// also kept in a cookie of the browser.
+func (s *Server) oidcLogin(c *gin.Context) {
+	state, err := util.RandomSecret(oidcStateBytes)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to start login"))
+		return
+	}
+	nonce, err := util.RandomSecret(oidcStateBytes)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to start login"))
+		return
+	}
+	verifier := sso.NewVerifier()
+
+	now := time.Now().UTC()
+	_, err = s.Db.CreateOidcLoginState(c.Request.Context(), db.CreateOidcLoginStateParams{
+		StateHash:    util.HashSecret(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    pgtype.Timestamp{Time: now.Add(oidcStateDuration), Valid: true},
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to start login"))
+		return
+	}
+
+	// پاک کردن ورودهای نیمه‌کاره منقضی‌شده
+	err = s.Db.DeleteExpiredOidcLoginStates(c.Request.Context(), pgtype.Timestamp{Time: now, Valid: true})
+	if err != nil {
+		logger(c).Error("Error deleting expired oidc login states", "error", err)
+	}
+
+	setOIDCStateCookie(c, state, int(oidcStateDuration.Seconds()))
+	c.Redirect(http.StatusFound, s.ssoProvider.AuthCodeURL(state, nonce, verifier))
+}
+
+type oidcCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// oidcCallback finishes the login when the identity provider redirects back.
+// First-time users are provisioned automatically.
+func (s *Server) oidcCallback(c *gin.Context) {
+	var req oidcCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	if req.Error != "" {
+		message := "Login was rejected by the identity provider: " + req.Error
+		if req.ErrorDescription != "" {
+			message += " (" + req.ErrorDescription + ")"
+		}
+		writeError(c, apperr.Unauthorized(message))
+		return
+	}
+	if req.Code == "" {
+		writeError(c, apperr.BadRequest("Missing authorization code"))
+		return
+	}
+
+	invalid := apperr.BadRequest("Invalid or expired login state")
+
+	// state باید همان باشد که در مرورگر شروع‌کننده ورود ذخیره شده است
+	cookieState, err := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookieState), []byte(req.State)) != 1 {
+		writeError(c, invalid)
+		return
+	}
+
+	// state فقط یک بار قابل استفاده است
+	loginState, err := s.Db.ConsumeOidcLoginState(c.Request.Context(), util.HashSecret(req.State))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, invalid)
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch login state"))
+		return
+	}
+	if time.Now().UTC().After(loginState.ExpiresAt.Time) {
+		writeError(c, invalid)
+		return
+	}
+
+	claims, err := s.ssoProvider.Exchange(c.Request.Context(), req.Code, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		logger(c).Error("Error completing oidc login", "error", err)
+		writeError(c, apperr.Unauthorized("Failed to verify login with the identity provider"))
+		return
+	}
+	if claims.Email == "" {
+		writeError(c, apperr.BadRequest("The identity provider did not share an email address"))
+		return
+	}
+
+	result, err := s.Db.OIDCLoginTx(c.Request.Context(), db.OIDCLoginTxParams{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		FullName:      claims.Name,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrEmailNotVerifiedByIssuer) {
+			writeError(c, apperr.Conflict("An account with this email already exists"))
+			return
+		}
+		if errors.Is(err, db.ErrTwoFactorAccount) {
+			writeError(c, apperr.Conflict("An account with this email already exists and uses two-factor authentication; log in with its password"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to log in"))
+		return
+	}
+
+	if result.User.DisabledAt.Valid {
+		s.rejectLogin(c, result.User, errAccountDisabled)
+		return
+	}
+
+	// ورود دو مرحله‌ای هویت‌هایی که قبلاً متصل شده‌اند بر عهده identity provider است
+	rsp, err := s.startSession(c, result.User)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create session"))
+		return
+	}
+	s.loginSucceeded(c, result.User)
+
+	c.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	"github.com/faezefz/SFP_website/sso/ssotest"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcCallbackPath = apiPrefix + "/auth/oidc/callback"
+
+func newSSOTestServer(t *testing.T, store *fakeStore) (*Server, *ssotest.Server) {
+	idp, err := ssotest.NewServer("sfp", util.RandomString(32))
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+
+	server := newTestServer(t, store, func(cfg *config.Config) {
+		cfg.OIDCIssuerURL = idp.URL
+		cfg.OIDCClientID = idp.ClientID
+		cfg.OIDCClientSecret = idp.ClientSecret
+		cfg.OIDCRedirectURL = "http://localhost:8080" + oidcCallbackPath
+	})
+	return server, idp
+}
+
+// startOIDCLogin starts a login like a browser and returns the state cookie
+// together with the query the identity provider sends back to the callback
+func startOIDCLogin(t *testing.T, server *Server) (*http.Cookie, url.Values) {
+	recorder := serve(server, http.MethodGet, apiPrefix+"/auth/oidc/login", nil, nil)
+	require.Equal(t, http.StatusFound, recorder.Code, recorder.Body.String())
+
+	var stateCookie *http.Cookie
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			stateCookie = cookie
+		}
+	}
+	require.NotNil(t, stateCookie)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	rsp, err := client.Get(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusFound, rsp.StatusCode)
+
+	callback, err := url.Parse(rsp.Header.Get("Location"))
+	require.NoError(t, err)
+	return stateCookie, callback.Query()
+}
+
+func oidcCallback(server *Server, query url.Values, cookie *http.Cookie) int {
+	recorder := serve(server, http.MethodGet, oidcCallbackPath+"?"+query.Encode(), nil, func(request *http.Request) {
+		if cookie != nil {
+			request.AddCookie(cookie)
+		}
+	})
+	return recorder.Code
+}
+
+func TestOIDCLogin(t *testing.T) {
+	server, idp := newSSOTestServer(t, newFakeStore())
+	idp.SetUser(ssotest.User{Subject: util.RandomString(12), Email: util.RandomEmail(), EmailVerified: true})
+
+	cookie, query := startOIDCLogin(t, server)
+	require.True(t, cookie.HttpOnly)
+	require.True(t, cookie.Secure)
+	require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	require.Equal(t, apiPrefix+"/auth/oidc", cookie.Path)
+	require.Equal(t, int(oidcStateDuration.Seconds()), cookie.MaxAge)
+	require.Equal(t, query.Get("state"), cookie.Value)
+
+	require.Equal(t, http.StatusOK, oidcCallback(server, query, cookie))
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	server, idp := newSSOTestServer(t, newFakeStore())
+	idp.SetUser(ssotest.User{Subject: util.RandomString(12), Email: util.RandomEmail(), EmailVerified: true})
+
+	// بدون کوکی، مثلاً وقتی لینک callback برای کاربر دیگری فرستاده شده است
+	_, query := startOIDCLogin(t, server)
+	require.Equal(t, http.StatusBadRequest, oidcCallback(server, query, nil))
+
+	// کوکی ورود دیگری از همان مرورگر
+	_, query = startOIDCLogin(t, server)
+	otherCookie, _ := startOIDCLogin(t, server)
+	require.Equal(t, http.StatusBadRequest, oidcCallback(server, query, otherCookie))
+
+	// state مصرف‌شده دوباره پذیرفته نمی‌شود
+	cookie, query := startOIDCLogin(t, server)
+	require.Equal(t, http.StatusOK, oidcCallback(server, query, cookie))
+	require.Equal(t, http.StatusBadRequest, oidcCallback(server, query, cookie))
+}
+
+func TestOIDCLoginTwoFactorAccount(t *testing.T) {
+	store := newFakeStore()
+	server, idp := newSSOTestServer(t, store)
+	user := store.addUser()
+	store.enableTotp(user, util.RandomString(32))
+	idp.SetUser(ssotest.User{Subject: util.RandomString(12), Email: user.Email, EmailVerified: true})
+
+	cookie, query := startOIDCLogin(t, server)
+	recorder := serve(server, http.MethodGet, oidcCallbackPath+"?"+query.Encode(), nil, func(request *http.Request) {
+		request.AddCookie(cookie)
+	})
+	requireErrorCode(t, recorder, http.StatusConflict, apperr.CodeConflict)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/buildinfo"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// redocFiles holds the vendored Redoc bundle; see redoc/README.md
+//
+//go:embed redoc
+var redocFiles embed.FS
+
+// Tags group the operations in the docs
+const (
+	tagMeta        = "meta"
+	tagAuth        = "auth"
+	tagAccount     = "account"
+	tagSessions    = "sessions"
+	tagAPIKeys     = "api-keys"
+	tagTwoFactor   = "two-factor"
+	tagDatasets    = "datasets"
+	tagModels      = "models"
+	tagPredictions = "predictions"
+	tagProjects    = "projects"
+	tagTeams       = "teams"
+	tagAdmin       = "admin"
+)
+
+// pageHeaders are sent with every page of a list
+var pageHeaders = map[string]openapi.Header{
+	totalCountHeader: {
+		Description: "Number of rows that match the filters",
+		Schema:      &openapi.Schema{Type: "integer", Format: "int64"},
+	},
+	nextCursorHeader: {
+		Description: "Value of the cursor parameter for the next page; missing on the last page",
+		Schema:      &openapi.Schema{Type: "string"},
+	},
+}
+
+// apiEndpoints documents every route under apiPrefix. Paths are relative to
+// apiPrefix. TestOpenAPIMatchesRoutes fails when this list and Routes differ.
+func (s *Server) apiEndpoints() []openapi.Endpoint {
+	endpoints := []openapi.Endpoint{
+		{Method: http.MethodGet, Path: "/", OperationID: "home", Summary: "Welcome message", Tag: tagMeta, Response: messageResponse{}},
+
+		// ورود و ثبت‌نام
+		{Method: http.MethodPost, Path: "/signup", OperationID: "signup", Summary: "Create an account", Tag: tagAuth, Body: signupRequest{}, Status: http.StatusCreated, Response: signupResponse{}},
+		{Method: http.MethodPost, Path: "/login", OperationID: "login", Summary: "Log in with email and password; answers with a challenge when 2FA is enabled", Tag: tagAuth, Body: loginRequest{}, Response: openapi.OneOf{loginResponse{}, twoFactorChallengeResponse{}}},
+		{Method: http.MethodPost, Path: "/login/2fa", OperationID: "loginTwoFactor", Summary: "Finish a login with a TOTP or recovery code", Tag: tagAuth, Body: loginTwoFactorRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/auth/refresh", OperationID: "refreshAccessToken", Summary: "Exchange a refresh token for new tokens", Tag: tagAuth, Body: refreshTokenRequest{}, Response: loginResponse{}},
+		{Method: http.MethodPost, Path: "/auth/logout", OperationID: "logout", Summary: "End the session of a refresh token", Tag: tagAuth, Body: refreshTokenRequest{}, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/password/forgot", OperationID: "forgotPassword", Summary: "Email a password reset link", Tag: tagAuth, Body: forgotPasswordRequest{}, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/password/reset", OperationID: "resetPassword", Summary: "Set a new password with a reset token", Tag: tagAuth, Body: resetPasswordRequest{}, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/verify-email", OperationID: "verifyEmail", Summary: "Verify the email address with the token from the link", Tag: tagAuth, Query: verifyEmailRequest{}, Response: userResponse{}},
+
+		// حساب کاربری
+		{Method: http.MethodGet, Path: "/dashboard", OperationID: "userDashboard", Summary: "Dashboard of the current user", Tag: tagAccount, Auth: true, Response: dashboardResponse{}},
+		{Method: http.MethodGet, Path: "/me", OperationID: "getMe", Summary: "Profile of the current user", Tag: tagAccount, Auth: true, Response: userResponse{}},
+		{Method: http.MethodPut, Path: "/me", OperationID: "updateMe", Summary: "Change the name and email", Tag: tagAccount, Auth: true, Body: updateMeRequest{}, Response: userResponse{}},
+		{Method: http.MethodPost, Path: "/me/password", OperationID: "changeMyPassword", Summary: "Change the password and end all sessions", Tag: tagAccount, Auth: true, Body: changePasswordRequest{}, Response: messageResponse{}},
+		{Method: http.MethodDelete, Path: "/me", OperationID: "deleteMe", Summary: "Delete the account and everything it owns", Tag: tagAccount, Auth: true, Body: deleteMeRequest{}, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/me/export", OperationID: "exportMyData", Summary: "Download all personal data as a zip archive", Tag: tagAccount, Auth: true, ResponseType: "application/zip"},
+		{Method: http.MethodPost, Path: "/me/erasure", OperationID: "requestErasure", Summary: "Schedule the erasure of the account", Tag: tagAccount, Auth: true, Body: requestErasureRequest{}, Status: http.StatusAccepted, Response: erasureRequestResponse{}},
+		{Method: http.MethodGet, Path: "/me/erasure", OperationID: "getErasureRequest", Summary: "Pending erasure of the account", Tag: tagAccount, Auth: true, Response: erasureRequestResponse{}},
+		{Method: http.MethodDelete, Path: "/me/erasure", OperationID: "cancelErasure", Summary: "Cancel the pending erasure", Tag: tagAccount, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/verify-email/resend", OperationID: "resendVerificationEmail", Summary: "Send the verification link again", Tag: tagAccount, Auth: true, Response: messageResponse{}},
+
+		// سشن‌ها و کلیدهای API
+		{Method: http.MethodPost, Path: "/auth/logout-all", OperationID: "logoutAll", Summary: "End all sessions", Tag: tagSessions, Auth: true, Response: logoutAllResponse{}},
+		{Method: http.MethodGet, Path: "/sessions", OperationID: "listSessions", Summary: "Active sessions", Tag: tagSessions, Auth: true, Query: pageRequest{}, Response: []sessionResponse{}, Headers: pageHeaders},
+		{Method: http.MethodDelete, Path: "/sessions/:session_id", OperationID: "revokeSession", Summary: "End a session", Tag: tagSessions, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/api-keys", OperationID: "createAPIKey", Summary: "Create an API key; its secret is shown only once", Tag: tagAPIKeys, Auth: true, Body: createAPIKeyRequest{}, Status: http.StatusCreated, Response: apiKeyResponse{}},
+		{Method: http.MethodGet, Path: "/api-keys", OperationID: "listAPIKeys", Summary: "API keys of the current user", Tag: tagAPIKeys, Auth: true, Query: pageRequest{}, Response: []apiKeyResponse{}, Headers: pageHeaders},
+		{Method: http.MethodDelete, Path: "/api-keys/:api_key_id", OperationID: "revokeAPIKey", Summary: "Revoke an API key", Tag: tagAPIKeys, Auth: true, Response: messageResponse{}},
+
+		// ورود دو مرحله‌ای
+		{Method: http.MethodPost, Path: "/2fa/enroll", OperationID: "enrollTOTP", Summary: "Create a TOTP secret", Tag: tagTwoFactor, Auth: true, Response: enrollTOTPResponse{}},
+		{Method: http.MethodPost, Path: "/2fa/confirm", OperationID: "confirmTOTP", Summary: "Enable 2FA and get the recovery codes", Tag: tagTwoFactor, Auth: true, Body: confirmTOTPRequest{}, Response: recoveryCodesResponse{}},
+		{Method: http.MethodPost, Path: "/2fa/disable", OperationID: "disableTOTP", Summary: "Disable 2FA", Tag: tagTwoFactor, Auth: true, Body: disableTOTPRequest{}, Response: messageResponse{}},
+
+		// دیتاست‌ها، مدل‌ها و پیش‌بینی‌ها
+		{Method: http.MethodPost, Path: "/datasets", OperationID: "uploadDataset", Summary: "Upload a CSV dataset", Tag: tagDatasets, Auth: true, Form: uploadRequest{}, Files: []string{"content"}, Status: http.StatusCreated, Response: uploadDatasetResponse{}},
+		{Method: http.MethodGet, Path: "/datasets", OperationID: "listDatasets", Summary: "Datasets of the current user, without their content", Tag: tagDatasets, Auth: true, Query: listDatasetsRequest{}, Response: []datasetSummary{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/datasets/:dataset_id", OperationID: "getDataset", Summary: "A dataset with its content", Tag: tagDatasets, Auth: true, Response: db.Dataset{}},
+		{Method: http.MethodDelete, Path: "/datasets/:dataset_id", OperationID: "deleteDataset", Summary: "Delete a dataset", Tag: tagDatasets, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/models", OperationID: "listModels", Summary: "Models of the current user", Tag: tagModels, Auth: true, Query: listModelsRequest{}, Response: []modelSummary{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/models/:model_id", OperationID: "getModel", Summary: "A model", Tag: tagModels, Auth: true, Response: db.Model{}},
+		{Method: http.MethodDelete, Path: "/models/:model_id", OperationID: "deleteModel", Summary: "Delete a model", Tag: tagModels, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/predictions", OperationID: "listPredictions", Summary: "Predictions of the current user", Tag: tagPredictions, Auth: true, Query: listPredictionsRequest{}, Response: []predictionSummary{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/predictions/:prediction_id", OperationID: "getPrediction", Summary: "A prediction", Tag: tagPredictions, Auth: true, Response: db.Prediction{}},
+		{Method: http.MethodDelete, Path: "/predictions/:prediction_id", OperationID: "deletePrediction", Summary: "Delete a prediction", Tag: tagPredictions, Auth: true, Response: messageResponse{}},
+
+		// پروژه‌ها
+		{Method: http.MethodPost, Path: "/projects", OperationID: "createProject", Summary: "Create a project", Tag: tagProjects, Auth: true, Body: createProjectRequest{}, Status: http.StatusCreated, Response: db.Project{}},
+		{Method: http.MethodGet, Path: "/projects", OperationID: "listProjects", Summary: "Own and shared projects", Tag: tagProjects, Auth: true, Query: listProjectsRequest{}, Response: []db.Project{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/users/:owner_user_id/projects", OperationID: "getProjectsByOwnerID", Summary: "Projects of a user that the caller can see", Tag: tagProjects, Auth: true, Query: listProjectsRequest{}, Response: []db.Project{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/projects/:project_id", OperationID: "getProject", Summary: "A project", Tag: tagProjects, Auth: true, Response: db.Project{}},
+		{Method: http.MethodPut, Path: "/projects/:project_id", OperationID: "updateProject", Summary: "Change a project", Tag: tagProjects, Auth: true, Body: updateProjectRequest{}, Response: db.Project{}},
+		{Method: http.MethodDelete, Path: "/projects/:project_id", OperationID: "deleteProject", Summary: "Delete a project", Tag: tagProjects, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/projects/:project_id/members", OperationID: "listProjectMembers", Summary: "Collaborators of a project", Tag: tagProjects, Auth: true, Query: pageRequest{}, Response: []db.ListProjectMembersRow{}, Headers: pageHeaders},
+		{Method: http.MethodPost, Path: "/projects/:project_id/members", OperationID: "addProjectMember", Summary: "Add a collaborator", Tag: tagProjects, Auth: true, Body: addMemberRequest{}, Status: http.StatusCreated, Response: db.ProjectMember{}},
+		{Method: http.MethodPut, Path: "/projects/:project_id/members/:user_id", OperationID: "updateProjectMember", Summary: "Change the role of a collaborator", Tag: tagProjects, Auth: true, Body: updateMemberRequest{}, Response: db.ProjectMember{}},
+		{Method: http.MethodDelete, Path: "/projects/:project_id/members/:user_id", OperationID: "removeProjectMember", Summary: "Remove a collaborator, or leave the project", Tag: tagProjects, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/projects/:project_id/datasets", OperationID: "listProjectDatasets", Summary: "Datasets attached to a project", Tag: tagProjects, Auth: true, Query: pageRequest{}, Response: []datasetSummary{}, Headers: pageHeaders},
+		{Method: http.MethodPost, Path: "/projects/:project_id/datasets", OperationID: "attachProjectDataset", Summary: "Attach a dataset", Tag: tagProjects, Auth: true, Body: attachDatasetRequest{}, Status: http.StatusCreated, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/projects/:project_id/datasets/upload", OperationID: "uploadProjectDataset", Summary: "Upload a CSV dataset into a project", Tag: tagProjects, Auth: true, Form: uploadRequest{}, Files: []string{"content"}, Status: http.StatusCreated, Response: uploadDatasetResponse{}},
+		{Method: http.MethodDelete, Path: "/projects/:project_id/datasets/:dataset_id", OperationID: "detachProjectDataset", Summary: "Detach a dataset", Tag: tagProjects, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/projects/:project_id/models", OperationID: "listProjectModels", Summary: "Models attached to a project", Tag: tagProjects, Auth: true, Query: pageRequest{}, Response: []db.Model{}, Headers: pageHeaders},
+		{Method: http.MethodPost, Path: "/projects/:project_id/models", OperationID: "attachProjectModel", Summary: "Attach a model", Tag: tagProjects, Auth: true, Body: attachModelRequest{}, Status: http.StatusCreated, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/projects/:project_id/predictions", OperationID: "listProjectPredictions", Summary: "Predictions of a project", Tag: tagProjects, Auth: true, Query: listProjectPredictionsRequest{}, Response: []db.Prediction{}, Headers: pageHeaders},
+		{Method: http.MethodPost, Path: "/projects/:project_id/predictions", OperationID: "createProjectPrediction", Summary: "Run a prediction with an attached dataset and model", Tag: tagProjects, Auth: true, Body: createPredictionRequest{}, Status: http.StatusCreated, Response: db.Prediction{}},
+
+		// تیم‌ها
+		{Method: http.MethodPost, Path: "/teams", OperationID: "createTeam", Summary: "Create a team", Tag: tagTeams, Auth: true, Body: teamRequest{}, Status: http.StatusCreated, Response: db.Team{}},
+		{Method: http.MethodGet, Path: "/teams", OperationID: "listTeams", Summary: "Teams of the current user", Tag: tagTeams, Auth: true, Query: pageRequest{}, Response: []db.Team{}, Headers: pageHeaders},
+		{Method: http.MethodGet, Path: "/teams/:team_id", OperationID: "getTeam", Summary: "A team", Tag: tagTeams, Auth: true, Response: db.Team{}},
+		{Method: http.MethodGet, Path: "/teams/:team_id/members", OperationID: "listTeamMembers", Summary: "Members and invitees of a team", Tag: tagTeams, Auth: true, Query: pageRequest{}, Response: []db.ListTeamMembersRow{}, Headers: pageHeaders},
+		{Method: http.MethodPut, Path: "/teams/:team_id", OperationID: "updateTeam", Summary: "Rename a team", Tag: tagTeams, Auth: true, Body: teamRequest{}, Response: db.Team{}},
+		{Method: http.MethodDelete, Path: "/teams/:team_id", OperationID: "deleteTeam", Summary: "Delete a team", Tag: tagTeams, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodPost, Path: "/teams/:team_id/invites", OperationID: "inviteTeamMember", Summary: "Invite a user to the team", Tag: tagTeams, Auth: true, Body: inviteRequest{}, Status: http.StatusCreated, Response: db.TeamMember{}},
+		{Method: http.MethodPost, Path: "/teams/:team_id/invites/accept", OperationID: "acceptTeamInvite", Summary: "Accept an invite", Tag: tagTeams, Auth: true, Response: db.TeamMember{}},
+		{Method: http.MethodDelete, Path: "/teams/:team_id/members/:user_id", OperationID: "removeTeamMember", Summary: "Remove a member, or leave the team", Tag: tagTeams, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodGet, Path: "/team-invites", OperationID: "listTeamInvites", Summary: "Pending invites of the current user, newest first", Tag: tagTeams, Auth: true, Query: pageRequest{}, Response: []db.TeamMember{}, Headers: pageHeaders},
+
+		// مدیریت کاربران
+		{Method: http.MethodGet, Path: "/admin/users", OperationID: "adminListUsers", Summary: "Search users", Tag: tagAdmin, Auth: true, Query: listUsersRequest{}, Response: []adminUserResponse{}, Headers: pageHeaders},
+		{Method: http.MethodPost, Path: "/admin/users/:user_id/disable", OperationID: "adminDisableUser", Summary: "Disable a user and end their sessions", Tag: tagAdmin, Auth: true, Response: userResponse{}},
+		{Method: http.MethodPost, Path: "/admin/users/:user_id/enable", OperationID: "adminEnableUser", Summary: "Enable a user", Tag: tagAdmin, Auth: true, Response: userResponse{}},
+		{Method: http.MethodPost, Path: "/admin/users/:user_id/force-password-reset", OperationID: "adminForcePasswordReset", Summary: "Make a user reset their password", Tag: tagAdmin, Auth: true, Response: messageResponse{}},
+		{Method: http.MethodPut, Path: "/admin/users/:user_id/role", OperationID: "adminSetUserRole", Summary: "Grant or remove administrator rights", Tag: tagAdmin, Auth: true, Body: setUserRoleRequest{}, Response: userResponse{}},
+		{Method: http.MethodDelete, Path: "/admin/users/:user_id", OperationID: "adminDeleteUser", Summary: "Delete a user", Tag: tagAdmin, Auth: true, Response: messageResponse{}},
+
+		// مستندات
+		{Method: http.MethodGet, Path: "/openapi.json", OperationID: "openAPISpec", Summary: "This document", Tag: tagMeta, ResponseType: "application/json"},
+		{Method: http.MethodGet, Path: "/docs", OperationID: "apiDocs", Summary: "Documentation browser", Tag: tagMeta, ResponseType: "text/html"},
+		{Method: http.MethodGet, Path: "/docs/redoc.standalone.js", OperationID: "redocBundle", Summary: "Script of the documentation browser", Tag: tagMeta, ResponseType: "text/javascript"},
+	}
+
+	if s.ssoProvider != nil {
+		endpoints = append(endpoints,
+			openapi.Endpoint{Method: http.MethodGet, Path: "/auth/oidc/login", OperationID: "oidcLogin", Summary: "Redirect to the identity provider", Tag: tagAuth, Status: http.StatusFound},
+			openapi.Endpoint{Method: http.MethodGet, Path: "/auth/oidc/callback", OperationID: "oidcCallback", Summary: "Finish a single sign-on login", Tag: tagAuth, Query: oidcCallbackRequest{}, Response: loginResponse{}},
+		)
+	}
+	return endpoints
+}
+
+// newOpenAPISpec builds the OpenAPI document of the server and encodes it
+func (s *Server) newOpenAPISpec() ([]byte, error) {
+	doc, err := openapi.Build(openapi.Info{
+		Title:   "SFP API",
+		Version: buildinfo.Get().Version,
+	}, []openapi.Server{{URL: apiPrefix}}, apperr.Error{}, s.apiEndpoints())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// openAPISpec serves the OpenAPI document
+func (s *Server) openAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", s.openAPI)
+}
+
+// apiDocs serves a page that renders the OpenAPI document
+func apiDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// redocBundle serves the Redoc script of the docs page from the binary
+func redocBundle(c *gin.Context) {
+	bundle, err := redocFiles.ReadFile("redoc/redoc.standalone.js")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			writeError(c, apperr.NotFound("Redoc isn't vendored in this build, run make redoc"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to read Redoc"))
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, "text/javascript; charset=utf-8", bundle)
+}
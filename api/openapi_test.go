@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/openapi"
+	"github.com/faezefz/SFP_website/sso/ssotest"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestServer creates a server on an in-memory store
+func newTestServer(t *testing.T, store db.Store, configure func(*config.Config)) *Server {
+	cfg := config.Config{
+		TokenSymmetricKey:          util.RandomString(32),
+		AccessTokenDuration:        time.Minute,
+		TwoFactorChallengeDuration: time.Minute,
+		PasswordAlgorithm:          util.PasswordAlgorithmBcrypt,
+		BcryptCost:                 bcrypt.MinCost,
+		MailDir:                    t.TempDir(),
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	server, err := NewServer(cfg, store)
+	require.NoError(t, err)
+	return server
+}
+
+// servedSpec fetches the document the server publishes
+func servedSpec(t *testing.T, server *Server) openapi.Document {
+	w := httptest.NewRecorder()
+	server.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Equal(t, openapi.Version, doc.OpenAPI)
+	return doc
+}
+
+// requireRoutesDocumented checks that the routes under apiPrefix and the
+// operations of the document are the same
+func requireRoutesDocumented(t *testing.T, server *Server) {
+	doc := servedSpec(t, server)
+
+	var documented []string
+	for path, item := range doc.Paths {
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch} {
+			if item.Operation(method) != nil {
+				documented = append(documented, method+" "+path)
+			}
+		}
+	}
+
+	var routed []string
+	for _, route := range server.Router.Routes() {
+		path, ok := strings.CutPrefix(route.Path, apiPrefix)
+		if !ok {
+			continue
+		}
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if name, ok := strings.CutPrefix(segment, ":"); ok {
+				segments[i] = "{" + name + "}"
+			}
+		}
+		routed = append(routed, route.Method+" "+strings.Join(segments, "/"))
+	}
+
+	slices.Sort(documented)
+	slices.Sort(routed)
+	require.Equal(t, routed, documented, "update apiEndpoints when the routes change")
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	requireRoutesDocumented(t, newTestServer(t, newFakeStore(), nil))
+}
+
+func TestOpenAPIMatchesRoutesWithSSO(t *testing.T) {
+	idp, err := ssotest.NewServer("sfp", util.RandomString(32))
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+
+	server := newTestServer(t, newFakeStore(), func(cfg *config.Config) {
+		cfg.OIDCIssuerURL = idp.URL
+		cfg.OIDCClientID = idp.ClientID
+		cfg.OIDCClientSecret = idp.ClientSecret
+		cfg.OIDCRedirectURL = "http://localhost:8080" + apiPrefix + "/auth/oidc/callback"
+	})
+	requireRoutesDocumented(t, server)
+}
+
+// TestOpenAPIRequiredFields sends empty bodies to the public endpoints and
+// checks that the fields the handlers reject are the ones the document marks
+// as required
+func TestOpenAPIRequiredFields(t *testing.T) {
+	server := newTestServer(t, newFakeStore(), nil)
+	doc := servedSpec(t, server)
+
+	for path, item := range doc.Paths {
+		op := item.Post
+		if op == nil || op.Security != nil || op.RequestBody == nil {
+			continue
+		}
+		t.Run(op.OperationID, func(t *testing.T) {
+			ref := op.RequestBody.Content["application/json"].Schema.Ref
+			schema := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+			require.NotNil(t, schema, ref)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, apiPrefix+path, strings.NewReader("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			server.Router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+			var rsp apperr.Error
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rsp))
+			require.Equal(t, apperr.CodeValidation, rsp.Code)
+
+			var rejected []string
+			for _, detail := range rsp.Details {
+				rejected = append(rejected, detail.Field)
+			}
+			required := slices.Clone(schema.Required)
+			slices.Sort(rejected)
+			slices.Sort(required)
+			require.Equal(t, required, rejected)
+		})
+	}
+}
+
+func TestAPIDocs(t *testing.T) {
+	server := newTestServer(t, newFakeStore(), nil)
+
+	w := httptest.NewRecorder()
+	server.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/docs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `spec-url="openapi.json"`)
+
+	// اسکریپت صفحه از خود سرور می‌آید، نه از CDN
+	require.Contains(t, w.Body.String(), `<script src="docs/redoc.standalone.js">`)
+	require.NotContains(t, w.Body.String(), "https://")
+}
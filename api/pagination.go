@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Headers sent with every page of a list
+const (
+	totalCountHeader = "X-Total-Count"
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// pageRequest holds the query parameters all list endpoints share. Pages are
+// cut with a cursor instead of an offset, so no row is skipped or repeated
+// when rows are added or deleted between two requests.
+type pageRequest struct {
+	Limit         int32     `form:"limit,default=20" binding:"min=1,max=100"`
+	Cursor        string    `form:"cursor"`
+	CreatedAfter  time.Time `form:"created_after"`
+	CreatedBefore time.Time `form:"created_before"`
+}
+
+// pageCursor points at the last row of a page: the next page starts right
+// after it in the sort order the cursor was made for
+type pageCursor struct {
+	Sort string     `json:"sort"`
+	ID   int32      `json:"id"`
+	Name string     `json:"name,omitempty"`
+	Time *time.Time `json:"time,omitempty"`
+}
+
+func newPageCursor(sort string, id int32, name string, createdAt pgtype.Timestamp) pageCursor {
+	cursor := pageCursor{Sort: sort, ID: id, Name: name}
+	if createdAt.Valid {
+		cursor.Time = &createdAt.Time
+	}
+	return cursor
+}
+
+func (cursor pageCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// readCursor decodes the cursor parameter of a request sorted by sort. It
+// returns nil for the first page.
+func readCursor(c *gin.Context, value, sort string) (*pageCursor, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID == 0 {
+		writeError(c, apperr.BadRequest("Invalid cursor"))
+		return nil, false
+	}
+	// مقدارهای cursor فقط برای همان ترتیبی معنا دارند که با آن ساخته شده
+	if cursor.Sort != sort {
+		writeError(c, apperr.BadRequest("Cursor belongs to another sort order"))
+		return nil, false
+	}
+	return &cursor, true
+}
+
+// bindPage reads the query of a list that is always sorted by sort
+func bindPage(c *gin.Context, sort string) (pageRequest, *pageCursor, bool) {
+	var req pageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return req, nil, false
+	}
+	cursor, ok := readCursor(c, req.Cursor, sort)
+	return req, cursor, ok
+}
+
+func (cursor *pageCursor) afterID() pgtype.Int4 {
+	if cursor == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: cursor.ID, Valid: true}
+}
+
+func (cursor *pageCursor) afterName() pgtype.Text {
+	if cursor == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: cursor.Name, Valid: true}
+}
+
+// afterTime is null both on the first page and after a row without a time;
+// the queries tell them apart with afterID
+func (cursor *pageCursor) afterTime() pgtype.Timestamp {
+	if cursor == nil || cursor.Time == nil {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: *cursor.Time, Valid: true}
+}
+
+// writePage sends a page of a list. The queries fetch one row more than the
+// limit; that row only tells there is a next page and isn't sent.
+func writePage[T any](c *gin.Context, rows []T, limit int32, total int64, cursor func(T) pageCursor) {
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		c.Header(nextCursorHeader, cursor(rows[len(rows)-1]).encode())
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	c.Header(totalCountHeader, strconv.FormatInt(total, 10))
+	c.JSON(http.StatusOK, rows)
+}
+
+// optionalText converts an optional query parameter into a nullable value
+func optionalText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+// optionalTimestamp converts an optional time into a nullable timestamp in UTC
+func optionalTimestamp(value time.Time) pgtype.Timestamp {
+	if value.IsZero() {
+		return pgtype.Timestamp{}
+	}
+	return pgtype.Timestamp{Time: value.UTC(), Valid: true}
+}
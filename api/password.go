@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/mail"
+	"github.com/faezefz/SFP_website/throttle"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// resetTokenBytes is the amount of randomness in a password reset token
+const resetTokenBytes = 32
+
+// mailTimeout bounds an email sent after the request has been answered
+const mailTimeout = 30 * time.Second
+
+// resetPolicy limits how often reset links are asked for, whether or not
+// the email is registered. Every request counts.
+var resetPolicy = throttle.Policy{
+	FreeFailures:    3,
+	BaseDelay:       time.Minute,
+	MaxFailures:     5,
+	LockoutDuration: time.Hour,
+	Window:          time.Hour,
+}
+
+// newResetLimiters builds the per-email and per-IP forgot-password limiters
+func newResetLimiters(attempts throttle.Store) (emailLimiter, ipLimiter *throttle.Limiter) {
+	ipPolicy := resetPolicy
+	ipPolicy.FreeFailures *= ipFailureFactor
+	ipPolicy.MaxFailures *= ipFailureFactor
+
+	return throttle.NewLimiter(attempts, resetPolicy), throttle.NewLimiter(attempts, ipPolicy)
+}
+
+// allowResetRequest counts the request against the email and the client IP,
+// or answers 429 with Retry-After and returns false
+func (s *Server) allowResetRequest(c *gin.Context, email string) bool {
+	emailReservation, err := s.resetEmailLimiter.Reserve(c.Request.Context(), "reset-email:"+strings.ToLower(email))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to check reset requests"))
+		return false
+	}
+	ipReservation, err := s.resetIPLimiter.Reserve(c.Request.Context(), "reset-ip:"+c.ClientIP())
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to check reset requests"))
+		return false
+	}
+
+	wait := max(emailReservation.Wait, ipReservation.Wait)
+	if wait == 0 {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	writeError(c, apperr.RateLimited("Too many password reset requests, please try again later", retryAfter))
+	return false
+}
+
+// sendInBackground runs send after the request has been answered; errors are
+// only logged. Run waits for these before returning.
+func (s *Server) sendInBackground(c *gin.Context, send func(ctx context.Context) error) {
+	log := logger(c)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), mailTimeout)
+
+	s.mailJobs.Add(1)
+	go func() {
+		defer s.mailJobs.Done()
+		defer cancel()
+
+		if err := send(ctx); err != nil {
+			log.Error("Error sending email", "error", err)
+		}
+	}()
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword emails a password reset link. It answers the same way
+// whether or not the email is registered, so it can't be used to probe accounts.
+func (s *Server) forgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	if !s.allowResetRequest(c, req.Email) {
+		return
+	}
+
+	// جستجوی کاربر و ارسال ایمیل پس از پاسخ انجام می‌شود تا نه پاسخ و نه
+	// زمان آن نشان ندهد که ایمیل ثبت شده است یا نه
+	s.sendInBackground(c, func(ctx context.Context) error {
+		user, err := s.Db.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		return s.sendPasswordResetEmail(ctx, user)
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "If the email is registered, a password reset link has been sent"})
+}
+
+// sendPasswordResetEmail creates a reset token for the user and mails the link
+func (s *Server) sendPasswordResetEmail(ctx context.Context, user db.User) error {
+	resetToken, err := util.RandomSecret(resetTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Db.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(resetToken),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().UTC().Add(s.config.PasswordResetTokenDuration),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	link := s.config.AppBaseURL + "/reset-password?token=" + url.QueryEscape(resetToken)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your account.\n\n"+
+			"Open this link to choose a new password:\n%s\n\n"+
+			"The link expires in %s. If you didn't ask for it, you can ignore this email.\n",
+			link, s.config.PasswordResetTokenDuration),
+	})
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// resetPassword sets a new password using a token from forgotPassword
+func (s *Server) resetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	if err := s.passwordPolicy.Check(req.NewPassword); err != nil {
+		writeError(c, apperr.BadRequest(err.Error()))
+		return
+	}
+
+	invalid := apperr.BadRequest("Invalid or expired reset token")
+
+	resetToken, err := s.Db.GetPasswordResetTokenByHash(c.Request.Context(), util.HashSecret(req.Token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, invalid)
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch reset token"))
+		return
+	}
+	if resetToken.UsedAt.Valid || time.Now().UTC().After(resetToken.ExpiresAt.Time) {
+		writeError(c, invalid)
+		return
+	}
+
+	user, err := s.Db.GetUserByID(c.Request.Context(), resetToken.UserID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to hash password"))
+		return
+	}
+
+	_, err = s.Db.ResetPasswordTx(c.Request.Context(), db.ResetPasswordTxParams{
+		TokenID:      resetToken.ID,
+		User:         user,
+		PasswordHash: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTokenAlreadyUsed) {
+			writeError(c, invalid)
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to reset password"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Password has been reset, please log in again"})
+}
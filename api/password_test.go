@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
+	"github.com/faezefz/SFP_website/mail"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func forgotPasswordBody(email string) *strings.Reader {
+	return strings.NewReader(`{"email":"` + email + `"}`)
+}
+
+// sentEmails lists the emails the file mailer of the test server wrote
+func sentEmails(t *testing.T, cfg config.Config) []string {
+	files, err := filepath.Glob(filepath.Join(cfg.MailDir, "*.eml"))
+	require.NoError(t, err)
+	return files
+}
+
+// forwardedFor sets the client IP a proxy would report for the request
+func forwardedFor(ip string) func(*http.Request) {
+	return func(request *http.Request) {
+		request.Header.Set("X-Forwarded-For", ip)
+	}
+}
+
+// failingMailer can't send anything
+type failingMailer struct{}
+
+func (failingMailer) Send(context.Context, mail.Message) error {
+	return errors.New("mail server is down")
+}
+
+func TestForgotPassword(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	registered := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(user.Email), nil)
+	require.Equal(t, http.StatusOK, registered.Code, registered.Body.String())
+	server.mailJobs.Wait()
+	require.Len(t, sentEmails(t, server.config), 1)
+	require.Len(t, store.resetTokens, 1)
+
+	// پاسخ برای ایمیل ثبت نشده دقیقاً همان است
+	unknown := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), nil)
+	require.Equal(t, http.StatusOK, unknown.Code, unknown.Body.String())
+	require.JSONEq(t, registered.Body.String(), unknown.Body.String())
+	server.mailJobs.Wait()
+	require.Len(t, sentEmails(t, server.config), 1)
+}
+
+func TestForgotPasswordMailError(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+	server.mailer = failingMailer{}
+
+	recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(user.Email), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	server.mailJobs.Wait()
+}
+
+func TestForgotPasswordThrottle(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	for i := 0; i < resetPolicy.FreeFailures+1; i++ {
+		recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(user.Email), nil)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	// بزرگی یا کوچکی حروف ایمیل محدودیت را دور نمی‌زند
+	recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(strings.ToUpper(user.Email)), nil)
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+	require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	// ایمیل‌های دیگر از همان IP هم محدود می‌شوند
+	for i := 0; i < resetPolicy.FreeFailures*ipFailureFactor; i++ {
+		serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), nil)
+	}
+	recorder = serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), nil)
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+
+	server.mailJobs.Wait()
+}
+
+func TestForgotPasswordThrottleSpoofedIP(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, nil)
+
+	// بدون پراکسی مورد اعتماد، X-Forwarded-For جعلی IP دیگری نمی‌سازد
+	for i := 0; i < resetPolicy.FreeFailures*ipFailureFactor+1; i++ {
+		recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), forwardedFor("198.51.100."+strconv.Itoa(i)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), forwardedFor("203.0.113.1"))
+	requireErrorCode(t, recorder, http.StatusTooManyRequests, apperr.CodeRateLimited)
+
+	server.mailJobs.Wait()
+}
+
+func TestForgotPasswordThrottleTrustedProxy(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, func(cfg *config.Config) {
+		// httptest همه درخواست‌ها را از 192.0.2.1 می‌فرستد
+		cfg.TrustedProxies = []string{"192.0.2.1"}
+	})
+
+	// پشت پراکسی مورد اعتماد، هر کلاینت محدودیت IP خودش را دارد
+	for i := 0; i < resetPolicy.FreeFailures*ipFailureFactor+2; i++ {
+		recorder := serve(server, http.MethodPost, apiPrefix+"/password/forgot", forgotPasswordBody(util.RandomEmail()), forwardedFor("198.51.100."+strconv.Itoa(i)))
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	server.mailJobs.Wait()
+}
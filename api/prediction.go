@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// predictionSummary is a prediction in a list, without the path of its result
+type predictionSummary struct {
+	ID        int32            `json:"id"`
+	UserID    pgtype.Int4      `json:"user_id"`
+	DatasetID pgtype.Int4      `json:"dataset_id"`
+	ModelID   pgtype.Int4      `json:"model_id"`
+	ProjectID pgtype.Int4      `json:"project_id"`
+	Status    pgtype.Text      `json:"status"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type listPredictionsRequest struct {
+	pageRequest
+	Status string `form:"status"`
+	Sort   string `form:"sort,default=id" binding:"oneof=id -id created_at -created_at"`
+}
+
+// listPredictions lists the predictions of the user page by page
+func (s *Server) listPredictions(c *gin.Context) {
+	var req listPredictionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	cursor, ok := readCursor(c, req.Cursor, req.Sort)
+	if !ok {
+		return
+	}
+
+	userID := pgtype.Int4{Int32: currentUserID(c), Valid: true}
+	rows, err := s.Db.ListPredictionsByUserID(c.Request.Context(), db.ListPredictionsByUserIDParams{
+		UserID:        userID,
+		Status:        optionalText(req.Status),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Sort:          req.Sort,
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch predictions"))
+		return
+	}
+
+	total, err := s.Db.CountPredictionsByUserID(c.Request.Context(), db.CountPredictionsByUserIDParams{
+		UserID:        userID,
+		Status:        optionalText(req.Status),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count predictions"))
+		return
+	}
+
+	predictions := make([]predictionSummary, len(rows))
+	for i, row := range rows {
+		predictions[i] = predictionSummary(row)
+	}
+	writePage(c, predictions, req.Limit, total, func(prediction predictionSummary) pageCursor {
+		return newPageCursor(req.Sort, prediction.ID, "", prediction.CreatedAt)
+	})
+}
+
+// getPrediction
+func (s *Server) getPrediction(c *gin.Context) {
+	c.JSON(http.StatusOK, authorizedPrediction(c))
+}
+
+// deletePrediction
+func (s *Server) deletePrediction(c *gin.Context) {
+	prediction := authorizedPrediction(c)
+
+	err := s.Db.DeletePrediction(c.Request.Context(), prediction.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to delete prediction"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionPredictionDeleted,
+		ProjectID:  prediction.ProjectID,
+		Resource:   auditPrediction,
+		ResourceID: prediction.ID,
+		Before:     prediction,
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Prediction deleted successfully"})
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// exportProfile is profile.json in the data export
+type exportProfile struct {
+	User       userResponse      `json:"user"`
+	Identities []db.UserIdentity `json:"identities"`
+	Sessions   []sessionResponse `json:"sessions"`
+	APIKeys    []apiKeyResponse  `json:"api_keys"`
+	ExportedAt time.Time         `json:"exported_at"`
+}
+
+// exportDataset is a dataset in datasets.json; the content itself is a
+// separate file in the archive
+type exportDataset struct {
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	File        string    `json:"file"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// datasetFileName is where the content of a dataset is stored in the archive
+func datasetFileName(id int32, name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(name)
+	return fmt.Sprintf("datasets/%d-%s", id, name)
+}
+
+// exportMyData sends a zip archive with all the personal data we keep about
+// the current user: profile, projects, datasets with their files, models,
+// predictions and log entries
+func (s *Server) exportMyData(c *gin.Context) {
+	user := currentUser(c)
+	owner := pgtype.Int4{Int32: user.ID, Valid: true}
+	ctx := c.Request.Context()
+
+	// همه داده‌ها جز محتوای دیتاست‌ها پیش از شروع پاسخ خوانده می‌شوند تا در
+	// صورت خطا بتوان کد 500 برگرداند؛ محتوای هر دیتاست هنگام نوشتن آن خوانده می‌شود
+	identities, err := s.Db.ListUserIdentitiesByUserID(ctx, user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch identities"))
+		return
+	}
+	sessions, err := s.Db.GetSessionsByUserID(ctx, user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch sessions"))
+		return
+	}
+	apiKeys, err := s.Db.GetApiKeysByUserID(ctx, user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch API keys"))
+		return
+	}
+	projects, err := s.Db.GetProjectsByOwnerID(ctx, user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch projects"))
+		return
+	}
+	datasets, err := s.Db.GetDatasetSummariesByUserID(ctx, owner)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch datasets"))
+		return
+	}
+	models, err := s.Db.GetModelsByUserID(ctx, owner)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch models"))
+		return
+	}
+	predictions, err := s.Db.GetPredictionsByUserID(ctx, owner)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch predictions"))
+		return
+	}
+	logs, err := s.Db.GetLogsByProjectOrUser(ctx, db.GetLogsByProjectOrUserParams{UserID: owner})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch logs"))
+		return
+	}
+
+	profile := exportProfile{
+		User:       newUserResponse(user),
+		Identities: identities,
+		Sessions:   make([]sessionResponse, len(sessions)),
+		APIKeys:    make([]apiKeyResponse, len(apiKeys)),
+		ExportedAt: time.Now().UTC(),
+	}
+	for i, session := range sessions {
+		profile.Sessions[i] = newSessionResponse(session)
+	}
+	for i, key := range apiKeys {
+		profile.APIKeys[i] = newAPIKeyResponse(key)
+	}
+
+	exportDatasets := make([]exportDataset, len(datasets))
+	for i, dataset := range datasets {
+		exportDatasets[i] = exportDataset{
+			ID:          dataset.ID,
+			Name:        dataset.Name,
+			Description: dataset.Description.String,
+			File:        datasetFileName(dataset.ID, dataset.Name),
+			UploadedAt:  dataset.UploadedAt.Time,
+		}
+	}
+
+	fileName := fmt.Sprintf("sfp-export-%d-%s.zip", user.ID, profile.ExportedAt.Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	err = s.writeExportArchive(ctx, archive, []exportFile{
+		{"profile.json", profile},
+		{"projects.json", projects},
+		{"datasets.json", exportDatasets},
+		{"models.json", models},
+		{"predictions.json", predictions},
+		{"logs.json", logs},
+	}, datasets)
+	if err == nil {
+		err = archive.Close()
+	}
+	if err != nil {
+		// هدرها ارسال شده‌اند و فقط می‌توان خطا را ثبت کرد
+		logger(c).Error("Error writing data export", "error", err)
+	}
+}
+
+// exportFile is a JSON document in the data export
+type exportFile struct {
+	name string
+	data any
+}
+
+// writeExportArchive writes the JSON documents and then the content of the
+// datasets, loading one dataset at a time
+func (s *Server) writeExportArchive(ctx context.Context, archive *zip.Writer, files []exportFile, datasets []db.GetDatasetSummariesByUserIDRow) error {
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	for _, summary := range datasets {
+		dataset, err := s.Db.GetDatasetByID(ctx, summary.ID)
+		if err != nil {
+			// دیتاستی که در این فاصله حذف شده دیگر داده‌ای ندارد
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return err
+		}
+
+		w, err := archive.Create(datasetFileName(dataset.ID, dataset.Name))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(dataset.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// erasureRequestResponse is a pending account erasure
+type erasureRequestResponse struct {
+	ID           int32     `json:"id"`
+	ExecuteAfter time.Time `json:"execute_after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newErasureRequestResponse(request db.ErasureRequest) erasureRequestResponse {
+	return erasureRequestResponse{
+		ID:           request.ID,
+		ExecuteAfter: request.ExecuteAfter.Time,
+		CreatedAt:    request.CreatedAt.Time,
+	}
+}
+
+type requestErasureRequest struct {
+	Password string `json:"password"`
+}
+
+// requestErasure schedules the erasure of the account. The erasure job runs
+// it once the grace period is over; until then it can be cancelled.
+func (s *Server) requestErasure(c *gin.Context) {
+	var req requestErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user := currentUser(c)
+	if user.PasswordHash != "" && !s.checkCurrentPassword(user, req.Password) {
+		writeError(c, apperr.Unauthorized("Password is incorrect"))
+		return
+	}
+
+	request, err := s.Db.CreateErasureRequest(c.Request.Context(), db.CreateErasureRequestParams{
+		UserID:       pgtype.Int4{Int32: user.ID, Valid: true},
+		ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC().Add(s.config.ErasureGracePeriod), Valid: true},
+	})
+	if err != nil {
+		if apperr.IsUniqueViolation(err) {
+			writeError(c, apperr.Conflict("Account erasure is already scheduled"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to schedule account erasure"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, newErasureRequestResponse(request))
+}
+
+// getErasureRequest returns the pending erasure of the account, if any
+func (s *Server) getErasureRequest(c *gin.Context) {
+	owner := pgtype.Int4{Int32: currentUserID(c), Valid: true}
+
+	request, err := s.Db.GetPendingErasureRequest(c.Request.Context(), owner)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("No account erasure is scheduled"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch erasure request"))
+		return
+	}
+
+	c.JSON(http.StatusOK, newErasureRequestResponse(request))
+}
+
+// cancelErasure keeps the account by cancelling its pending erasure
+func (s *Server) cancelErasure(c *gin.Context) {
+	owner := pgtype.Int4{Int32: currentUserID(c), Valid: true}
+
+	cancelled, err := s.Db.CancelErasureRequest(c.Request.Context(), owner)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to cancel account erasure"))
+		return
+	}
+	if cancelled == 0 {
+		writeError(c, apperr.NotFound("No account erasure is scheduled"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Account erasure has been cancelled"})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportMyData(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	other := store.addUser()
+	dataset := store.addDataset(user)
+	store.addDataset(other)
+	server := newTestServer(t, store, nil)
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/me/export", nil, func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+
+	body := recorder.Body.Bytes()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+	}
+
+	// فقط دیتاست خود کاربر، هم در فهرست و هم به صورت فایل، در خروجی است
+	var datasets []exportDataset
+	require.NoError(t, json.Unmarshal(files["datasets.json"], &datasets))
+	require.Len(t, datasets, 1)
+	require.Equal(t, dataset.ID, datasets[0].ID)
+	require.Equal(t, dataset.Content, files[datasets[0].File])
+	require.Len(t, files, 7)
+}
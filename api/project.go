@@ -0,0 +1,472 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Allowed values of projects.visibility
+const (
+	visibilityPrivate = "private"
+	visibilityTeam    = "team"
+	visibilityPublic  = "public"
+)
+
+var errTeamRequired = errors.New("team_id is required for team visibility")
+var errNotTeamMember = errors.New("you are not a member of this team")
+
+// checkProjectTeam makes sure a project shared with a team is shared with
+// a team the caller actually belongs to
+func (s *Server) checkProjectTeam(c *gin.Context, visibility string, teamID pgtype.Int4) error {
+	if visibility != visibilityTeam {
+		return nil
+	}
+	if !teamID.Valid {
+		return errTeamRequired
+	}
+
+	ok, err := s.isTeamMember(c.Request.Context(), teamID.Int32, currentUserID(c))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotTeamMember
+	}
+	return nil
+}
+
+// visibilityError writes the response for an error returned by checkProjectTeam
+func visibilityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errTeamRequired):
+		writeError(c, apperr.BadRequest(err.Error()))
+	case errors.Is(err, errNotTeamMember):
+		writeError(c, apperr.Forbidden(err.Error()))
+	default:
+		writeError(c, apperr.Wrap(err, "Failed to check team membership"))
+	}
+}
+
+// optionalInt4 converts an optional JSON number into a nullable column value
+func optionalInt4(value *int32) pgtype.Int4 {
+	if value == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *value, Valid: true}
+}
+
+// getProject
+func (s *Server) getProject(c *gin.Context) {
+	c.JSON(http.StatusOK, authorizedProject(c))
+}
+
+type listProjectsRequest struct {
+	pageRequest
+	Name       string `form:"name"`
+	Visibility string `form:"visibility" binding:"omitempty,oneof=private team public"`
+	Sort       string `form:"sort,default=id" binding:"oneof=id -id name -name created_at -created_at"`
+}
+
+// bindListProjects reads the query of the project lists
+func bindListProjects(c *gin.Context) (listProjectsRequest, *pageCursor, bool) {
+	var req listProjectsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return req, nil, false
+	}
+	cursor, ok := readCursor(c, req.Cursor, req.Sort)
+	return req, cursor, ok
+}
+
+func writeProjectPage(c *gin.Context, req listProjectsRequest, projects []db.Project, total int64) {
+	writePage(c, projects, req.Limit, total, func(project db.Project) pageCursor {
+		return newPageCursor(req.Sort, project.ID, project.Name, project.CreatedAt)
+	})
+}
+
+// listProjects returns the projects the caller owns or collaborates on, page by page
+func (s *Server) listProjects(c *gin.Context) {
+	req, cursor, ok := bindListProjects(c)
+	if !ok {
+		return
+	}
+
+	projects, err := s.Db.ListProjectsForUser(c.Request.Context(), db.ListProjectsForUserParams{
+		UserID:        currentUserID(c),
+		Name:          optionalText(req.Name),
+		Visibility:    optionalText(req.Visibility),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Sort:          req.Sort,
+		AfterName:     cursor.afterName(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch projects"))
+		return
+	}
+
+	total, err := s.Db.CountProjectsForUser(c.Request.Context(), db.CountProjectsForUserParams{
+		UserID:        currentUserID(c),
+		Name:          optionalText(req.Name),
+		Visibility:    optionalText(req.Visibility),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count projects"))
+		return
+	}
+
+	writeProjectPage(c, req, projects, total)
+}
+
+// listProjectDatasets lists the datasets attached to the project page by page
+func (s *Server) listProjectDatasets(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "id")
+	if !ok {
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	rows, err := s.Db.ListDatasetsByProjectID(c.Request.Context(), db.ListDatasetsByProjectIDParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch datasets"))
+		return
+	}
+
+	total, err := s.Db.CountDatasetsByProjectID(c.Request.Context(), db.CountDatasetsByProjectIDParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count datasets"))
+		return
+	}
+
+	datasets := make([]datasetSummary, len(rows))
+	for i, row := range rows {
+		datasets[i] = datasetSummary(row)
+	}
+	writePage(c, datasets, req.Limit, total, func(dataset datasetSummary) pageCursor {
+		return pageCursor{Sort: "id", ID: dataset.ID}
+	})
+}
+
+type attachDatasetRequest struct {
+	DatasetID int32 `json:"dataset_id" binding:"required"`
+}
+
+// attachProjectDataset adds one of the caller's datasets to the project
+func (s *Server) attachProjectDataset(c *gin.Context) {
+	var req attachDatasetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	dataset, err := s.Db.GetDatasetByID(c.Request.Context(), req.DatasetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("dataset not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to load dataset"))
+		return
+	}
+	if ownerOf(dataset.UserID) != currentUserID(c) {
+		writeError(c, apperr.Forbidden("You can only attach your own datasets"))
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	err = s.Db.AddDatasetToProject(c.Request.Context(), db.AddDatasetToProjectParams{
+		ProjectID: projectID,
+		DatasetID: dataset.ID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to attach dataset"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectDatasetAttached,
+		ProjectID:  projectAuditID(projectID),
+		Resource:   auditDataset,
+		ResourceID: dataset.ID,
+		After:      gin.H{"project_id": projectID, "dataset_id": dataset.ID},
+	})
+
+	c.JSON(http.StatusCreated, messageResponse{Message: "Dataset attached successfully"})
+}
+
+// uploadProjectDataset uploads a dataset straight into the project, so an API
+// key restricted to the project can add data to it
+func (s *Server) uploadProjectDataset(c *gin.Context) {
+	arg, ok := bindDatasetUpload(c)
+	if !ok {
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	dataset, err := s.Db.CreateProjectDatasetTx(c.Request.Context(), projectID, arg)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create dataset"))
+		return
+	}
+	s.metrics.uploadSize.Observe(float64(len(arg.Content)))
+
+	// رویداد ساخت دیتاست در لاگ پروژه ثبت می‌شود
+	audit(c, auditEvent{
+		Action:     actionDatasetCreated,
+		ProjectID:  projectAuditID(projectID),
+		Resource:   auditDataset,
+		ResourceID: dataset.ID,
+		After:      dataset,
+	})
+
+	c.JSON(http.StatusCreated, uploadDatasetResponse{DatasetID: dataset.ID})
+}
+
+// detachProjectDataset
+func (s *Server) detachProjectDataset(c *gin.Context) {
+	datasetID, ok := paramID(c, "dataset_id")
+	if !ok {
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	err := s.Db.RemoveDatasetFromProject(c.Request.Context(), db.RemoveDatasetFromProjectParams{
+		ProjectID: projectID,
+		DatasetID: datasetID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to detach dataset"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectDatasetDetached,
+		ProjectID:  projectAuditID(projectID),
+		Resource:   auditDataset,
+		ResourceID: datasetID,
+		Before:     gin.H{"project_id": projectID, "dataset_id": datasetID},
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Dataset detached successfully"})
+}
+
+// listProjectModels lists the models attached to the project page by page
+func (s *Server) listProjectModels(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "id")
+	if !ok {
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	models, err := s.Db.ListModelsByProjectID(c.Request.Context(), db.ListModelsByProjectIDParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch models"))
+		return
+	}
+
+	total, err := s.Db.CountModelsByProjectID(c.Request.Context(), db.CountModelsByProjectIDParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count models"))
+		return
+	}
+
+	writePage(c, models, req.Limit, total, func(model db.Model) pageCursor {
+		return pageCursor{Sort: "id", ID: model.ID}
+	})
+}
+
+type attachModelRequest struct {
+	ModelID int32 `json:"model_id" binding:"required"`
+}
+
+// attachProjectModel adds one of the caller's models to the project
+func (s *Server) attachProjectModel(c *gin.Context) {
+	var req attachModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	model, err := s.Db.GetModelByID(c.Request.Context(), req.ModelID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("model not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to load model"))
+		return
+	}
+	if ownerOf(model.UserID) != currentUserID(c) {
+		writeError(c, apperr.Forbidden("You can only attach your own models"))
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	err = s.Db.AddModelToProject(c.Request.Context(), db.AddModelToProjectParams{
+		ProjectID: projectID,
+		ModelID:   model.ID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to attach model"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectModelAttached,
+		ProjectID:  projectAuditID(projectID),
+		Resource:   auditModel,
+		ResourceID: model.ID,
+		After:      gin.H{"project_id": projectID, "model_id": model.ID},
+	})
+
+	c.JSON(http.StatusCreated, messageResponse{Message: "Model attached successfully"})
+}
+
+type listProjectPredictionsRequest struct {
+	pageRequest
+	Status string `form:"status"`
+}
+
+// listProjectPredictions lists the predictions of the project page by page,
+// optionally only those with the given status
+func (s *Server) listProjectPredictions(c *gin.Context) {
+	var req listProjectPredictionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	cursor, ok := readCursor(c, req.Cursor, "id")
+	if !ok {
+		return
+	}
+
+	projectID := pgtype.Int4{Int32: authorizedProject(c).ID, Valid: true}
+	predictions, err := s.Db.ListPredictionsByProjectID(c.Request.Context(), db.ListPredictionsByProjectIDParams{
+		ProjectID:     projectID,
+		Status:        optionalText(req.Status),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch predictions"))
+		return
+	}
+
+	total, err := s.Db.CountPredictionsByProjectID(c.Request.Context(), db.CountPredictionsByProjectIDParams{
+		ProjectID:     projectID,
+		Status:        optionalText(req.Status),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count predictions"))
+		return
+	}
+
+	writePage(c, predictions, req.Limit, total, func(prediction db.Prediction) pageCursor {
+		return pageCursor{Sort: "id", ID: prediction.ID}
+	})
+}
+
+type createPredictionRequest struct {
+	DatasetID int32 `json:"dataset_id" binding:"required"`
+	ModelID   int32 `json:"model_id" binding:"required"`
+}
+
+// createProjectPrediction runs a prediction with a dataset and a model that
+// are both attached to the project
+func (s *Server) createProjectPrediction(c *gin.Context) {
+	project := authorizedProject(c)
+
+	var req createPredictionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	attached, err := s.Db.IsDatasetInProject(c.Request.Context(), db.IsDatasetInProjectParams{
+		ProjectID: project.ID,
+		DatasetID: req.DatasetID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to check dataset"))
+		return
+	}
+	model, err := s.Db.GetProjectModel(c.Request.Context(), db.GetProjectModelParams{
+		ProjectID: project.ID,
+		ID:        req.ModelID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		writeError(c, apperr.Wrap(err, "Failed to check model"))
+		return
+	}
+	if !attached || err != nil {
+		writeError(c, apperr.BadRequest("Dataset and model must be attached to the project"))
+		return
+	}
+
+	ctx, span := tracer.Start(c.Request.Context(), "prediction.create", trace.WithAttributes(
+		attribute.Int("dataset.id", int(req.DatasetID)),
+		attribute.Int("model.id", int(req.ModelID)),
+		attribute.String("model.type", model.ModelType.String),
+	))
+	start := time.Now()
+	prediction, err := s.Db.CreatePrediction(ctx, db.CreatePredictionParams{
+		UserID:    pgtype.Int4{Int32: currentUserID(c), Valid: true},
+		DatasetID: pgtype.Int4{Int32: req.DatasetID, Valid: true},
+		ModelID:   pgtype.Int4{Int32: req.ModelID, Valid: true},
+		ProjectID: pgtype.Int4{Int32: project.ID, Valid: true},
+	})
+	s.metrics.observeJob(jobPrediction, model.ModelType, start, err)
+	endSpan(span, err)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create prediction"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionPredictionCreated,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditPrediction,
+		ResourceID: prediction.ID,
+		After:      prediction,
+	})
+
+	c.JSON(http.StatusCreated, prediction)
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// listProjectMembers lists the collaborators of the project page by page, in
+// the order they joined; the owner is owner_user_id of the project
+func (s *Server) listProjectMembers(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "created_at")
+	if !ok {
+		return
+	}
+
+	projectID := authorizedProject(c).ID
+	members, err := s.Db.ListProjectMembers(c.Request.Context(), db.ListProjectMembersParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch project members"))
+		return
+	}
+
+	total, err := s.Db.CountProjectMembers(c.Request.Context(), db.CountProjectMembersParams{
+		ProjectID:     projectID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count project members"))
+		return
+	}
+
+	writePage(c, members, req.Limit, total, func(member db.ListProjectMembersRow) pageCursor {
+		return newPageCursor("created_at", member.UserID, "", member.CreatedAt)
+	})
+}
+
+type addMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=viewer editor maintainer"`
+}
+
+// addProjectMember invites an existing user, by email, to collaborate on the project
+func (s *Server) addProjectMember(c *gin.Context) {
+	project := authorizedProject(c)
+
+	var req addMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user, err := s.Db.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("User not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+	if user.ID == project.OwnerUserID {
+		writeError(c, apperr.BadRequest("The owner is already a member of the project"))
+		return
+	}
+
+	_, err = s.Db.GetProjectMember(c.Request.Context(), db.GetProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+	})
+	if err == nil {
+		writeError(c, apperr.Conflict("User is already a member of the project"))
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(c, apperr.Wrap(err, "Failed to check project membership"))
+		return
+	}
+
+	member, err := s.Db.AddProjectMember(c.Request.Context(), db.AddProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      req.Role,
+		InvitedBy: pgtype.Int4{Int32: currentUserID(c), Valid: true},
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to add project member"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectMemberAdded,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProjectMember,
+		ResourceID: member.UserID,
+		After:      member,
+	})
+
+	c.JSON(http.StatusCreated, member)
+}
+
+type updateMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor maintainer"`
+}
+
+// updateProjectMember changes the role of a collaborator
+func (s *Server) updateProjectMember(c *gin.Context) {
+	project := authorizedProject(c)
+
+	userID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var req updateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	before, err := s.Db.GetProjectMember(c.Request.Context(), db.GetProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("Member not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch project member"))
+		return
+	}
+
+	member, err := s.Db.UpdateProjectMemberRole(c.Request.Context(), db.UpdateProjectMemberRoleParams{
+		ProjectID: project.ID,
+		UserID:    userID,
+		Role:      req.Role,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("Member not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to update project member"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectMemberUpdated,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProjectMember,
+		ResourceID: member.UserID,
+		Before:     before,
+		After:      member,
+	})
+
+	c.JSON(http.StatusOK, member)
+}
+
+// removeProjectMember lets maintainers remove collaborators and lets any
+// collaborator leave the project
+func (s *Server) removeProjectMember(c *gin.Context) {
+	project := authorizedProject(c)
+
+	userID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+
+	if userID != currentUserID(c) && authorizedProjectRole(c) < roleMaintainer {
+		writeError(c, apperr.Forbidden("Only maintainers can remove other members"))
+		return
+	}
+
+	removed, err := s.Db.RemoveProjectMember(c.Request.Context(), db.RemoveProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    userID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to remove project member"))
+		return
+	}
+	if removed == 0 {
+		writeError(c, apperr.NotFound("Member not found"))
+		return
+	}
+
+	audit(c, auditEvent{
+		Action:     actionProjectMemberRemoved,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProjectMember,
+		ResourceID: userID,
+		Before:     gin.H{"project_id": project.ID, "user_id": userID},
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Member removed successfully"})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// projectRole is the caller's level of access to a project. Each role can
+// do everything the roles below it can.
+type projectRole int
+
+const (
+	roleNone projectRole = iota
+	roleViewer
+	roleEditor
+	roleMaintainer
+	roleOwner
+)
+
+// Values of project_members.role
+const (
+	memberRoleViewer     = "viewer"
+	memberRoleEditor     = "editor"
+	memberRoleMaintainer = "maintainer"
+)
+
+// projectRoleKey is the context key of the caller's role on the authorized project
+const projectRoleKey = "project_role"
+
+func parseMemberRole(role string) projectRole {
+	switch role {
+	case memberRoleViewer:
+		return roleViewer
+	case memberRoleEditor:
+		return roleEditor
+	case memberRoleMaintainer:
+		return roleMaintainer
+	default:
+		return roleNone
+	}
+}
+
+// projectRoleOf resolves the caller's role on a project. The owner always
+// has roleOwner, collaborators get the role stored in project_members, and
+// anyone else who may read the project under its visibility is a viewer.
+func (s *Server) projectRoleOf(ctx context.Context, project db.Project, userID int32) (projectRole, error) {
+	if project.OwnerUserID == userID {
+		return roleOwner, nil
+	}
+
+	member, err := s.Db.GetProjectMember(ctx, db.GetProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    userID,
+	})
+	if err == nil {
+		return parseMemberRole(member.Role), nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return roleNone, err
+	}
+
+	switch project.Visibility.String {
+	case visibilityPublic:
+		return roleViewer, nil
+	case visibilityTeam:
+		if !project.TeamID.Valid {
+			return roleNone, nil
+		}
+		ok, err := s.isTeamMember(ctx, project.TeamID.Int32, userID)
+		if err != nil || !ok {
+			return roleNone, err
+		}
+		return roleViewer, nil
+	default:
+		return roleNone, nil
+	}
+}
+
+// requireProjectRole loads :project_id and lets the caller through only if
+// their role on it is at least min. Projects the caller can't read at all
+// are reported as missing; readable ones they can't change return 403.
+func (s *Server) requireProjectRole(min projectRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		projectID, ok := paramID(c, "project_id")
+		if !ok {
+			return
+		}
+
+		project, err := s.Db.GetProjectByID(c.Request.Context(), projectID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				writeError(c, apperr.NotFound("project not found"))
+				return
+			}
+			writeError(c, apperr.Wrap(err, "Failed to load project"))
+			return
+		}
+
+		role, err := s.projectRoleOf(c.Request.Context(), project, currentUserID(c))
+		if err != nil {
+			writeError(c, apperr.Wrap(err, "Failed to check project access"))
+			return
+		}
+		if role == roleNone {
+			writeError(c, apperr.NotFound("project not found"))
+			return
+		}
+		if role < min {
+			writeError(c, apperr.Forbidden("Your role on this project doesn't allow this action"))
+			return
+		}
+
+		c.Set(projectKey, project)
+		c.Set(projectRoleKey, role)
+		c.Next()
+	}
+}
+
+// authorizedProjectRole returns the caller's role stored by requireProjectRole
+func authorizedProjectRole(c *gin.Context) projectRole {
+	return c.MustGet(projectRoleKey).(projectRole)
+}
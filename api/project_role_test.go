@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireProjectRole(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	viewer := store.addUser()
+	editor := store.addUser()
+	maintainer := store.addUser()
+	stranger := store.addUser()
+	teammate := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	private := store.addProject(owner, visibilityPrivate)
+	store.addMember(private, viewer, memberRoleViewer)
+	store.addMember(private, editor, memberRoleEditor)
+	store.addMember(private, maintainer, memberRoleMaintainer)
+	public := store.addProject(owner, visibilityPublic)
+
+	team := store.addTeam(owner)
+	store.addTeamMember(team, teammate, true)
+	store.addTeamMember(team, stranger, false)
+	shared := store.addProject(owner, visibilityTeam)
+	shared.TeamID = pgtype.Int4{Int32: team.ID, Valid: true}
+	store.projects[shared.ID] = shared
+
+	// بدنه خالی از middleware می‌گذرد و در handler با 400 رد می‌شود
+	request := func(method string, project db.Project, path string, caller db.User) int {
+		recorder := serve(server, method, apiPrefix+"/projects/"+itoa(project.ID)+path, strings.NewReader("{}"), func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, caller, time.Minute)
+		})
+		return recorder.Code
+	}
+
+	testCases := []struct {
+		name    string
+		method  string
+		project db.Project
+		path    string
+		caller  db.User
+		status  int
+	}{
+		{"OwnerReads", http.MethodGet, private, "", owner, http.StatusOK},
+		{"ViewerReads", http.MethodGet, private, "", viewer, http.StatusOK},
+		{"StrangerCantSeePrivate", http.MethodGet, private, "", stranger, http.StatusNotFound},
+		{"StrangerReadsPublic", http.MethodGet, public, "", stranger, http.StatusOK},
+		{"TeammateReadsTeamProject", http.MethodGet, shared, "", teammate, http.StatusOK},
+		{"InviteeCantSeeTeamProject", http.MethodGet, shared, "", stranger, http.StatusNotFound},
+		{"ViewerCantAttach", http.MethodPost, private, "/datasets", viewer, http.StatusForbidden},
+		{"EditorAttaches", http.MethodPost, private, "/datasets", editor, http.StatusBadRequest},
+		{"StrangerCantAttachToPublic", http.MethodPost, public, "/datasets", stranger, http.StatusForbidden},
+		{"EditorCantUpdate", http.MethodPut, private, "", editor, http.StatusForbidden},
+		{"MaintainerUpdates", http.MethodPut, private, "", maintainer, http.StatusBadRequest},
+		{"OwnerUpdates", http.MethodPut, private, "", owner, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.status, request(tc.method, tc.project, tc.path, tc.caller))
+		})
+	}
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/projects/999999", nil, func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, owner, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusNotFound, apperr.CodeNotFound)
+}
+
+func TestListProjectPredictionsStatus(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	server := newTestServer(t, store, nil)
+	project := store.addProject(owner, visibilityPrivate)
+
+	var done []int32
+	for _, status := range []string{"pending", "done", "failed", "done"} {
+		prediction := store.addPrediction(owner)
+		prediction.ProjectID = pgtype.Int4{Int32: project.ID, Valid: true}
+		prediction.Status = pgtype.Text{String: status, Valid: true}
+		store.predictions[prediction.ID] = prediction
+		if status == "done" {
+			done = append(done, prediction.ID)
+		}
+	}
+	// پیش‌بینی خارج از پروژه با همان وضعیت حساب نمی‌شود
+	other := store.addPrediction(owner)
+	other.Status = pgtype.Text{String: "done", Valid: true}
+	store.predictions[other.ID] = other
+
+	list := func(query string) ([]db.Prediction, *http.Response) {
+		recorder := serve(server, http.MethodGet, apiPrefix+"/projects/"+itoa(project.ID)+"/predictions"+query, nil, func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, owner, time.Minute)
+		})
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var predictions []db.Prediction
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &predictions))
+		return predictions, recorder.Result()
+	}
+
+	predictions, rsp := list("")
+	require.Len(t, predictions, 4)
+	require.Equal(t, "4", rsp.Header.Get(totalCountHeader))
+
+	predictions, rsp = list("?status=done&limit=1")
+	require.Len(t, predictions, 1)
+	require.Equal(t, done[0], predictions[0].ID)
+	require.Equal(t, "2", rsp.Header.Get(totalCountHeader))
+	cursor := rsp.Header.Get(nextCursorHeader)
+	require.NotEmpty(t, cursor)
+
+	predictions, rsp = list("?status=done&limit=1&cursor=" + cursor)
+	require.Len(t, predictions, 1)
+	require.Equal(t, done[1], predictions[0].ID)
+	require.Empty(t, rsp.Header.Get(nextCursorHeader))
+}
@@ -2,219 +2,662 @@ package api
 
 import (
 	"context"
-	"io/ioutil"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/faezefz/SFP_website/apperr"
+	"github.com/faezefz/SFP_website/config"
 	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/mail"
+	"github.com/faezefz/SFP_website/sso"
+	"github.com/faezefz/SFP_website/throttle"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/faezefz/SFP_website/util"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/jackc/pgx/v5/pgxpool"
-	"golang.org/x/crypto/bcrypt" // اضافه کردن این خط برای استفاده از bcrypt
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Server struct
 type Server struct {
-	Db     *db.Queries // فیلد db هم می‌تواند exported باشد (اگر نیاز به دسترسی از خارج پکیج است)
-	Router *gin.Engine // تغییر از router به Router (با حرف بزرگ)
+	Db         db.Store    // فیلد db هم می‌تواند exported باشد (اگر نیاز به دسترسی از خارج پکیج است)
+	Router     *gin.Engine // تغییر از router به Router (با حرف بزرگ)
+	config     config.Config
+	tokenMaker token.Maker
+	mailer     mail.Mailer
+
+	// هش کردن رمزهای عبور و قوانین انتخاب رمز جدید
+	passwordHasher util.PasswordHasher
+	passwordPolicy *util.PasswordPolicy
+
+	// ssoProvider is nil when OIDC single sign-on isn't configured
+	ssoProvider *sso.Provider
+
+	// محدودیت تلاش‌های ناموفق ورود به ازای هر ایمیل و هر IP
+	emailLimiter *throttle.Limiter
+	ipLimiter    *throttle.Limiter
+
+	// محدودیت درخواست‌های بازیابی رمز عبور به ازای هر ایمیل و هر IP
+	resetEmailLimiter *throttle.Limiter
+	resetIPLimiter    *throttle.Limiter
+
+	// محدودیت ارسال دوباره ایمیل تأیید به ازای هر کاربر
+	resendLimiter *throttle.Limiter
+
+	// ایمیل‌هایی که پس از پاسخ به درخواست فرستاده می‌شوند
+	mailJobs sync.WaitGroup
+
+	// متریک‌های Prometheus که در /metrics منتشر می‌شوند
+	metrics *metrics
+
+	// مستند OpenAPI که یک بار هنگام ساخت سرور تولید می‌شود
+	openAPI []byte
 }
 
 // NewServer
-func NewServer(dbPool *pgxpool.Pool) *Server {
+func NewServer(config config.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	mailer, err := newMailer(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	passwordHasher, err := util.NewPasswordHasher(config.PasswordAlgorithm, config.Argon2idParams(), config.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	passwordPolicy, err := util.NewPasswordPolicy(config.PasswordMinLength, config.PasswordMaxLength, config.BreachedPasswordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password policy: %w", err)
+	}
+
+	ssoProvider, err := newSSOProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := newAttemptStore(config, store)
+	emailLimiter, ipLimiter := newLoginLimiters(config, attempts)
+	resetEmailLimiter, resetIPLimiter := newResetLimiters(attempts)
+
 	server := &Server{
-		Db:     db.New(dbPool),
-		Router: gin.Default(),
+		Db:             store,
+		Router:         gin.New(),
+		config:         config,
+		tokenMaker:     tokenMaker,
+		mailer:         mailer,
+		passwordHasher: passwordHasher,
+		passwordPolicy: passwordPolicy,
+		ssoProvider:    ssoProvider,
+		emailLimiter:   emailLimiter,
+		ipLimiter:      ipLimiter,
+
+		resetEmailLimiter: resetEmailLimiter,
+		resetIPLimiter:    resetIPLimiter,
+		resendLimiter:     throttle.NewLimiter(attempts, resendPolicy),
+
+		metrics: newMetrics(store),
+	}
+
+	// بدون پراکسی مورد اعتماد، هر کلاینتی می‌توانست IP خود را با X-Forwarded-For جعل کند
+	if err := server.Router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	server.openAPI, err = server.newOpenAPISpec()
+	if err != nil {
+		return nil, fmt.Errorf("cannot build OpenAPI document: %w", err)
 	}
 
 	server.Routes()
 
-	return server
+	return server, nil
+}
+
+// newMailer picks the mail backend configured with MAILER
+func newMailer(config config.Config) (mail.Mailer, error) {
+	if config.Mailer == "smtp" {
+		return mail.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom), nil
+	}
+	return mail.NewFileMailer(config.MailDir, config.MailFrom)
+}
+
+// newCORSConfig allows the configured origins, or every origin for "*" or
+// when none are configured
+func newCORSConfig(origins []string) cors.Config {
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AddAllowHeaders("Authorization", requestIDHeader)
+	corsConfig.AddExposeHeaders(requestIDHeader, "Retry-After", totalCountHeader, nextCursorHeader)
+	if len(origins) == 0 || slices.Contains(origins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = origins
+	}
+	return corsConfig
 }
 
+// apiPrefix is the base path of version 1 of the API
+const apiPrefix = "/api/v1"
+
 // routes
 func (s *Server) Routes() {
 	// فعال‌سازی CORS برای همه روت‌ها
-	s.Router.Use(cors.Default()) // استفاده از تنظیمات پیش‌فرض CORS
+	s.Router.Use(s.tracingMiddleware())
+	s.Router.Use(cors.New(newCORSConfig(s.config.CORSAllowedOrigins)))
+	s.Router.Use(requestIDMiddleware())
+	s.Router.Use(accessLogMiddleware(), recoveryMiddleware())
+	s.Router.Use(s.metricsMiddleware())
+	s.Router.Use(s.timeoutMiddleware())
+	s.Router.NoRoute(notFound)
+
+	// مسیرهای زیرساختی خارج از نسخه‌بندی API هستند
+	s.Router.GET("/healthz", s.healthz)          // زنده بودن سرور
+	s.Router.GET("/readyz", s.readyz)            // آمادگی برای دریافت درخواست
+	s.Router.GET("/version", s.version)          // نسخه برنامه
+	s.Router.GET("/metrics", s.metricsHandler()) // متریک‌های Prometheus
+
+	// همه مسیرهای API زیر یک نسخه؛ تغییرات ناسازگار به نسخه بعدی می‌روند
+	v1 := s.Router.Group(apiPrefix)
+	v1.GET("/openapi.json", s.openAPISpec)           // مستند OpenAPI
+	v1.GET("/docs", apiDocs)                         // نمایش مستند در مرورگر
+	v1.GET("/docs/redoc.standalone.js", redocBundle) // اسکریپت Redoc از داخل باینری، نه از CDN
 
 	// مسیرهایی که نیازی به احراز هویت ندارند:
-	s.Router.GET("/", s.home)          // صفحه اصلی
-	s.Router.POST("/login", s.login)   // صفحه ورود
-	s.Router.POST("/signup", s.signup) // ثبت‌نام
+	v1.GET("/", s.home)                            // صفحه اصلی
+	v1.POST("/login", s.login)                     // صفحه ورود
+	v1.POST("/login/2fa", s.loginTwoFactor)        // مرحله دوم ورود با کد TOTP یا کد بازیابی
+	v1.POST("/signup", s.signup)                   // ثبت‌نام
+	v1.POST("/auth/refresh", s.refreshAccessToken) // تمدید توکن دسترسی
+	v1.POST("/auth/logout", s.logout)              // خروج از سشن فعلی
+	v1.POST("/password/forgot", s.forgotPassword)  // ارسال لینک بازیابی رمز عبور
+	v1.POST("/password/reset", s.resetPassword)    // تعیین رمز عبور جدید
+	v1.GET("/verify-email", s.verifyEmail)         // تأیید ایمیل با لینک ارسال‌شده
+
+	// ورود با OIDC فقط در صورت تنظیم identity provider
+	if s.ssoProvider != nil {
+		v1.GET("/auth/oidc/login", s.oidcLogin)
+		v1.GET("/auth/oidc/callback", s.oidcCallback)
+	}
 
 	// این گروه فقط برای مسیرهایی که نیاز به احراز هویت دارند:
-	auth := s.Router.Group("/")
+	auth := v1.Group("/")
 	auth.Use(s.authMiddleware()) // فقط این گروه به احراز هویت نیاز دارد
+
+	// ثبت تغییرات پروژه‌ها، دیتاست‌ها، مدل‌ها و پیش‌بینی‌ها در logs
+	auth.Use(s.auditMiddleware())
+
+	// در صورت فعال بودن RequireVerifiedEmail، فقط کاربران با ایمیل تأییدشده
+	verified := s.requireVerifiedEmail()
 	{
-		auth.GET("/dashboard", s.userDashboard) // صفحه داشبورد
-		auth.POST("/datasets", s.uploadDataset) // آپلود داده
-		auth.GET("/datasets", s.listDatasets)
-		auth.POST("/projects", s.createProject)                      // ایجاد پروژه
-		auth.GET("/projects/:owner_user_id", s.getProjectsByOwnerID) // دریافت پروژه‌ها بر اساس owner_user_id
-		auth.PUT("/projects/:project_id", s.updateProject)           // ویرایش پروژه
-		auth.DELETE("/projects/:project_id", s.deleteProject)        // نمایش داده‌ها
+		auth.GET("/dashboard", s.userDashboard)                                             // صفحه داشبورد
+		auth.GET("/me", s.getMe)                                                            // پروفایل کاربر
+		auth.PUT("/me", s.userTokenOnly(), s.updateMe)                                      // ویرایش نام و ایمیل
+		auth.POST("/me/password", s.userTokenOnly(), s.changeMyPassword)                    // تغییر رمز عبور
+		auth.DELETE("/me", s.userTokenOnly(), s.deleteMe)                                   // حذف حساب کاربری
+		auth.GET("/me/export", s.userTokenOnly(), s.exportMyData)                           // دریافت نسخه‌ای از همه اطلاعات شخصی
+		auth.POST("/me/erasure", s.userTokenOnly(), s.requestErasure)                       // درخواست حذف اطلاعات پس از مهلت لغو
+		auth.GET("/me/erasure", s.userTokenOnly(), s.getErasureRequest)                     // وضعیت درخواست حذف
+		auth.DELETE("/me/erasure", s.userTokenOnly(), s.cancelErasure)                      // لغو درخواست حذف
+		auth.POST("/auth/logout-all", s.userTokenOnly(), s.logoutAll)                       // خروج از همه دستگاه‌ها
+		auth.GET("/sessions", s.userTokenOnly(), s.listSessions)                            // لیست سشن‌های فعال
+		auth.DELETE("/sessions/:session_id", s.userTokenOnly(), s.revokeSession)            // باطل کردن یک سشن
+		auth.POST("/api-keys", s.userTokenOnly(), s.createAPIKey)                           // ساخت کلید API
+		auth.GET("/api-keys", s.userTokenOnly(), s.listAPIKeys)                             // لیست کلیدهای API
+		auth.DELETE("/api-keys/:api_key_id", s.userTokenOnly(), s.revokeAPIKey)             // باطل کردن کلید API
+		auth.POST("/verify-email/resend", s.userTokenOnly(), s.resendVerificationEmail)     // ارسال دوباره لینک تأیید ایمیل
+		auth.POST("/2fa/enroll", s.userTokenOnly(), s.enrollTOTP)                           // ساخت کلید TOTP
+		auth.POST("/2fa/confirm", s.userTokenOnly(), s.confirmTOTP)                         // فعال‌سازی ورود دو مرحله‌ای
+		auth.POST("/2fa/disable", s.userTokenOnly(), s.disableTOTP)                         // غیرفعال‌سازی ورود دو مرحله‌ای
+		auth.POST("/datasets", verified, s.uploadDataset)                                   // آپلود داده
+		auth.GET("/datasets", s.listDatasets)                                               // لیست دیتاست‌های کاربر
+		auth.GET("/datasets/:dataset_id", s.datasetOwner(), s.getDataset)                   // دریافت یک دیتاست
+		auth.DELETE("/datasets/:dataset_id", s.datasetOwner(), s.deleteDataset)             // حذف دیتاست
+		auth.GET("/models", s.listModels)                                                   // لیست مدل‌های کاربر
+		auth.GET("/models/:model_id", s.modelOwner(), s.getModel)                           // دریافت یک مدل
+		auth.DELETE("/models/:model_id", s.modelOwner(), s.deleteModel)                     // حذف مدل
+		auth.GET("/predictions", s.listPredictions)                                         // لیست پیش‌بینی‌های کاربر
+		auth.GET("/predictions/:prediction_id", s.predictionOwner(), s.getPrediction)       // دریافت یک پیش‌بینی
+		auth.DELETE("/predictions/:prediction_id", s.predictionOwner(), s.deletePrediction) // حذف پیش‌بینی
+		auth.POST("/projects", verified, s.createProject)                                   // ایجاد پروژه
+		auth.GET("/projects", s.listProjects)                                               // پروژه‌های کاربر و پروژه‌های مشترک
+		auth.GET("/users/:owner_user_id/projects", s.getProjectsByOwnerID)                  // پروژه‌های قابل مشاهده یک کاربر
+
+		// مسیرهای یک پروژه؛ دسترسی بر اساس نقش کاربر در پروژه
+		viewer := s.requireProjectRole(roleViewer)
+		editor := s.requireProjectRole(roleEditor)
+		maintainer := s.requireProjectRole(roleMaintainer)
+		auth.GET("/projects/:project_id", viewer, s.getProject)
+		auth.PUT("/projects/:project_id", maintainer, s.updateProject)
+		auth.DELETE("/projects/:project_id", maintainer, s.deleteProject)
+		auth.GET("/projects/:project_id/members", viewer, s.listProjectMembers)
+		auth.POST("/projects/:project_id/members", maintainer, s.addProjectMember)
+		auth.PUT("/projects/:project_id/members/:user_id", maintainer, s.updateProjectMember)
+		auth.DELETE("/projects/:project_id/members/:user_id", viewer, s.removeProjectMember)
+		auth.GET("/projects/:project_id/datasets", viewer, s.listProjectDatasets)
+		auth.POST("/projects/:project_id/datasets", editor, s.attachProjectDataset)
+		auth.POST("/projects/:project_id/datasets/upload", verified, editor, s.uploadProjectDataset)
+		auth.DELETE("/projects/:project_id/datasets/:dataset_id", editor, s.detachProjectDataset)
+		auth.GET("/projects/:project_id/models", viewer, s.listProjectModels)
+		auth.POST("/projects/:project_id/models", editor, s.attachProjectModel)
+		auth.GET("/projects/:project_id/predictions", viewer, s.listProjectPredictions)
+		auth.POST("/projects/:project_id/predictions", editor, s.createProjectPrediction)
+
+		// تیم‌ها
+		auth.POST("/teams", s.createTeam)
+		auth.GET("/teams", s.listTeams)
+		auth.GET("/teams/:team_id", s.teamMember(), s.getTeam)
+		auth.GET("/teams/:team_id/members", s.teamMember(), s.listTeamMembers)
+		auth.PUT("/teams/:team_id", s.teamOwner(), s.updateTeam)
+		auth.DELETE("/teams/:team_id", s.teamOwner(), s.deleteTeam)
+		auth.POST("/teams/:team_id/invites", s.teamOwner(), s.inviteTeamMember)
+		auth.POST("/teams/:team_id/invites/accept", s.acceptTeamInvite)
+		auth.DELETE("/teams/:team_id/members/:user_id", s.removeTeamMember)
+		auth.GET("/team-invites", s.listTeamInvites)
+
+		// مدیریت کاربران؛ فقط برای مدیران
+		admin := auth.Group("/admin", s.userTokenOnly(), s.requireAdmin())
+		admin.GET("/users", s.adminListUsers)
+		admin.POST("/users/:user_id/disable", s.adminDisableUser)
+		admin.POST("/users/:user_id/enable", s.adminEnableUser)
+		admin.POST("/users/:user_id/force-password-reset", s.adminForcePasswordReset)
+		admin.PUT("/users/:user_id/role", s.adminSetUserRole)
+		admin.DELETE("/users/:user_id", s.adminDeleteUser)
+	}
+}
+
+// Run serves HTTP on addr until ctx is cancelled, then stops accepting
+// connections and waits up to ShutdownTimeout for in-flight requests
+func (s *Server) Run(ctx context.Context, addr string) error {
+	httpServer := &http.Server{
+		Addr:         addr,
+		Handler:      s.Router,
+		ReadTimeout:  s.config.ServerReadTimeout,
+		WriteTimeout: s.config.ServerWriteTimeout,
+		IdleTimeout:  s.config.ServerIdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server, waiting for in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("cannot shut down server: %w", err)
 	}
+
+	// ایمیل‌های در حال ارسال تا پایان مهلت خود فرستاده می‌شوند
+	s.mailJobs.Wait()
+	return nil
 }
 
-// Run
-func (s *Server) Run(addr string) error {
-	return s.Router.Run(addr)
+// messageResponse is returned by endpoints that have nothing else to report
+type messageResponse struct {
+	Message string `json:"message"`
 }
 
 // home
 func (s *Server) home(c *gin.Context) {
 	// ارسال یک پاسخ JSON به فلاتر
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Welcome to the API, please use /login or /signup.",
+	c.JSON(http.StatusOK, messageResponse{
+		Message: "Welcome to the API, please use " + apiPrefix + "/login or " + apiPrefix + "/signup.",
 	})
 }
 
+type signupResponse struct {
+	UserID        int32  `json:"user_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type signupRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	FullName string `json:"full_name"`
+}
+
 // signup
 func (s *Server) signup(c *gin.Context) {
-	type signupRequest struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
-		FullName string `json:"full_name"`
-	}
-
 	var req signupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	if err := s.passwordPolicy.Check(req.Password); err != nil {
+		writeError(c, apperr.BadRequest(err.Error()))
 		return
 	}
 
 	// هش کردن پسورد قبل از ذخیره در دیتابیس
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		writeError(c, apperr.Wrap(err, "Failed to hash password"))
 		return
 	}
 
 	// ذخیره‌سازی پسورد هش شده
 	arg := db.CreateUserParams{
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword), // پسورد هش شده را ذخیره می‌کنیم
+		PasswordHash: hashedPassword, // پسورد هش شده را ذخیره می‌کنیم
 		FullName:     pgtype.Text{String: req.FullName, Valid: req.FullName != ""},
 	}
 
-	user, err := s.Db.CreateUser(context.Background(), arg)
+	user, err := s.Db.CreateUser(c.Request.Context(), arg)
 	if err != nil {
-		log.Printf("Error creating user: %v", arg.Email)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		if apperr.IsUniqueViolation(err) {
+			writeError(c, apperr.Conflict("Email is already registered"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to create user"))
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user_id": user.ID, "email": user.Email})
+	// ارسال لینک تأیید ایمیل؛ در صورت خطا کاربر می‌تواند دوباره درخواست دهد
+	if err := s.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		logger(c).Error("Error sending verification email", "error", err)
+	}
+
+	c.JSON(http.StatusCreated, signupResponse{UserID: user.ID, Email: user.Email, EmailVerified: false})
 }
 
-// login
-func (s *Server) login(c *gin.Context) {
-	type loginRequest struct {
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required"`
+// userResponse is the public view of a user, without the password hash
+type userResponse struct {
+	ID            int32     `json:"id"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	FullName      string    `json:"full_name"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newUserResponse(user db.User) userResponse {
+	return userResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		FullName:      user.FullName.String,
+		Role:          user.Role,
+		CreatedAt:     user.CreatedAt.Time,
 	}
+}
 
+type loginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// login
+func (s *Server) login(c *gin.Context) {
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid data format or missing fields"})
+		writeError(c, apperr.Invalid(err))
 		return
 	}
 
+	// جلوگیری از حدس زدن رمز عبور (brute-force)
+	attempt := s.reserveLoginAttempt(c, req.Email)
+	if attempt == nil {
+		return
+	}
+	defer s.finishLoginAttempt(c, attempt)
+
 	// جستجو برای کاربر در دیتابیس
-	user, err := s.Db.GetUserByEmail(context.Background(), req.Email)
+	user, err := s.Db.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		s.loginFailed(c, attempt, pgtype.Int4{}, req.Email, "unknown email")
+		writeError(c, apperr.Unauthorized("Invalid credentials"))
 		return
 	}
 
 	// مقایسه پسورد وارد شده با پسورد هش شده در دیتابیس
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if !s.checkCurrentPassword(user, req.Password) {
+		s.loginFailed(c, attempt, pgtype.Int4{Int32: user.ID, Valid: true}, req.Email, "wrong password")
+		writeError(c, apperr.Unauthorized("Invalid credentials"))
+		return
+	}
+
+	// هش‌های قدیمی (مثلاً bcrypt) با الگوریتم فعلی دوباره ساخته می‌شوند
+	s.upgradePasswordHash(c, user, req.Password)
+
+	// حساب‌های غیرفعال یا نیازمند تعیین رمز جدید اجازه ورود ندارند
+	if err := passwordLoginAllowed(user); err != nil {
+		s.rejectLogin(c, user, err)
+		return
+	}
+
+	// اگر ورود دو مرحله‌ای فعال باشد، به جای توکن‌ها یک challenge برگردانده می‌شود
+	_, twoFactor, err := s.twoFactorEnabled(c.Request.Context(), user.ID)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		writeError(c, apperr.Wrap(err, "Failed to fetch two-factor settings"))
+		return
+	}
+	if twoFactor {
+		challenge, err := s.startTwoFactorChallenge(user)
+		if err != nil {
+			writeError(c, apperr.Wrap(err, "Failed to create challenge"))
+			return
+		}
+		c.JSON(http.StatusOK, challenge)
 		return
 	}
 
+	// ساخت سشن و توکن‌های دسترسی
+	rsp, err := s.startSession(c, user)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create session"))
+		return
+	}
+	s.loginSucceeded(c, user)
+
 	// لاگین موفق
-	c.JSON(http.StatusOK, gin.H{"user_id": user.ID})
+	c.JSON(http.StatusOK, rsp)
 }
 
-// authMiddleware
-func (s *Server) authMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// هیچ احراز هویتی برای این مسیرها لازم نیست
-		c.Next()
-	}
+type uploadDatasetResponse struct {
+	DatasetID int32 `json:"dataset_id"`
+}
+
+type uploadRequest struct {
+	Name        string `form:"name" binding:"required"`
+	Description string `form:"description"`
 }
 
 // uploadDataset
 func (s *Server) uploadDataset(c *gin.Context) {
-	// تعریف ساختار درخواست
-	type uploadRequest struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
-
-	var req uploadRequest
-
-	// بررسی پارامترهای ورودی
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	arg, ok := bindDatasetUpload(c)
+	if !ok {
 		return
 	}
 
-	// دریافت فایل CSV از درخواست
-	file, _, err := c.Request.FormFile("content")
+	// ایجاد دیتاست در پایگاه داده
+	dataset, err := s.Db.CreateDataset(c.Request.Context(), arg)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		writeError(c, apperr.Wrap(err, "Failed to create dataset"))
 		return
 	}
+	s.metrics.uploadSize.Observe(float64(len(arg.Content)))
 
-	// خواندن فایل به صورت بایت
-	fileContent, err := ioutil.ReadAll(file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
-		return
+	audit(c, auditEvent{
+		Action:     actionDatasetCreated,
+		Resource:   auditDataset,
+		ResourceID: dataset.ID,
+		After:      dataset,
+	})
+
+	// ارسال پاسخ موفقیت‌آمیز
+	c.JSON(http.StatusCreated, uploadDatasetResponse{DatasetID: dataset.ID})
+}
+
+// bindDatasetUpload reads an upload request into the dataset of the current
+// user, or answers the error and returns false
+func bindDatasetUpload(c *gin.Context) (db.CreateDatasetParams, bool) {
+	var req uploadRequest
+
+	// بررسی پارامترهای ورودی (multipart/form-data همراه با فایل)
+	if err := c.ShouldBind(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return db.CreateDatasetParams{}, false
 	}
 
-	// گرفتن ID کاربر از سشن یا کانتکست
-	userID, _ := c.Get("user_id")
+	// دریافت و خواندن فایل CSV از درخواست
+	fileContent, err := readDatasetFile(c)
+	if err != nil {
+		if errors.Is(err, errNoDatasetFile) {
+			writeError(c, apperr.BadRequest("No file uploaded"))
+			return db.CreateDatasetParams{}, false
+		}
+		writeError(c, apperr.Wrap(err, "Failed to read file"))
+		return db.CreateDatasetParams{}, false
+	}
 
 	// آماده‌سازی داده‌ها برای ذخیره در پایگاه داده
-	arg := db.CreateDatasetParams{
-		UserID: pgtype.Int4{Int32: userID.(int32), Valid: true},
+	return db.CreateDatasetParams{
+		UserID: pgtype.Int4{Int32: currentUserID(c), Valid: true},
 		Name:   req.Name,
 		Description: pgtype.Text{
 			String: req.Description,
 			Valid:  req.Description != "",
 		},
 		Content: fileContent, // ذخیره فایل به صورت بایت
+	}, true
+}
+
+// errNoDatasetFile means the upload request carries no dataset file
+var errNoDatasetFile = errors.New("no dataset file uploaded")
+
+// readDatasetFile reads the uploaded dataset in its own span, so slow uploads
+// show up in traces
+func readDatasetFile(c *gin.Context) (content []byte, err error) {
+	_, span := tracer.Start(c.Request.Context(), "dataset.read")
+	defer func() { endSpan(span, err) }()
+
+	file, header, err := c.Request.FormFile("content")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoDatasetFile, err)
 	}
+	defer file.Close()
+	span.SetAttributes(attribute.String("dataset.file_name", header.Filename))
 
-	// ایجاد دیتاست در پایگاه داده
-	dataset, err := s.Db.CreateDataset(context.Background(), arg)
+	content, err = io.ReadAll(file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create dataset"})
-		return
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("dataset.size_bytes", len(content)))
+	return content, nil
+}
 
-	// ارسال پاسخ موفقیت‌آمیز
-	c.JSON(http.StatusCreated, gin.H{"dataset_id": dataset.ID})
+// datasetSummary is a dataset in a list, without its content
+type datasetSummary struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	SizeBytes   int32            `json:"size_bytes"`
+	UploadedAt  pgtype.Timestamp `json:"uploaded_at"`
 }
 
-// listDatasets
+type listDatasetsRequest struct {
+	pageRequest
+	Name string `form:"name"`
+	Sort string `form:"sort,default=id" binding:"oneof=id -id name -name uploaded_at -uploaded_at"`
+}
+
+// listDatasets lists the datasets of the user page by page
 func (s *Server) listDatasets(c *gin.Context) {
-	// به سادگی داده‌ها را نمایش می‌دهیم
-	userID, _ := c.Get("user_id")
-	userIDParam := pgtype.Int4{Int32: userID.(int32), Valid: true}
-	datasets, err := s.Db.GetDatasetsByUserID(context.Background(), userIDParam)
+	var req listDatasetsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+	cursor, ok := readCursor(c, req.Cursor, req.Sort)
+	if !ok {
+		return
+	}
+
+	userID := pgtype.Int4{Int32: currentUserID(c), Valid: true}
+	rows, err := s.Db.ListDatasetsByUserID(c.Request.Context(), db.ListDatasetsByUserIDParams{
+		UserID:        userID,
+		Name:          optionalText(req.Name),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Sort:          req.Sort,
+		AfterName:     cursor.afterName(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch datasets"))
+		return
+	}
+
+	total, err := s.Db.CountDatasetsByUserID(c.Request.Context(), db.CountDatasetsByUserIDParams{
+		UserID:        userID,
+		Name:          optionalText(req.Name),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count datasets"))
+		return
+	}
+
+	datasets := make([]datasetSummary, len(rows))
+	for i, row := range rows {
+		datasets[i] = datasetSummary(row)
+	}
+	writePage(c, datasets, req.Limit, total, func(dataset datasetSummary) pageCursor {
+		return newPageCursor(req.Sort, dataset.ID, dataset.Name, dataset.UploadedAt)
+	})
+}
+
+// getDataset
+func (s *Server) getDataset(c *gin.Context) {
+	c.JSON(http.StatusOK, authorizedDataset(c))
+}
+
+// deleteDataset
+func (s *Server) deleteDataset(c *gin.Context) {
+	dataset := authorizedDataset(c)
+
+	err := s.Db.DeleteDataset(c.Request.Context(), dataset.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch datasets"})
+		writeError(c, apperr.Wrap(err, "Failed to delete dataset"))
 		return
 	}
 
-	c.JSON(http.StatusOK, datasets)
+	audit(c, auditEvent{
+		Action:     actionDatasetDeleted,
+		Resource:   auditDataset,
+		ResourceID: dataset.ID,
+		Before:     dataset,
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Dataset deleted successfully"})
 }
 
 // dashboard
@@ -222,132 +665,206 @@ type dashboardRequest struct {
 	ID pgtype.Int4 `uri:"id" binding:"required"`
 }
 
+type dashboardResponse struct {
+	Message string `json:"message"`
+	UserID  int32  `json:"user_id"`
+}
+
 func (s *Server) userDashboard(c *gin.Context) {
 	var req dashboardRequest
 
 	// دریافت پارامترهای URI
 	if err := c.ShouldBindUri(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID in URL"})
+		writeError(c, apperr.Invalid(err))
 		return
 	}
 
 	// پر کردن Int4 با مقدار id
 	userID := req.ID.Int32
 	if !req.ID.Valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		writeError(c, apperr.BadRequest("Invalid user ID"))
 		return
 	}
 
 	// ارسال اطلاعات پروفایل یا داشبورد
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Welcome to your dashboard",
-		"user_id": userID,
+	c.JSON(http.StatusOK, dashboardResponse{
+		Message: "Welcome to your dashboard",
+		UserID:  userID,
 	})
 }
 
+type createProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private team public"`
+	TeamID      *int32 `json:"team_id"`
+}
+
 // createProject
 func (s *Server) createProject(c *gin.Context) {
-	type createProjectRequest struct {
-		OwnerUserID int32  `json:"owner_user_id" binding:"required"`
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
-	print(c.Request.Body)
 	var req createProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeError(c, apperr.Invalid(err))
 		return
 	}
 
 	// ذخیره پروژه در دیتابیس
+	teamID := optionalInt4(req.TeamID)
+	if err := s.checkProjectTeam(c, req.Visibility, teamID); err != nil {
+		visibilityError(c, err)
+		return
+	}
+
+	// مالک پروژه همیشه کاربر احراز هویت شده است
 	arg := db.CreateProjectParams{
-		OwnerUserID: req.OwnerUserID,
+		OwnerUserID: currentUserID(c),
 		Name:        req.Name,
 		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Visibility:  pgtype.Text{String: req.Visibility, Valid: req.Visibility != ""},
+		TeamID:      teamID,
 	}
 
-	project, err := s.Db.CreateProject(context.Background(), arg)
+	project, err := s.Db.CreateProject(c.Request.Context(), arg)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project"})
+		writeError(c, apperr.Wrap(err, "Failed to create project"))
 		return
 	}
 
+	audit(c, auditEvent{
+		Action:     actionProjectCreated,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProject,
+		ResourceID: project.ID,
+		After:      project,
+	})
+
 	c.JSON(http.StatusCreated, project)
 }
 
 // getProjectsByOwnerID
 func (s *Server) getProjectsByOwnerID(c *gin.Context) {
-	ownerUserID := c.Param("owner_user_id")
-
 	// تبدیل شناسه کاربر از string به int32
-	ownerUserIDInt, err := strconv.Atoi(ownerUserID)
+	ownerUserID, ok := paramID(c, "owner_user_id")
+	if !ok {
+		return
+	}
+
+	req, cursor, ok := bindListProjects(c)
+	if !ok {
+		return
+	}
+
+	// فقط پروژه‌هایی که کاربر اجازه دیدنشان را دارد برگردانده می‌شوند
+	projects, err := s.Db.ListVisibleProjectsByOwnerID(c.Request.Context(), db.ListVisibleProjectsByOwnerIDParams{
+		OwnerUserID:   ownerUserID,
+		ViewerID:      currentUserID(c),
+		Name:          optionalText(req.Name),
+		Visibility:    optionalText(req.Visibility),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Sort:          req.Sort,
+		AfterName:     cursor.afterName(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid owner_user_id format"})
+		writeError(c, apperr.Wrap(err, "Failed to fetch projects"))
 		return
 	}
 
-	// دریافت پروژه‌ها از دیتابیس با استفاده از شناسه کاربر
-	projects, err := s.Db.GetProjectsByOwnerID(context.Background(), int32(ownerUserIDInt))
+	total, err := s.Db.CountVisibleProjectsByOwnerID(c.Request.Context(), db.CountVisibleProjectsByOwnerIDParams{
+		OwnerUserID:   ownerUserID,
+		ViewerID:      currentUserID(c),
+		Name:          optionalText(req.Name),
+		Visibility:    optionalText(req.Visibility),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch projects"})
+		writeError(c, apperr.Wrap(err, "Failed to count projects"))
 		return
 	}
 
-	c.JSON(http.StatusOK, projects)
+	writeProjectPage(c, req, projects, total)
+}
+
+type updateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private team public"`
+	TeamID      *int32 `json:"team_id"`
 }
 
 // updateProject
 func (s *Server) updateProject(c *gin.Context) {
-	type updateProjectRequest struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
+	project := authorizedProject(c)
 
-	projectID := c.Param("project_id")
-	projectIDInt, err := strconv.Atoi(projectID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
+	var req updateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
 		return
 	}
 
-	var req updateProjectRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	teamID := optionalInt4(req.TeamID)
+	if req.Visibility == visibilityTeam && !teamID.Valid {
+		teamID = project.TeamID
+	}
+	if err := s.checkProjectTeam(c, req.Visibility, teamID); err != nil {
+		visibilityError(c, err)
 		return
 	}
 
 	// ویرایش پروژه در دیتابیس
 	arg := db.UpdateProjectParams{
-		ID:          int32(projectIDInt),
+		ID:          project.ID,
 		Name:        req.Name,
 		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Visibility:  pgtype.Text{String: req.Visibility, Valid: req.Visibility != ""},
+		TeamID:      teamID,
 	}
 
-	project, err := s.Db.UpdateProject(context.Background(), arg)
+	// پروژه ممکن است پس از بررسی دسترسی حذف شده باشد
+	project, err := s.Db.UpdateProject(c.Request.Context(), arg)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("Project not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to update project"))
 		return
 	}
 
+	audit(c, auditEvent{
+		Action:     actionProjectUpdated,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProject,
+		ResourceID: project.ID,
+		Before:     authorizedProject(c),
+		After:      project,
+	})
+
 	c.JSON(http.StatusOK, project)
 }
 
 // deleteProject
 func (s *Server) deleteProject(c *gin.Context) {
-	projectID := c.Param("project_id")
-	projectIDInt, err := strconv.Atoi(projectID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project_id format"})
-		return
-	}
-	projectIDInt32 := int32(projectIDInt)
+	project := authorizedProject(c)
 
 	// حذف پروژه از دیتابیس
-	err = s.Db.DeleteProject(context.Background(), projectIDInt32)
+	err := s.Db.DeleteProject(c.Request.Context(), project.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
+		writeError(c, apperr.Wrap(err, "Failed to delete project"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+	audit(c, auditEvent{
+		Action:     actionProjectDeleted,
+		ProjectID:  projectAuditID(project.ID),
+		Resource:   auditProject,
+		ResourceID: project.ID,
+		Before:     project,
+	})
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Project deleted successfully"})
 }
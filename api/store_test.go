@@ -0,0 +1,999 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeStore keeps the rows of the handler tests in memory. It embeds db.Store,
+// so a query the fake doesn't implement panics and the test fails with a 500.
+type fakeStore struct {
+	db.Store
+
+	mu          sync.Mutex
+	nextID      int32
+	users       map[int32]db.User
+	sessions    map[int32]db.Session
+	apiKeys     map[int32]db.ApiKey
+	datasets    map[int32]db.Dataset
+	models      map[int32]db.Model
+	predictions map[int32]db.Prediction
+	projects    map[int32]db.Project
+	members     map[[2]int32]db.ProjectMember
+	attached    map[[2]int32]bool
+	teams       map[int32]db.Team
+	teamMembers map[[2]int32]db.TeamMember
+	totps       map[int32]db.UserTotp
+	resetTokens map[int32]db.PasswordResetToken
+	challenges  map[[16]byte]bool
+	oidcStates  map[string]db.OidcLoginState
+	logs        []db.Log
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:       map[int32]db.User{},
+		sessions:    map[int32]db.Session{},
+		apiKeys:     map[int32]db.ApiKey{},
+		datasets:    map[int32]db.Dataset{},
+		models:      map[int32]db.Model{},
+		predictions: map[int32]db.Prediction{},
+		projects:    map[int32]db.Project{},
+		members:     map[[2]int32]db.ProjectMember{},
+		attached:    map[[2]int32]bool{},
+		teams:       map[int32]db.Team{},
+		teamMembers: map[[2]int32]db.TeamMember{},
+		totps:       map[int32]db.UserTotp{},
+		resetTokens: map[int32]db.PasswordResetToken{},
+		challenges:  map[[16]byte]bool{},
+		oidcStates:  map[string]db.OidcLoginState{},
+	}
+}
+
+func (store *fakeStore) newID() int32 {
+	store.nextID++
+	return store.nextID
+}
+
+// itoa formats an ID for a URL
+func itoa(id int32) string {
+	return strconv.Itoa(int(id))
+}
+
+func now() pgtype.Timestamp {
+	return pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+}
+
+// addUser stores a verified user with a random email
+func (store *fakeStore) addUser() db.User {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user := db.User{
+		ID:              store.newID(),
+		Email:           util.RandomEmail(),
+		Role:            userRoleUser,
+		CreatedAt:       now(),
+		EmailVerifiedAt: now(),
+	}
+	store.users[user.ID] = user
+	return user
+}
+
+// addAdmin stores a verified administrator
+func (store *fakeStore) addAdmin() db.User {
+	user := store.addUser()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user.Role = userRoleAdmin
+	store.users[user.ID] = user
+	return user
+}
+
+func (store *fakeStore) SetUserPasswordResetRequired(_ context.Context, arg db.SetUserPasswordResetRequiredParams) (db.User, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user, ok := store.users[arg.ID]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	user.PasswordResetRequired = arg.PasswordResetRequired
+	store.users[user.ID] = user
+	return user, nil
+}
+
+func (store *fakeStore) UpdateUserProfile(_ context.Context, arg db.UpdateUserProfileParams) (db.User, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user, ok := store.users[arg.ID]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	user.FullName = arg.FullName
+	user.Email = arg.Email
+	if arg.EmailChanged {
+		user.EmailVerifiedAt = pgtype.Timestamp{}
+	}
+	store.users[user.ID] = user
+	return user, nil
+}
+
+func (store *fakeStore) UpdateUserProfileTx(ctx context.Context, arg db.UpdateUserProfileParams) (db.User, error) {
+	return store.UpdateUserProfile(ctx, arg)
+}
+
+func (store *fakeStore) ChangePasswordTx(ctx context.Context, arg db.ChangePasswordTxParams) (db.User, error) {
+	user := store.setPassword(arg.User, arg.PasswordHash)
+	if _, err := store.RevokeUserSessions(ctx, user.ID); err != nil {
+		return db.User{}, err
+	}
+	if _, err := store.RevokeUserApiKeys(ctx, user.ID); err != nil {
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (store *fakeStore) CreateEmailVerificationToken(_ context.Context, arg db.CreateEmailVerificationTokenParams) (db.EmailVerificationToken, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return db.EmailVerificationToken{
+		ID:        store.newID(),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: now(),
+	}, nil
+}
+
+// setPassword stores the password hash of user
+func (store *fakeStore) setPassword(user db.User, passwordHash string) db.User {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user.PasswordHash = passwordHash
+	store.users[user.ID] = user
+	return user
+}
+
+// noProject leaves an API key unrestricted
+var noProject = pgtype.Int4{}
+
+// addAPIKey stores an active key of user and returns its secret
+func (store *fakeStore) addAPIKey(user db.User, projectID pgtype.Int4, scopes ...string) (db.ApiKey, string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	secret := apiKeyPrefix + util.RandomString(32)
+	key := db.ApiKey{
+		ID:         store.newID(),
+		UserID:     user.ID,
+		Name:       "test",
+		Prefix:     secret[:apiKeyDisplayLength],
+		SecretHash: util.HashSecret(secret),
+		Scopes:     scopes,
+		ProjectID:  projectID,
+		CreatedAt:  now(),
+	}
+	store.apiKeys[key.ID] = key
+	return key, secret
+}
+
+func (store *fakeStore) GetUserByID(_ context.Context, id int32) (db.User, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user, ok := store.users[id]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (store *fakeStore) GetUserByEmail(_ context.Context, email string) (db.User, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, user := range store.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (store *fakeStore) GetUserTotp(_ context.Context, userID int32) (db.UserTotp, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	userTotp, ok := store.totps[userID]
+	if !ok {
+		return db.UserTotp{}, pgx.ErrNoRows
+	}
+	return userTotp, nil
+}
+
+// enableTotp turns two-factor authentication on for user with secret
+func (store *fakeStore) enableTotp(user db.User, secret string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.totps[user.ID] = db.UserTotp{
+		UserID:    user.ID,
+		Secret:    secret,
+		EnabledAt: now(),
+		CreatedAt: now(),
+	}
+}
+
+func (store *fakeStore) UseTotpStep(_ context.Context, arg db.UseTotpStepParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	userTotp, ok := store.totps[arg.UserID]
+	if !ok || userTotp.LastUsedStep >= arg.Step {
+		return 0, nil
+	}
+	userTotp.LastUsedStep = arg.Step
+	store.totps[arg.UserID] = userTotp
+	return 1, nil
+}
+
+func (store *fakeStore) UseRecoveryCode(context.Context, db.UseRecoveryCodeParams) (int64, error) {
+	return 0, nil
+}
+
+func (store *fakeStore) ConsumeChallengeToken(_ context.Context, arg db.ConsumeChallengeTokenParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.challenges[arg.TokenID.Bytes] {
+		return 0, nil
+	}
+	store.challenges[arg.TokenID.Bytes] = true
+	return 1, nil
+}
+
+func (store *fakeStore) CreateLog(_ context.Context, arg db.CreateLogParams) (db.Log, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	log := db.Log{
+		ID:        store.newID(),
+		UserID:    arg.UserID,
+		Action:    arg.Action,
+		Details:   arg.Details,
+		IpAddress: arg.IpAddress,
+		CreatedAt: now(),
+	}
+	store.logs = append(store.logs, log)
+	return log, nil
+}
+
+func (store *fakeStore) CreatePasswordResetToken(_ context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	resetToken := db.PasswordResetToken{
+		ID:        store.newID(),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: now(),
+	}
+	store.resetTokens[resetToken.ID] = resetToken
+	return resetToken, nil
+}
+
+// countLogs counts the logs written with action
+func (store *fakeStore) countLogs(action string) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, log := range store.logs {
+		if log.Action.String == action {
+			count++
+		}
+	}
+	return count
+}
+
+func (store *fakeStore) DisableUser(_ context.Context, id int32) (db.User, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user, ok := store.users[id]
+	if !ok {
+		return db.User{}, pgx.ErrNoRows
+	}
+	user.DisabledAt = now()
+	store.users[id] = user
+	return user, nil
+}
+
+func (store *fakeStore) GetApiKeyBySecretHash(_ context.Context, secretHash string) (db.ApiKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, key := range store.apiKeys {
+		if key.SecretHash == secretHash {
+			return key, nil
+		}
+	}
+	return db.ApiKey{}, pgx.ErrNoRows
+}
+
+func (store *fakeStore) TouchApiKey(_ context.Context, id int32) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	key := store.apiKeys[id]
+	key.LastUsedAt = now()
+	store.apiKeys[id] = key
+	return nil
+}
+
+func (store *fakeStore) RevokeUserApiKeys(_ context.Context, userID int32) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var revoked int64
+	for id, key := range store.apiKeys {
+		if key.UserID == userID && !key.RevokedAt.Valid {
+			key.RevokedAt = now()
+			store.apiKeys[id] = key
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// addSession stores an active session of user and returns its refresh token
+func (store *fakeStore) addSession(user db.User) (db.Session, string) {
+	refreshToken := util.RandomString(32)
+	session, _ := store.CreateSession(context.Background(), db.CreateSessionParams{
+		UserID:           user.ID,
+		RefreshTokenHash: util.HashSecret(refreshToken),
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().UTC().Add(time.Hour), Valid: true},
+	})
+	return session, refreshToken
+}
+
+func (store *fakeStore) CreateSession(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session := db.Session{
+		ID:               store.newID(),
+		UserID:           arg.UserID,
+		RefreshTokenHash: arg.RefreshTokenHash,
+		UserAgent:        arg.UserAgent,
+		ClientIp:         arg.ClientIp,
+		ExpiresAt:        arg.ExpiresAt,
+		LastUsedAt:       now(),
+		CreatedAt:        now(),
+	}
+	store.sessions[session.ID] = session
+	return session, nil
+}
+
+func (store *fakeStore) GetSessionByID(_ context.Context, id int32) (db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok {
+		return db.Session{}, pgx.ErrNoRows
+	}
+	return session, nil
+}
+
+func (store *fakeStore) GetSessionByRefreshTokenHash(_ context.Context, refreshTokenHash string) (db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, session := range store.sessions {
+		if session.RefreshTokenHash == refreshTokenHash {
+			return session, nil
+		}
+	}
+	return db.Session{}, pgx.ErrNoRows
+}
+
+func (store *fakeStore) GetSessionByPreviousRefreshTokenHash(_ context.Context, previousRefreshTokenHash pgtype.Text) (db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, session := range store.sessions {
+		if session.PreviousRefreshTokenHash == previousRefreshTokenHash {
+			return session, nil
+		}
+	}
+	return db.Session{}, pgx.ErrNoRows
+}
+
+func (store *fakeStore) RotateSessionRefreshToken(_ context.Context, arg db.RotateSessionRefreshTokenParams) (db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, ok := store.sessions[arg.ID]
+	if !ok || session.IsRevoked || session.RefreshTokenHash != arg.OldRefreshTokenHash || !session.ExpiresAt.Time.After(arg.Now.Time) {
+		return db.Session{}, pgx.ErrNoRows
+	}
+	session.PreviousRefreshTokenHash = pgtype.Text{String: session.RefreshTokenHash, Valid: true}
+	session.RefreshTokenHash = arg.RefreshTokenHash
+	session.UserAgent = arg.UserAgent
+	session.ClientIp = arg.ClientIp
+	session.LastUsedAt = now()
+	store.sessions[session.ID] = session
+	return session, nil
+}
+
+func (store *fakeStore) RevokeSession(_ context.Context, arg db.RevokeSessionParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	session, ok := store.sessions[arg.ID]
+	if !ok || session.UserID != arg.UserID || session.IsRevoked {
+		return 0, nil
+	}
+	session.IsRevoked = true
+	store.sessions[session.ID] = session
+	return 1, nil
+}
+
+func (store *fakeStore) RevokeUserSessions(_ context.Context, userID int32) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var revoked int64
+	for id, session := range store.sessions {
+		if session.UserID == userID && !session.IsRevoked {
+			session.IsRevoked = true
+			store.sessions[id] = session
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func ownerID(user db.User) pgtype.Int4 {
+	return pgtype.Int4{Int32: user.ID, Valid: true}
+}
+
+// addDataset stores a small dataset of user
+func (store *fakeStore) addDataset(user db.User) db.Dataset {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	dataset := db.Dataset{
+		ID:         store.newID(),
+		UserID:     ownerID(user),
+		Name:       util.RandomName(),
+		Content:    []byte("a,b\n1,2\n"),
+		UploadedAt: now(),
+	}
+	store.datasets[dataset.ID] = dataset
+	return dataset
+}
+
+// addModel stores a model of user
+func (store *fakeStore) addModel(user db.User) db.Model {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	model := db.Model{
+		ID:        store.newID(),
+		UserID:    ownerID(user),
+		Name:      util.RandomName(),
+		FilePath:  "models/" + util.RandomString(8),
+		CreatedAt: now(),
+	}
+	store.models[model.ID] = model
+	return model
+}
+
+// addPrediction stores a prediction of user
+func (store *fakeStore) addPrediction(user db.User) db.Prediction {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	prediction := db.Prediction{
+		ID:        store.newID(),
+		UserID:    ownerID(user),
+		Status:    pgtype.Text{String: "pending", Valid: true},
+		CreatedAt: now(),
+	}
+	store.predictions[prediction.ID] = prediction
+	return prediction
+}
+
+func (store *fakeStore) GetDatasetByID(_ context.Context, id int32) (db.Dataset, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	dataset, ok := store.datasets[id]
+	if !ok {
+		return db.Dataset{}, pgx.ErrNoRows
+	}
+	return dataset, nil
+}
+
+func (store *fakeStore) GetDatasetSummariesByUserID(_ context.Context, userID pgtype.Int4) ([]db.GetDatasetSummariesByUserIDRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var summaries []db.GetDatasetSummariesByUserIDRow
+	for _, dataset := range store.datasets {
+		if dataset.UserID == userID {
+			summaries = append(summaries, db.GetDatasetSummariesByUserIDRow{
+				ID:          dataset.ID,
+				UserID:      dataset.UserID,
+				Name:        dataset.Name,
+				Description: dataset.Description,
+				SizeBytes:   int32(len(dataset.Content)),
+				UploadedAt:  dataset.UploadedAt,
+			})
+		}
+	}
+	return summaries, nil
+}
+
+func (store *fakeStore) GetModelsByUserID(_ context.Context, userID pgtype.Int4) ([]db.Model, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var models []db.Model
+	for _, model := range store.models {
+		if model.UserID == userID {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+func (store *fakeStore) GetPredictionsByUserID(_ context.Context, userID pgtype.Int4) ([]db.Prediction, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var predictions []db.Prediction
+	for _, prediction := range store.predictions {
+		if prediction.UserID == userID {
+			predictions = append(predictions, prediction)
+		}
+	}
+	return predictions, nil
+}
+
+func (store *fakeStore) GetProjectsByOwnerID(_ context.Context, ownerUserID int32) ([]db.Project, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var projects []db.Project
+	for _, project := range store.projects {
+		if project.OwnerUserID == ownerUserID {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+func (store *fakeStore) ListUserIdentitiesByUserID(context.Context, int32) ([]db.UserIdentity, error) {
+	return nil, nil
+}
+
+func (store *fakeStore) GetSessionsByUserID(_ context.Context, userID int32) ([]db.Session, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var sessions []db.Session
+	for _, session := range store.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (store *fakeStore) GetApiKeysByUserID(_ context.Context, userID int32) ([]db.ApiKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var keys []db.ApiKey
+	for _, key := range store.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (store *fakeStore) GetLogsByProjectOrUser(_ context.Context, arg db.GetLogsByProjectOrUserParams) ([]db.Log, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var logs []db.Log
+	for _, log := range store.logs {
+		if log.UserID == arg.UserID {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (store *fakeStore) GetModelByID(_ context.Context, id int32) (db.Model, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	model, ok := store.models[id]
+	if !ok {
+		return db.Model{}, pgx.ErrNoRows
+	}
+	return model, nil
+}
+
+func (store *fakeStore) GetPredictionByID(_ context.Context, id int32) (db.Prediction, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	prediction, ok := store.predictions[id]
+	if !ok {
+		return db.Prediction{}, pgx.ErrNoRows
+	}
+	return prediction, nil
+}
+
+// addProject stores a project of owner with the given visibility
+func (store *fakeStore) addProject(owner db.User, visibility string) db.Project {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	project := db.Project{
+		ID:          store.newID(),
+		OwnerUserID: owner.ID,
+		Name:        util.RandomName(),
+		Visibility:  pgtype.Text{String: visibility, Valid: true},
+		CreatedAt:   now(),
+	}
+	store.projects[project.ID] = project
+	return project
+}
+
+func (store *fakeStore) CreateProjectDatasetTx(_ context.Context, projectID int32, arg db.CreateDatasetParams) (db.Dataset, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.projects[projectID]; !ok {
+		return db.Dataset{}, pgx.ErrNoRows
+	}
+	dataset := db.Dataset{
+		ID:          store.newID(),
+		UserID:      arg.UserID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		Content:     arg.Content,
+		UploadedAt:  now(),
+	}
+	store.datasets[dataset.ID] = dataset
+	store.attached[[2]int32{projectID, dataset.ID}] = true
+	return dataset, nil
+}
+
+func (store *fakeStore) IsDatasetInProject(_ context.Context, arg db.IsDatasetInProjectParams) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.attached[[2]int32{arg.ProjectID, arg.DatasetID}], nil
+}
+
+// addMember makes user a collaborator of project
+func (store *fakeStore) addMember(project db.Project, user db.User, role string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.members[[2]int32{project.ID, user.ID}] = db.ProjectMember{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      role,
+		CreatedAt: now(),
+	}
+}
+
+func (store *fakeStore) GetProjectByID(_ context.Context, id int32) (db.Project, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	project, ok := store.projects[id]
+	if !ok {
+		return db.Project{}, pgx.ErrNoRows
+	}
+	return project, nil
+}
+
+func (store *fakeStore) GetProjectMember(_ context.Context, arg db.GetProjectMemberParams) (db.ProjectMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	member, ok := store.members[[2]int32{arg.ProjectID, arg.UserID}]
+	if !ok {
+		return db.ProjectMember{}, pgx.ErrNoRows
+	}
+	return member, nil
+}
+
+// addTeam stores a team of owner; the owner is its first accepted member
+func (store *fakeStore) addTeam(owner db.User) db.Team {
+	store.mu.Lock()
+	team := db.Team{
+		ID:          store.newID(),
+		OwnerUserID: owner.ID,
+		Name:        util.RandomName(),
+		CreatedAt:   now(),
+	}
+	store.teams[team.ID] = team
+	store.mu.Unlock()
+
+	store.addTeamMember(team, owner, true)
+	return team
+}
+
+// addTeamMember invites user to team, accepting the invite if accepted is set
+func (store *fakeStore) addTeamMember(team db.Team, user db.User, accepted bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	member := db.TeamMember{
+		TeamID:    team.ID,
+		UserID:    user.ID,
+		Role:      db.TeamRoleMember,
+		CreatedAt: now(),
+	}
+	if accepted {
+		member.AcceptedAt = now()
+	}
+	store.teamMembers[[2]int32{team.ID, user.ID}] = member
+}
+
+func (store *fakeStore) GetTeamByID(_ context.Context, id int32) (db.Team, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	team, ok := store.teams[id]
+	if !ok {
+		return db.Team{}, pgx.ErrNoRows
+	}
+	return team, nil
+}
+
+func (store *fakeStore) GetTeamMember(_ context.Context, arg db.GetTeamMemberParams) (db.TeamMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	member, ok := store.teamMembers[[2]int32{arg.TeamID, arg.UserID}]
+	if !ok {
+		return db.TeamMember{}, pgx.ErrNoRows
+	}
+	return member, nil
+}
+
+// userTeams returns the teams user has joined, sorted by ID
+func (store *fakeStore) userTeams(userID int32) []db.Team {
+	var teams []db.Team
+	for key, member := range store.teamMembers {
+		if key[1] == userID && member.AcceptedAt.Valid {
+			teams = append(teams, store.teams[key[0]])
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	return teams
+}
+
+func (store *fakeStore) ListTeamsByUserID(_ context.Context, arg db.ListTeamsByUserIDParams) ([]db.Team, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var teams []db.Team
+	for _, team := range store.userTeams(arg.UserID) {
+		if arg.AfterID.Valid && team.ID <= arg.AfterID.Int32 {
+			continue
+		}
+		if len(teams) == int(arg.Limit) {
+			break
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func (store *fakeStore) CountTeamsByUserID(_ context.Context, arg db.CountTeamsByUserIDParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return int64(len(store.userTeams(arg.UserID))), nil
+}
+
+// teamMemberRows returns the members and invitees of team, sorted by user ID
+func (store *fakeStore) teamMemberRows(teamID int32) []db.ListTeamMembersRow {
+	var members []db.ListTeamMembersRow
+	for key, member := range store.teamMembers {
+		if key[0] == teamID {
+			members = append(members, db.ListTeamMembersRow{
+				TeamID:     member.TeamID,
+				UserID:     member.UserID,
+				Role:       member.Role,
+				AcceptedAt: member.AcceptedAt,
+				CreatedAt:  member.CreatedAt,
+				Email:      store.users[member.UserID].Email,
+			})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members
+}
+
+func (store *fakeStore) ListTeamMembers(_ context.Context, arg db.ListTeamMembersParams) ([]db.ListTeamMembersRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var members []db.ListTeamMembersRow
+	for _, member := range store.teamMemberRows(arg.TeamID) {
+		if arg.AfterID.Valid && member.UserID <= arg.AfterID.Int32 {
+			continue
+		}
+		if len(members) == int(arg.Limit) {
+			break
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (store *fakeStore) CountTeamMembers(_ context.Context, arg db.CountTeamMembersParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return int64(len(store.teamMemberRows(arg.TeamID))), nil
+}
+
+// pendingInvites returns the invites user hasn't accepted, newest team first
+func (store *fakeStore) pendingInvites(userID int32) []db.TeamMember {
+	var invites []db.TeamMember
+	for key, member := range store.teamMembers {
+		if key[1] == userID && !member.AcceptedAt.Valid {
+			invites = append(invites, member)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool { return invites[i].TeamID > invites[j].TeamID })
+	return invites
+}
+
+func (store *fakeStore) ListPendingTeamInvites(_ context.Context, arg db.ListPendingTeamInvitesParams) ([]db.TeamMember, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var invites []db.TeamMember
+	for _, invite := range store.pendingInvites(arg.UserID) {
+		if arg.AfterID.Valid && invite.TeamID >= arg.AfterID.Int32 {
+			continue
+		}
+		if len(invites) == int(arg.Limit) {
+			break
+		}
+		invites = append(invites, invite)
+	}
+	return invites, nil
+}
+
+func (store *fakeStore) CountPendingTeamInvites(_ context.Context, arg db.CountPendingTeamInvitesParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return int64(len(store.pendingInvites(arg.UserID))), nil
+}
+
+// projectPredictions returns the predictions of project with the status, if
+// one is given, sorted by ID
+func (store *fakeStore) projectPredictions(projectID pgtype.Int4, status pgtype.Text) []db.Prediction {
+	var predictions []db.Prediction
+	for _, prediction := range store.predictions {
+		if prediction.ProjectID != projectID || (status.Valid && prediction.Status != status) {
+			continue
+		}
+		predictions = append(predictions, prediction)
+	}
+	sort.Slice(predictions, func(i, j int) bool { return predictions[i].ID < predictions[j].ID })
+	return predictions
+}
+
+func (store *fakeStore) ListPredictionsByProjectID(_ context.Context, arg db.ListPredictionsByProjectIDParams) ([]db.Prediction, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var predictions []db.Prediction
+	for _, prediction := range store.projectPredictions(arg.ProjectID, arg.Status) {
+		if arg.AfterID.Valid && prediction.ID <= arg.AfterID.Int32 {
+			continue
+		}
+		if len(predictions) == int(arg.Limit) {
+			break
+		}
+		predictions = append(predictions, prediction)
+	}
+	return predictions, nil
+}
+
+func (store *fakeStore) CountPredictionsByProjectID(_ context.Context, arg db.CountPredictionsByProjectIDParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return int64(len(store.projectPredictions(arg.ProjectID, arg.Status))), nil
+}
+
+func (store *fakeStore) CreateOidcLoginState(_ context.Context, arg db.CreateOidcLoginStateParams) (db.OidcLoginState, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	loginState := db.OidcLoginState{
+		ID:           store.newID(),
+		StateHash:    arg.StateHash,
+		Nonce:        arg.Nonce,
+		CodeVerifier: arg.CodeVerifier,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    now(),
+	}
+	store.oidcStates[arg.StateHash] = loginState
+	return loginState, nil
+}
+
+func (store *fakeStore) ConsumeOidcLoginState(_ context.Context, stateHash string) (db.OidcLoginState, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	loginState, ok := store.oidcStates[stateHash]
+	if !ok {
+		return db.OidcLoginState{}, pgx.ErrNoRows
+	}
+	delete(store.oidcStates, stateHash)
+	return loginState, nil
+}
+
+func (store *fakeStore) DeleteExpiredOidcLoginStates(context.Context, pgtype.Timestamp) error {
+	return nil
+}
+
+// OIDCLoginTx logs in the user with the email of the identity, provisioning
+// one if there is none; identities themselves aren't kept
+func (store *fakeStore) OIDCLoginTx(_ context.Context, arg db.OIDCLoginTxParams) (db.OIDCLoginTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, user := range store.users {
+		if user.Email != arg.Email {
+			continue
+		}
+		if !arg.EmailVerified {
+			return db.OIDCLoginTxResult{}, db.ErrEmailNotVerifiedByIssuer
+		}
+		if store.totps[user.ID].EnabledAt.Valid {
+			return db.OIDCLoginTxResult{}, db.ErrTwoFactorAccount
+		}
+		return db.OIDCLoginTxResult{User: user}, nil
+	}
+
+	user := db.User{ID: store.newID(), Email: arg.Email, Role: userRoleUser, CreatedAt: now()}
+	store.users[user.ID] = user
+	return db.OIDCLoginTxResult{User: user, Created: true}, nil
+}
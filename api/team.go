@@ -0,0 +1,304 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type teamRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// createTeam creates a team owned by the caller
+func (s *Server) createTeam(c *gin.Context) {
+	var req teamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	result, err := s.Db.CreateTeamTx(c.Request.Context(), db.CreateTeamTxParams{
+		OwnerUserID: currentUserID(c),
+		Name:        req.Name,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create team"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.Team)
+}
+
+// listTeams returns the teams the caller belongs to, page by page
+func (s *Server) listTeams(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "id")
+	if !ok {
+		return
+	}
+
+	teams, err := s.Db.ListTeamsByUserID(c.Request.Context(), db.ListTeamsByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch teams"))
+		return
+	}
+
+	total, err := s.Db.CountTeamsByUserID(c.Request.Context(), db.CountTeamsByUserIDParams{
+		UserID:        currentUserID(c),
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count teams"))
+		return
+	}
+
+	writePage(c, teams, req.Limit, total, func(team db.Team) pageCursor {
+		return pageCursor{Sort: "id", ID: team.ID}
+	})
+}
+
+// getTeam returns a team; its members are listed by listTeamMembers
+func (s *Server) getTeam(c *gin.Context) {
+	c.JSON(http.StatusOK, authorizedTeam(c))
+}
+
+// listTeamMembers returns the members and invitees of a team, page by page
+func (s *Server) listTeamMembers(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "created_at")
+	if !ok {
+		return
+	}
+
+	teamID := authorizedTeam(c).ID
+	members, err := s.Db.ListTeamMembers(c.Request.Context(), db.ListTeamMembersParams{
+		TeamID:        teamID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch team members"))
+		return
+	}
+
+	total, err := s.Db.CountTeamMembers(c.Request.Context(), db.CountTeamMembersParams{
+		TeamID:        teamID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count team members"))
+		return
+	}
+
+	writePage(c, members, req.Limit, total, func(member db.ListTeamMembersRow) pageCursor {
+		return newPageCursor("created_at", member.UserID, "", member.CreatedAt)
+	})
+}
+
+// updateTeam renames a team
+func (s *Server) updateTeam(c *gin.Context) {
+	team := authorizedTeam(c)
+
+	var req teamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	team, err := s.Db.UpdateTeam(c.Request.Context(), db.UpdateTeamParams{
+		ID:   team.ID,
+		Name: req.Name,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to update team"))
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// deleteTeam deletes a team; its projects lose their team and become unreadable to former members
+func (s *Server) deleteTeam(c *gin.Context) {
+	team := authorizedTeam(c)
+
+	err := s.Db.DeleteTeam(c.Request.Context(), team.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to delete team"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Team deleted successfully"})
+}
+
+type inviteRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// inviteTeamMember invites an existing user, by email, to join the team
+func (s *Server) inviteTeamMember(c *gin.Context) {
+	team := authorizedTeam(c)
+
+	var req inviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user, err := s.Db.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("User not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+
+	_, err = s.Db.GetTeamMember(c.Request.Context(), db.GetTeamMemberParams{
+		TeamID: team.ID,
+		UserID: user.ID,
+	})
+	if err == nil {
+		writeError(c, apperr.Conflict("User is already invited or a member"))
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(c, apperr.Wrap(err, "Failed to check team membership"))
+		return
+	}
+
+	member, err := s.Db.CreateTeamMember(c.Request.Context(), db.CreateTeamMemberParams{
+		TeamID:    team.ID,
+		UserID:    user.ID,
+		Role:      db.TeamRoleMember,
+		InvitedBy: pgtype.Int4{Int32: currentUserID(c), Valid: true},
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to invite member"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// listTeamInvites returns the caller's pending team invitations, newest first
+func (s *Server) listTeamInvites(c *gin.Context) {
+	req, cursor, ok := bindPage(c, "-created_at")
+	if !ok {
+		return
+	}
+
+	userID := currentUserID(c)
+	invites, err := s.Db.ListPendingTeamInvites(c.Request.Context(), db.ListPendingTeamInvitesParams{
+		UserID:        userID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+		AfterID:       cursor.afterID(),
+		AfterTime:     cursor.afterTime(),
+		Limit:         req.Limit + 1,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch invites"))
+		return
+	}
+
+	total, err := s.Db.CountPendingTeamInvites(c.Request.Context(), db.CountPendingTeamInvitesParams{
+		UserID:        userID,
+		CreatedAfter:  optionalTimestamp(req.CreatedAfter),
+		CreatedBefore: optionalTimestamp(req.CreatedBefore),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to count invites"))
+		return
+	}
+
+	writePage(c, invites, req.Limit, total, func(invite db.TeamMember) pageCursor {
+		return newPageCursor("-created_at", invite.TeamID, "", invite.CreatedAt)
+	})
+}
+
+// acceptTeamInvite accepts the caller's pending invitation to :team_id
+func (s *Server) acceptTeamInvite(c *gin.Context) {
+	teamID, ok := paramID(c, "team_id")
+	if !ok {
+		return
+	}
+
+	member, err := s.Db.AcceptTeamInvite(c.Request.Context(), db.AcceptTeamInviteParams{
+		TeamID: teamID,
+		UserID: currentUserID(c),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("Invite not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to accept invite"))
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// removeTeamMember lets the team owner remove anyone but themselves, and
+// lets any member leave the team or decline an invitation
+func (s *Server) removeTeamMember(c *gin.Context) {
+	teamID, ok := paramID(c, "team_id")
+	if !ok {
+		return
+	}
+	userID, ok := paramID(c, "user_id")
+	if !ok {
+		return
+	}
+
+	team, err := s.Db.GetTeamByID(c.Request.Context(), teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(c, apperr.NotFound("team not found"))
+			return
+		}
+		writeError(c, apperr.Wrap(err, "Failed to load team"))
+		return
+	}
+
+	callerID := currentUserID(c)
+	if callerID != team.OwnerUserID && callerID != userID {
+		writeError(c, apperr.Forbidden("Only the team owner can remove other members"))
+		return
+	}
+	if userID == team.OwnerUserID {
+		writeError(c, apperr.BadRequest("The team owner can't leave the team, delete it instead"))
+		return
+	}
+
+	removed, err := s.Db.DeleteTeamMember(c.Request.Context(), db.DeleteTeamMemberParams{
+		TeamID: team.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to remove member"))
+		return
+	}
+	if removed == 0 {
+		writeError(c, apperr.NotFound("Member not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Member removed successfully"})
+}
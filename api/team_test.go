@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTeamsPages(t *testing.T) {
+	store := newFakeStore()
+	user := store.addUser()
+	other := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	var ids []int32
+	for i := 0; i < 3; i++ {
+		ids = append(ids, store.addTeam(user).ID)
+	}
+	store.addTeamMember(store.addTeam(other), user, false)
+
+	list := func(query string) ([]db.Team, *http.Response) {
+		recorder := serve(server, http.MethodGet, apiPrefix+"/teams"+query, nil, func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+		})
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var teams []db.Team
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &teams))
+		return teams, recorder.Result()
+	}
+
+	// دعوت پذیرفته‌نشده در فهرست و شمارش حساب نمی‌شود
+	teams, rsp := list("?limit=2")
+	require.Len(t, teams, 2)
+	require.Equal(t, ids[:2], []int32{teams[0].ID, teams[1].ID})
+	require.Equal(t, "3", rsp.Header.Get(totalCountHeader))
+	cursor := rsp.Header.Get(nextCursorHeader)
+	require.NotEmpty(t, cursor)
+
+	teams, rsp = list("?limit=2&cursor=" + cursor)
+	require.Len(t, teams, 1)
+	require.Equal(t, ids[2], teams[0].ID)
+	require.Empty(t, rsp.Header.Get(nextCursorHeader))
+
+	recorder := serve(server, http.MethodGet, apiPrefix+"/teams?cursor=abc", nil, func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusBadRequest, apperr.CodeBadRequest)
+}
+
+func TestTeamAccess(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	member := store.addUser()
+	invitee := store.addUser()
+	stranger := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	team := store.addTeam(owner)
+	store.addTeamMember(team, member, true)
+	store.addTeamMember(team, invitee, false)
+
+	request := func(method, path string, caller db.User) int {
+		recorder := serve(server, method, apiPrefix+"/teams/"+path, strings.NewReader("{}"), func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, caller, time.Minute)
+		})
+		return recorder.Code
+	}
+
+	// فقط اعضای پذیرفته‌شده تیم را می‌بینند
+	require.Equal(t, http.StatusOK, request(http.MethodGet, itoa(team.ID), owner))
+	require.Equal(t, http.StatusOK, request(http.MethodGet, itoa(team.ID), member))
+	require.Equal(t, http.StatusNotFound, request(http.MethodGet, itoa(team.ID), invitee))
+	require.Equal(t, http.StatusNotFound, request(http.MethodGet, itoa(team.ID), stranger))
+	require.Equal(t, http.StatusNotFound, request(http.MethodGet, "999999", owner))
+	require.Equal(t, http.StatusBadRequest, request(http.MethodGet, "abc", owner))
+
+	// فقط مالک تیم را تغییر می‌دهد؛ بدنه خالی در handler رد می‌شود
+	require.Equal(t, http.StatusBadRequest, request(http.MethodPut, itoa(team.ID), owner))
+	require.Equal(t, http.StatusForbidden, request(http.MethodPut, itoa(team.ID), member))
+	require.Equal(t, http.StatusForbidden, request(http.MethodPost, itoa(team.ID)+"/invites", member))
+	require.Equal(t, http.StatusForbidden, request(http.MethodDelete, itoa(team.ID), stranger))
+}
+
+func TestListTeamMembersPages(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	member := store.addUser()
+	invitee := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	team := store.addTeam(owner)
+	store.addTeamMember(team, member, true)
+	store.addTeamMember(team, invitee, false)
+
+	list := func(query string) ([]db.ListTeamMembersRow, *http.Response) {
+		recorder := serve(server, http.MethodGet, apiPrefix+"/teams/"+itoa(team.ID)+"/members"+query, nil, func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, member, time.Minute)
+		})
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var members []db.ListTeamMembersRow
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &members))
+		return members, recorder.Result()
+	}
+
+	// دعوت‌شده‌ها هم در فهرست اعضا شمرده می‌شوند
+	members, rsp := list("?limit=2")
+	require.Len(t, members, 2)
+	require.Equal(t, []int32{owner.ID, member.ID}, []int32{members[0].UserID, members[1].UserID})
+	require.Equal(t, "3", rsp.Header.Get(totalCountHeader))
+	cursor := rsp.Header.Get(nextCursorHeader)
+	require.NotEmpty(t, cursor)
+
+	members, rsp = list("?limit=2&cursor=" + cursor)
+	require.Len(t, members, 1)
+	require.Equal(t, invitee.ID, members[0].UserID)
+	require.Empty(t, rsp.Header.Get(nextCursorHeader))
+
+	// دعوت‌شده هنوز عضو نیست و اعضا را نمی‌بیند
+	recorder := serve(server, http.MethodGet, apiPrefix+"/teams/"+itoa(team.ID)+"/members", nil, func(request *http.Request) {
+		addAuthorization(t, request, server, authorizationTypeBearer, invitee, time.Minute)
+	})
+	requireErrorCode(t, recorder, http.StatusNotFound, apperr.CodeNotFound)
+}
+
+func TestListTeamInvitesPages(t *testing.T) {
+	store := newFakeStore()
+	owner := store.addUser()
+	user := store.addUser()
+	server := newTestServer(t, store, nil)
+
+	var ids []int32
+	for i := 0; i < 3; i++ {
+		team := store.addTeam(owner)
+		store.addTeamMember(team, user, false)
+		ids = append(ids, team.ID)
+	}
+	store.addTeamMember(store.addTeam(owner), user, true)
+
+	list := func(query string) ([]db.TeamMember, *http.Response) {
+		recorder := serve(server, http.MethodGet, apiPrefix+"/team-invites"+query, nil, func(request *http.Request) {
+			addAuthorization(t, request, server, authorizationTypeBearer, user, time.Minute)
+		})
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		var invites []db.TeamMember
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &invites))
+		return invites, recorder.Result()
+	}
+
+	// جدیدترین دعوت اول می‌آید و دعوت پذیرفته‌شده شمرده نمی‌شود
+	invites, rsp := list("?limit=2")
+	require.Len(t, invites, 2)
+	require.Equal(t, []int32{ids[2], ids[1]}, []int32{invites[0].TeamID, invites[1].TeamID})
+	require.Equal(t, "3", rsp.Header.Get(totalCountHeader))
+	cursor := rsp.Header.Get(nextCursorHeader)
+	require.NotEmpty(t, cursor)
+
+	invites, rsp = list("?limit=2&cursor=" + cursor)
+	require.Len(t, invites, 1)
+	require.Equal(t, ids[0], invites[0].TeamID)
+	require.Empty(t, rsp.Header.Get(nextCursorHeader))
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of the handlers; request spans come from otelgin
+// and query spans from the pgx tracer set up in main
+var tracer = otel.Tracer("github.com/faezefz/SFP_website/api")
+
+// tracingMiddleware starts a span for every request, continuing the trace of
+// the caller when it sends a traceparent header
+func (s *Server) tracingMiddleware() gin.HandlerFunc {
+	return otelgin.Middleware(s.config.TracingServiceName)
+}
+
+// traceID returns the ID of the trace the request belongs to, for the logs
+// table; it is null when the request isn't traced
+func traceID(c *gin.Context) pgtype.Text {
+	spanContext := trace.SpanContextFromContext(c.Request.Context())
+	return pgtype.Text{String: spanContext.TraceID().String(), Valid: spanContext.HasTraceID()}
+}
+
+// endSpan ends span, marking it as failed when err isn't nil
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// setSpanRequestID links the request span to the X-Request-ID of the request
+func setSpanRequestID(c *gin.Context, id string) {
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+}
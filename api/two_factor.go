@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5 // 8 base32 characters
+)
+
+// TOTP codes use 30 second steps and one step of clock skew either way, like totp.Validate
+const (
+	totpPeriod = 30
+	totpSkew   = 1
+)
+
+// twoFactorChallengeResponse is returned by login instead of tokens when the
+// user has 2FA enabled; the challenge token is exchanged at /login/2fa
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// twoFactorEnabled reports whether the user has a confirmed TOTP secret
+func (s *Server) twoFactorEnabled(ctx context.Context, userID int32) (db.UserTotp, bool, error) {
+	userTotp, err := s.Db.GetUserTotp(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return userTotp, false, nil
+		}
+		return userTotp, false, err
+	}
+	return userTotp, userTotp.EnabledAt.Valid, nil
+}
+
+// totpStep returns the time step a TOTP code was generated for, or false
+// when the code doesn't match any step within the allowed skew
+func totpStep(code, secret string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		if ok, err := totp.ValidateCustom(code, secret, at, opts); err == nil && ok {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// useTotpCode accepts a current TOTP code only once. Its step must be later
+// than the last step accepted for the user, so an observed code can't be replayed.
+func (s *Server) useTotpCode(ctx context.Context, userTotp db.UserTotp, code string) (bool, error) {
+	step, ok := totpStep(strings.TrimSpace(code), userTotp.Secret, time.Now().UTC())
+	if !ok {
+		return false, nil
+	}
+
+	used, err := s.Db.UseTotpStep(ctx, db.UseTotpStepParams{
+		Step:   step,
+		UserID: userTotp.UserID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// checkSecondFactor accepts either a current, unused TOTP code or an unused
+// recovery code; a recovery code is used up when it matches
+func (s *Server) checkSecondFactor(ctx context.Context, userTotp db.UserTotp, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	ok, err := s.useTotpCode(ctx, userTotp, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	used, err := s.Db.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   userTotp.UserID,
+		CodeHash: util.HashSecret(normalizeRecoveryCode(code)),
+	})
+	if err != nil {
+		return false, err
+	}
+	return used == 1, nil
+}
+
+// newRecoveryCodes returns fresh recovery codes in the form "abcd-efgh"
+func newRecoveryCodes() ([]string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes with any case and separators
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) == 8 {
+		code = code[:4] + "-" + code[4:]
+	}
+	return code
+}
+
+// startTwoFactorChallenge issues a short-lived token proving the password step passed
+func (s *Server) startTwoFactorChallenge(user db.User) (twoFactorChallengeResponse, error) {
+	challengeToken, payload, err := s.tokenMaker.CreateToken(user.ID, 0, token.TokenTypeTwoFactorChallenge, s.config.TwoFactorChallengeDuration)
+	if err != nil {
+		return twoFactorChallengeResponse{}, err
+	}
+
+	return twoFactorChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: payload.ExpiredAt,
+	}, nil
+}
+
+type loginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// loginTwoFactor finishes a two-step login with a TOTP or recovery code
+func (s *Server) loginTwoFactor(c *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(req.ChallengeToken, token.TokenTypeTwoFactorChallenge)
+	if err != nil {
+		writeError(c, apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	user, err := s.Db.GetUserByID(c.Request.Context(), payload.UserID)
+	if err != nil {
+		writeError(c, apperr.Unauthorized("Invalid credentials"))
+		return
+	}
+
+	attempt := s.reserveLoginAttempt(c, user.Email)
+	if attempt == nil {
+		return
+	}
+	defer s.finishLoginAttempt(c, attempt)
+
+	if err := passwordLoginAllowed(user); err != nil {
+		s.rejectLogin(c, user, err)
+		return
+	}
+
+	userTotp, enabled, err := s.twoFactorEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch two-factor settings"))
+		return
+	}
+	if !enabled {
+		writeError(c, apperr.Unauthorized("Two-factor authentication is not enabled"))
+		return
+	}
+
+	ok, err := s.checkSecondFactor(c.Request.Context(), userTotp, req.Code)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to verify code"))
+		return
+	}
+	if !ok {
+		s.loginFailed(c, attempt, pgtype.Int4{Int32: user.ID, Valid: true}, user.Email, "wrong two-factor code")
+		writeError(c, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	// هر challenge فقط یک بار به توکن تبدیل می‌شود
+	consumed, err := s.Db.ConsumeChallengeToken(c.Request.Context(), db.ConsumeChallengeTokenParams{
+		Now:       pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		TokenID:   pgtype.UUID{Bytes: payload.ID, Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: payload.ExpiredAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to use challenge"))
+		return
+	}
+	if consumed == 0 {
+		writeError(c, apperr.Unauthorized("Challenge has already been used"))
+		return
+	}
+
+	rsp, err := s.startSession(c, user)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to create session"))
+		return
+	}
+	s.loginSucceeded(c, user)
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+// enrollTOTPResponse carries the new secret; otpauth_url can be shown as a QR code
+type enrollTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// enrollTOTP creates a new, not yet enabled, TOTP secret for the current user
+func (s *Server) enrollTOTP(c *gin.Context) {
+	user, err := s.Db.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+
+	_, enabled, err := s.twoFactorEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch two-factor settings"))
+		return
+	}
+	if enabled {
+		writeError(c, apperr.BadRequest("Two-factor authentication is already enabled"))
+		return
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.config.TOTPIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to generate secret"))
+		return
+	}
+
+	_, err = s.Db.UpsertUserTotp(c.Request.Context(), db.UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: key.Secret(),
+	})
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to save secret"))
+		return
+	}
+
+	// otpauth_url را می‌توان به صورت QR code به کاربر نشان داد
+	c.JSON(http.StatusOK, enrollTOTPResponse{Secret: key.Secret(), OTPAuthURL: key.URL()})
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// confirmTOTP enables 2FA once the user proves their app produces valid codes,
+// and returns the recovery codes; they are shown only this once
+func (s *Server) confirmTOTP(c *gin.Context) {
+	var req confirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	userTotp, enabled, err := s.twoFactorEnabled(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch two-factor settings"))
+		return
+	}
+	if enabled {
+		writeError(c, apperr.BadRequest("Two-factor authentication is already enabled"))
+		return
+	}
+	if userTotp.Secret == "" {
+		writeError(c, apperr.BadRequest("Start enrollment first"))
+		return
+	}
+
+	ok, err := s.useTotpCode(c.Request.Context(), userTotp, req.Code)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to verify code"))
+		return
+	}
+	if !ok {
+		writeError(c, apperr.BadRequest("Invalid code"))
+		return
+	}
+
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to generate recovery codes"))
+		return
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = util.HashSecret(code)
+	}
+
+	_, err = s.Db.EnableTotpTx(c.Request.Context(), userTotp.UserID, hashes)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to enable two-factor authentication"))
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// disableTOTP turns 2FA off; it needs both the password and a second factor
+func (s *Server) disableTOTP(c *gin.Context) {
+	var req disableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeError(c, apperr.Invalid(err))
+		return
+	}
+
+	user, err := s.Db.GetUserByID(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch user"))
+		return
+	}
+
+	if !s.checkCurrentPassword(user, req.Password) {
+		writeError(c, apperr.Unauthorized("Invalid credentials"))
+		return
+	}
+
+	userTotp, enabled, err := s.twoFactorEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to fetch two-factor settings"))
+		return
+	}
+	if !enabled {
+		writeError(c, apperr.BadRequest("Two-factor authentication is not enabled"))
+		return
+	}
+
+	ok, err := s.checkSecondFactor(c.Request.Context(), userTotp, req.Code)
+	if err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to verify code"))
+		return
+	}
+	if !ok {
+		writeError(c, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	if err := s.Db.DisableTotpTx(c.Request.Context(), user.ID); err != nil {
+		writeError(c, apperr.Wrap(err, "Failed to disable two-factor authentication"))
+		return
+	}
+
+	c.JSON(http.StatusOK, messageResponse{Message: "Two-factor authentication has been disabled"})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/apperr"
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/faezefz/SFP_website/token"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+)
+
+func loginBody(email, password string) *strings.Reader {
+	return strings.NewReader(`{"email":"` + email + `","password":"` + password + `"}`)
+}
+
+func loginTwoFactorBody(challengeToken, code string) *strings.Reader {
+	return strings.NewReader(`{"challenge_token":"` + challengeToken + `","code":"` + code + `"}`)
+}
+
+// twoFactorUser stores a user with a password and 2FA enabled, and returns
+// the password and the TOTP secret
+func twoFactorUser(t *testing.T, store *fakeStore, server *Server) (db.User, string, string) {
+	password := util.RandomPassword()
+	hash, err := server.passwordHasher.Hash(password)
+	require.NoError(t, err)
+	user := store.setPassword(store.addUser(), hash)
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "SFP", AccountName: user.Email})
+	require.NoError(t, err)
+	store.enableTotp(user, key.Secret())
+	return user, password, key.Secret()
+}
+
+// startChallenge logs in with the password and returns the challenge token
+func startChallenge(t *testing.T, server *Server, user db.User, password string) string {
+	recorder := serve(server, http.MethodPost, apiPrefix+"/login", loginBody(user.Email, password), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp twoFactorChallengeResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.True(t, rsp.TwoFactorRequired)
+	return rsp.ChallengeToken
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	code, err := totp.GenerateCode(secret, at)
+	require.NoError(t, err)
+	return code
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, nil)
+	user, password, secret := twoFactorUser(t, store, server)
+
+	challenge := startChallenge(t, server, user, password)
+	recorder := serve(server, http.MethodPost, apiPrefix+"/login/2fa", loginTwoFactorBody(challenge, totpCode(t, secret, time.Now())), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var rsp loginResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.NotEmpty(t, rsp.AccessToken)
+}
+
+func TestLoginTwoFactorReplay(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, nil)
+	user, password, secret := twoFactorUser(t, store, server)
+
+	challenge := startChallenge(t, server, user, password)
+	code := totpCode(t, secret, time.Now())
+	recorder := serve(server, http.MethodPost, apiPrefix+"/login/2fa", loginTwoFactorBody(challenge, code), nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// همان کد با challenge تازه دوباره پذیرفته نمی‌شود
+	recorder = serve(server, http.MethodPost, apiPrefix+"/login/2fa", loginTwoFactorBody(startChallenge(t, server, user, password), code), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	// کد گام قبلی هم پذیرفته نمی‌شود
+	recorder = serve(server, http.MethodPost, apiPrefix+"/login/2fa", loginTwoFactorBody(startChallenge(t, server, user, password), totpCode(t, secret, time.Now().Add(-totpPeriod*time.Second))), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+
+	// challenge مصرف شده با کد معتبر بعدی هم رد می‌شود
+	recorder = serve(server, http.MethodPost, apiPrefix+"/login/2fa", loginTwoFactorBody(challenge, totpCode(t, secret, time.Now().Add(totpPeriod*time.Second))), nil)
+	requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+}
+
+func TestLoginTwoFactorRejected(t *testing.T) {
+	store := newFakeStore()
+	server := newTestServer(t, store, nil)
+	user, password, secret := twoFactorUser(t, store, server)
+	withoutTwoFactor := store.addUser()
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.ID, 0, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	expired, _, err := server.tokenMaker.CreateToken(user.ID, 0, token.TokenTypeTwoFactorChallenge, -time.Minute)
+	require.NoError(t, err)
+	notEnabled, _, err := server.tokenMaker.CreateToken(withoutTwoFactor.ID, 0, token.TokenTypeTwoFactorChallenge, time.Minute)
+	require.NoError(t, err)
+
+	code := totpCode(t, secret, time.Now())
+	for name, body := range map[string]*strings.Reader{
+		"WrongCode":    loginTwoFactorBody(startChallenge(t, server, user, password), "000000"),
+		"AccessToken":  loginTwoFactorBody(accessToken, code),
+		"ExpiredToken": loginTwoFactorBody(expired, code),
+		"InvalidToken": loginTwoFactorBody("invalid", code),
+		"NotEnabled":   loginTwoFactorBody(notEnabled, code),
+		"UnknownUser":  loginTwoFactorBody(mustChallenge(t, server, 1000), code),
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := serve(server, http.MethodPost, apiPrefix+"/login/2fa", body, nil)
+			requireErrorCode(t, recorder, http.StatusUnauthorized, apperr.CodeUnauthorized)
+		})
+	}
+}
+
+func mustChallenge(t *testing.T, server *Server, userID int32) string {
+	challenge, _, err := server.tokenMaker.CreateToken(userID, 0, token.TokenTypeTwoFactorChallenge, time.Minute)
+	require.NoError(t, err)
+	return challenge
+}
@@ -0,0 +1,135 @@
+// Package apperr describes the errors the API sends to clients: an HTTP
+// status, a stable machine-readable code and a message, with field-level
+// details when the request failed validation
+package apperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Code identifies the kind of error. Codes are part of the API, so clients
+// can rely on them instead of the message; don't rename them.
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidation       Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeConflict         Code = "conflict"
+	CodeInvalidReference Code = "invalid_reference"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal"
+)
+
+// Postgres error codes mapped to client errors
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// Error is the JSON envelope of every error response
+type Error struct {
+	Status  int          `json:"-"`
+	Code    Code         `json:"code"`
+	Message string       `json:"error"`
+	Details []FieldError `json:"details,omitempty"`
+	// RetryAfter is the number of seconds to wait before retrying
+	RetryAfter int    `json:"retry_after,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	// Err is the underlying error; it is logged but never sent to the client
+	Err error `json:"-"`
+}
+
+// FieldError explains why one field of the request was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error with the given status, code and message
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// RateLimited asks the client to wait retryAfter seconds
+func RateLimited(message string, retryAfter int) *Error {
+	e := New(http.StatusTooManyRequests, CodeRateLimited, message)
+	e.RetryAfter = retryAfter
+	return e
+}
+
+// Internal hides err behind message
+func Internal(err error, message string) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal, message)
+	e.Err = err
+	return e
+}
+
+// Wrap turns err into the error the client should see. Database errors caused
+// by the request become client errors: a missing row is 404, a duplicate key
+// 409 and a reference to a missing row 422. Any other error is a 500 with
+// message.
+func Wrap(err error, message string) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		e = NotFound("Resource not found")
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		e = Conflict("Resource already exists")
+	case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation:
+		e = New(http.StatusUnprocessableEntity, CodeInvalidReference, "Referenced resource does not exist")
+	default:
+		return Internal(err, message)
+	}
+	e.Err = err
+	return e
+}
+
+// IsUniqueViolation reports whether err is a duplicate key error
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,82 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   Code
+	}{
+		{"no rows", fmt.Errorf("get project: %w", pgx.ErrNoRows), http.StatusNotFound, CodeNotFound},
+		{"unique violation", &pgconn.PgError{Code: uniqueViolation}, http.StatusConflict, CodeConflict},
+		{"foreign key violation", &pgconn.PgError{Code: foreignKeyViolation}, http.StatusUnprocessableEntity, CodeInvalidReference},
+		{"other postgres error", &pgconn.PgError{Code: "42P01"}, http.StatusInternalServerError, CodeInternal},
+		{"other error", errors.New("disk full"), http.StatusInternalServerError, CodeInternal},
+		{"app error", Forbidden("Nope"), http.StatusForbidden, CodeForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := Wrap(tc.err, "Failed to do it")
+			require.Equal(t, tc.status, e.Status)
+			require.Equal(t, tc.code, e.Code)
+			require.ErrorIs(t, e, tc.err)
+		})
+	}
+
+	// پیام خطای داخلی به کلاینت نمی‌رسد
+	body, err := json.Marshal(Wrap(errors.New("secret detail"), "Failed to do it"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"code":"internal","error":"Failed to do it"}`, string(body))
+}
+
+func TestInvalid(t *testing.T) {
+	type page struct {
+		Limit int `form:"limit" binding:"max=100"`
+	}
+	type request struct {
+		page
+		Email  string   `json:"email" binding:"required,email"`
+		Name   string   `json:"name" binding:"required,min=3"`
+		Role   string   `json:"role" binding:"oneof=viewer editor"`
+		Scopes []string `json:"scopes" binding:"dive,oneof=read write"`
+	}
+	validate := validator.New()
+	validate.SetTagName("binding")
+	validate.RegisterTagNameFunc(FieldName)
+
+	err := validate.Struct(request{page: page{Limit: 500}, Email: "nope", Name: "ab", Role: "owner", Scopes: []string{"read", "delete"}})
+	e := Invalid(err)
+	require.Equal(t, http.StatusBadRequest, e.Status)
+	require.Equal(t, CodeValidation, e.Code)
+	require.Equal(t, []FieldError{
+		{Field: "limit", Rule: "max", Message: "must be at most 100"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "name", Rule: "min", Message: "must be at least 3 characters"},
+		{Field: "role", Rule: "oneof", Message: "must be one of: viewer, editor"},
+		{Field: "scopes[1]", Rule: "oneof", Message: "must be one of: read, write"},
+	}, e.Details)
+
+	var req request
+	err = json.Unmarshal([]byte(`{"email": 42}`), &req)
+	e = Invalid(err)
+	require.Equal(t, CodeValidation, e.Code)
+	require.Equal(t, []FieldError{{Field: "email", Rule: "type", Message: "must be a string"}}, e.Details)
+
+	e = Invalid(json.Unmarshal([]byte(`{`), &req))
+	require.Equal(t, CodeBadRequest, e.Code)
+	require.Empty(t, e.Details)
+}
@@ -0,0 +1,125 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Invalid turns a request binding error into a 400. Failed binding tags and
+// fields of the wrong JSON type are listed in Details, one entry per field.
+func Invalid(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var e *Error
+	switch {
+	case errors.As(err, &validationErrs):
+		e = New(http.StatusBadRequest, CodeValidation, "Invalid request")
+		for _, fieldErr := range validationErrs {
+			e.Details = append(e.Details, FieldError{
+				Field:   fieldName(fieldErr),
+				Rule:    fieldErr.Tag(),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+	case errors.As(err, &typeErr):
+		e = New(http.StatusBadRequest, CodeValidation, "Invalid request")
+		e.Details = []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + typeName(typeErr.Type),
+		}}
+	case errors.Is(err, io.EOF):
+		e = BadRequest("Request body is empty")
+	default:
+		e = BadRequest("Malformed request")
+	}
+	e.Err = err
+	return e
+}
+
+// embeddedName stands for an embedded struct in the path of a field. Its
+// fields are sent as fields of the outer struct, so it is left out of errors.
+const embeddedName = "~"
+
+// FieldName returns the name of a struct field in requests: its json, form or
+// uri tag. Register it with the validator so errors name fields the way
+// clients send them.
+func FieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	if field.Anonymous {
+		return embeddedName
+	}
+	return field.Name
+}
+
+// fieldName is the path of the field without the name of the request struct,
+// e.g. scopes[0]
+func fieldName(fieldErr validator.FieldError) string {
+	_, name, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return strings.ReplaceAll(name, embeddedName+".", "")
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "min":
+		return "must be at least " + param + sizeUnit(fieldErr.Kind())
+	case "max":
+		return "must be at most " + param + sizeUnit(fieldErr.Kind())
+	default:
+		return fmt.Sprintf("failed the %q rule", fieldErr.Tag())
+	}
+}
+
+// sizeUnit names what min and max count for a kind of field
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
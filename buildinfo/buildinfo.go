@@ -0,0 +1,57 @@
+// Package buildinfo describes the running build. Version, Commit and Date are
+// set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/faezefz/SFP_website/buildinfo.Version=v1.2.0"
+//
+// and otherwise taken from the VCS information the Go toolchain embeds.
+package buildinfo
+
+import "runtime/debug"
+
+// Set with -ldflags -X
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info is the build information reported by /version
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information, preferring values set with -ldflags
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date}
+
+	build, ok := debug.ReadBuildInfo()
+	if ok {
+		info.GoVersion = build.GoVersion
+		if info.Version == "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/faezefz/SFP_website/logging"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// minTokenKeySize matches what the token maker accepts
+const minTokenKeySize = 32
+
+// Config stores the settings of the application. Every field is read from the
+// setting named in its config tag; see Load for where settings come from.
+type Config struct {
+	// HTTP server; CORSAllowedOrigins is "*" or a list of origins.
+	// TrustedProxies lists the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-For is believed; with none, the client IP is the peer address.
+	ListenAddress      string   `config:"LISTEN_ADDRESS" default:":8080"`
+	CORSAllowedOrigins []string `config:"CORS_ALLOWED_ORIGINS" default:"*"`
+	TrustedProxies     []string `config:"TRUSTED_PROXIES"`
+
+	// HTTP timeouts. RequestTimeout is the deadline of the work done for a
+	// request and SlowRequestTimeout that of uploads, exports and account
+	// deletion. On shutdown, requests get ShutdownTimeout to finish.
+	ServerReadTimeout  time.Duration `config:"SERVER_READ_TIMEOUT" default:"1m"`
+	ServerWriteTimeout time.Duration `config:"SERVER_WRITE_TIMEOUT" default:"3m"`
+	ServerIdleTimeout  time.Duration `config:"SERVER_IDLE_TIMEOUT" default:"2m"`
+	RequestTimeout     time.Duration `config:"REQUEST_TIMEOUT" default:"15s"`
+	SlowRequestTimeout time.Duration `config:"SLOW_REQUEST_TIMEOUT" default:"2m"`
+	ShutdownTimeout    time.Duration `config:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// Logging; LogFormat is json | text and LogLevel debug | info | warn | error
+	LogFormat string `config:"LOG_FORMAT" default:"json"`
+	LogLevel  string `config:"LOG_LEVEL" default:"info"`
+
+	// Database; DBSource is a postgres URL
+	DBSource          string        `config:"DB_SOURCE"`
+	DBMaxConns        int32         `config:"DB_MAX_CONNS" default:"10"`
+	DBMinConns        int32         `config:"DB_MIN_CONNS" default:"0"`
+	DBMaxConnLifetime time.Duration `config:"DB_MAX_CONN_LIFETIME" default:"1h"`
+	DBMaxConnIdleTime time.Duration `config:"DB_MAX_CONN_IDLE_TIME" default:"30m"`
+
+	TokenSymmetricKey          string        `config:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration        time.Duration `config:"ACCESS_TOKEN_DURATION" default:"15m"`
+	RefreshTokenDuration       time.Duration `config:"REFRESH_TOKEN_DURATION" default:"168h"`
+	PasswordResetTokenDuration time.Duration `config:"PASSWORD_RESET_TOKEN_DURATION" default:"1h"`
+	EmailVerificationDuration  time.Duration `config:"EMAIL_VERIFICATION_DURATION" default:"24h"`
+	TwoFactorChallengeDuration time.Duration `config:"TWO_FACTOR_CHALLENGE_DURATION" default:"5m"`
+
+	// Password hashing; PasswordAlgorithm is argon2id | bcrypt. Hashes made
+	// with other settings are upgraded when the user logs in.
+	PasswordAlgorithm string `config:"PASSWORD_ALGORITHM" default:"argon2id"`
+	Argon2Memory      uint32 `config:"ARGON2_MEMORY_KIB" default:"65536"`
+	Argon2Iterations  uint32 `config:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism uint8  `config:"ARGON2_PARALLELISM" default:"4"`
+	BcryptCost        int    `config:"BCRYPT_COST" default:"10"`
+
+	// Password policy for new passwords; BreachedPasswordsFile is optional
+	PasswordMinLength     int    `config:"PASSWORD_MIN_LENGTH" default:"8"`
+	PasswordMaxLength     int    `config:"PASSWORD_MAX_LENGTH" default:"64"`
+	BreachedPasswordsFile string `config:"BREACHED_PASSWORDS_FILE"`
+
+	// TOTPIssuer is the account issuer shown in authenticator apps
+	TOTPIssuer string `config:"TOTP_ISSUER" default:"SFP"`
+
+	// RequireVerifiedEmail blocks unverified users from creating projects and uploading datasets
+	RequireVerifiedEmail bool `config:"REQUIRE_VERIFIED_EMAIL" default:"false"`
+
+	// Login brute-force protection; LoginThrottleStore is memory | postgres
+	LoginThrottleStore   string        `config:"LOGIN_THROTTLE_STORE" default:"memory"`
+	LoginFreeFailures    int           `config:"LOGIN_FREE_FAILURES" default:"5"`
+	LoginMaxFailures     int           `config:"LOGIN_MAX_FAILURES" default:"10"`
+	LoginLockoutDuration time.Duration `config:"LOGIN_LOCKOUT_DURATION" default:"15m"`
+	LoginFailureWindow   time.Duration `config:"LOGIN_FAILURE_WINDOW" default:"1h"`
+
+	// OpenID Connect single sign-on; disabled when OIDCIssuerURL is empty.
+	// OIDCRedirectURL defaults to the callback under AppBaseURL.
+	OIDCIssuerURL    string   `config:"OIDC_ISSUER_URL"`
+	OIDCClientID     string   `config:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string   `config:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string   `config:"OIDC_REDIRECT_URL"`
+	OIDCScopes       []string `config:"OIDC_SCOPES" default:"email profile"`
+
+	// Storage of model and prediction files; only local is supported for now
+	StorageBackend string `config:"STORAGE_BACKEND" default:"local"`
+	StorageDir     string `config:"STORAGE_DIR" default:"storage"`
+
+	// Account erasure: requests wait ErasureGracePeriod so users can cancel,
+	// and the job looks for due requests every ErasureJobInterval. An instance
+	// running the job isn't ready while more than ErasureMaxBacklog are due.
+	ErasureJobEnabled  bool          `config:"ERASURE_JOB_ENABLED" default:"true"`
+	ErasureGracePeriod time.Duration `config:"ERASURE_GRACE_PERIOD" default:"72h"`
+	ErasureJobInterval time.Duration `config:"ERASURE_JOB_INTERVAL" default:"10m"`
+	ErasureMaxBacklog  int64         `config:"ERASURE_MAX_BACKLOG" default:"1000"`
+
+	// OpenTelemetry tracing; TracingExporter is none | otlp | stdout. Without
+	// TracingOTLPEndpoint the OTEL_EXPORTER_OTLP_* variables apply.
+	TracingExporter     string  `config:"TRACING_EXPORTER" default:"none"`
+	TracingOTLPEndpoint string  `config:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName  string  `config:"TRACING_SERVICE_NAME" default:"sfp-api"`
+	TracingSampleRatio  float64 `config:"TRACING_SAMPLE_RATIO" default:"1"`
+
+	// AppBaseURL is where the client app lives; links in emails point there
+	AppBaseURL string `config:"APP_BASE_URL" default:"http://localhost:8080"`
+
+	Mailer       string `config:"MAILER" default:"file"` // smtp | file
+	MailFrom     string `config:"MAIL_FROM" default:"no-reply@sfp.local"`
+	MailDir      string `config:"MAIL_DIR" default:"tmp/mail"`
+	SMTPHost     string `config:"SMTP_HOST"`
+	SMTPPort     int    `config:"SMTP_PORT" default:"587"`
+	SMTPUsername string `config:"SMTP_USERNAME"`
+	SMTPPassword string `config:"SMTP_PASSWORD"`
+}
+
+// Argon2idParams returns the argon2id cost parameters for the password hasher
+func (config Config) Argon2idParams() util.Argon2idParams {
+	params := util.DefaultArgon2idParams
+	params.Memory = config.Argon2Memory
+	params.Iterations = config.Argon2Iterations
+	params.Parallelism = config.Argon2Parallelism
+	return params
+}
+
+// PoolConfig returns the database pool settings
+func (config Config) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(config.DBSource)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.MaxConns = config.DBMaxConns
+	poolConfig.MinConns = config.DBMinConns
+	poolConfig.MaxConnLifetime = config.DBMaxConnLifetime
+	poolConfig.MaxConnIdleTime = config.DBMaxConnIdleTime
+	return poolConfig, nil
+}
+
+// Validate reports every invalid setting at once
+func (config Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(config.ListenAddress); err != nil {
+		invalid("invalid LISTEN_ADDRESS %q: %v", config.ListenAddress, err)
+	}
+	if len(config.CORSAllowedOrigins) == 0 {
+		invalid("CORS_ALLOWED_ORIGINS must list at least one origin, or *")
+	}
+	for _, origin := range config.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("invalid origin %q in CORS_ALLOWED_ORIGINS", origin)
+		}
+	}
+	for _, proxy := range config.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			invalid("invalid proxy %q in TRUSTED_PROXIES: must be an IP or a CIDR", proxy)
+		}
+	}
+
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", config.ServerReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", config.ServerWriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", config.ServerIdleTimeout},
+		{"REQUEST_TIMEOUT", config.RequestTimeout},
+		{"SLOW_REQUEST_TIMEOUT", config.SlowRequestTimeout},
+		{"SHUTDOWN_TIMEOUT", config.ShutdownTimeout},
+	} {
+		if d.value <= 0 {
+			invalid("%s must be positive", d.key)
+		}
+	}
+	// پاسخ باید پیش از بسته شدن اتصال نوشته شود
+	if config.ServerWriteTimeout <= max(config.RequestTimeout, config.SlowRequestTimeout) {
+		invalid("SERVER_WRITE_TIMEOUT must be longer than REQUEST_TIMEOUT and SLOW_REQUEST_TIMEOUT")
+	}
+
+	if _, err := logging.New(io.Discard, config.LogFormat, config.LogLevel); err != nil {
+		invalid("invalid logging settings: %v", err)
+	}
+
+	if config.DBSource == "" {
+		invalid("DB_SOURCE is required")
+	} else if _, err := pgxpool.ParseConfig(config.DBSource); err != nil {
+		// خطای pgx ممکن است رمز عبور را نشان دهد
+		invalid("invalid DB_SOURCE: cannot parse the connection string")
+	}
+	if config.DBMaxConns < 1 {
+		invalid("DB_MAX_CONNS must be at least 1")
+	}
+	if config.DBMinConns < 0 || config.DBMinConns > config.DBMaxConns {
+		invalid("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS")
+	}
+
+	if len(config.TokenSymmetricKey) < minTokenKeySize {
+		invalid("TOKEN_SYMMETRIC_KEY must be at least %d characters", minTokenKeySize)
+	}
+	for _, d := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"ACCESS_TOKEN_DURATION", config.AccessTokenDuration},
+		{"REFRESH_TOKEN_DURATION", config.RefreshTokenDuration},
+		{"PASSWORD_RESET_TOKEN_DURATION", config.PasswordResetTokenDuration},
+		{"EMAIL_VERIFICATION_DURATION", config.EmailVerificationDuration},
+		{"TWO_FACTOR_CHALLENGE_DURATION", config.TwoFactorChallengeDuration},
+	} {
+		if d.value <= 0 {
+			invalid("%s must be positive", d.key)
+		}
+	}
+
+	if _, err := util.NewPasswordHasher(config.PasswordAlgorithm, config.Argon2idParams(), config.BcryptCost); err != nil {
+		invalid("invalid password hashing settings: %v", err)
+	}
+	if config.PasswordMinLength < 1 {
+		invalid("PASSWORD_MIN_LENGTH must be at least 1")
+	}
+	if config.PasswordMaxLength != 0 && config.PasswordMaxLength < config.PasswordMinLength {
+		invalid("PASSWORD_MAX_LENGTH must not be less than PASSWORD_MIN_LENGTH")
+	}
+
+	if config.LoginThrottleStore != "memory" && config.LoginThrottleStore != "postgres" {
+		invalid("invalid LOGIN_THROTTLE_STORE %q: must be memory or postgres", config.LoginThrottleStore)
+	}
+
+	if config.OIDCIssuerURL != "" && config.OIDCClientID == "" {
+		invalid("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+	}
+
+	if config.StorageBackend != "local" {
+		invalid("invalid STORAGE_BACKEND %q: must be local", config.StorageBackend)
+	}
+
+	if config.ErasureJobEnabled && config.ErasureJobInterval <= 0 {
+		invalid("ERASURE_JOB_INTERVAL must be positive")
+	}
+	if config.ErasureMaxBacklog < 1 {
+		invalid("ERASURE_MAX_BACKLOG must be at least 1")
+	}
+	if config.ErasureGracePeriod < 0 {
+		invalid("ERASURE_GRACE_PERIOD must not be negative")
+	}
+
+	switch config.TracingExporter {
+	case "none", "otlp", "stdout":
+	default:
+		invalid("invalid TRACING_EXPORTER %q: must be none, otlp or stdout", config.TracingExporter)
+	}
+	if config.TracingSampleRatio < 0 || config.TracingSampleRatio > 1 {
+		invalid("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	switch config.Mailer {
+	case "file":
+	case "smtp":
+		if config.SMTPHost == "" {
+			invalid("SMTP_HOST is required when MAILER=smtp")
+		}
+	default:
+		invalid("invalid MAILER %q: must be smtp or file", config.Mailer)
+	}
+
+	return errors.Join(errs...)
+}
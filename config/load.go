@@ -0,0 +1,276 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// secretFileSuffix marks a setting whose value is read from a file, e.g.
+// DB_SOURCE_FILE=/run/secrets/db_source
+const secretFileSuffix = "_FILE"
+
+// Load reads the configuration and validates it. Settings come from, in
+// increasing priority: the defaults, an optional YAML or TOML file given by
+// -config or CONFIG_FILE, environment variables and command-line flags.
+// Flags are named after the setting, e.g. DB_SOURCE is -db-source.
+// Any setting can instead be read from a file named by <SETTING>_FILE.
+func Load(args []string) (config Config, err error) {
+	fields := settingFields()
+
+	flags := flag.NewFlagSet("sfp", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path of a YAML or TOML config file")
+	for _, field := range fields {
+		flags.String(flagName(field.key), "", "overrides "+field.key)
+		flags.String(flagName(field.key+secretFileSuffix), "", "reads "+field.key+" from a file")
+	}
+	if err := flags.Parse(args); err != nil {
+		return config, err
+	}
+
+	values := settings{}
+	if *configFile != "" {
+		fileValues, err := readConfigFile(*configFile)
+		if err != nil {
+			return config, err
+		}
+		values.merge(fileValues)
+	}
+
+	envValues := map[string]string{}
+	for _, field := range fields {
+		for _, key := range []string{field.key, field.key + secretFileSuffix} {
+			if value, ok := os.LookupEnv(key); ok {
+				envValues[key] = value
+			}
+		}
+	}
+	values.merge(envValues)
+
+	flagValues := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			flagValues[settingKey(f.Name)] = f.Value.String()
+		}
+	})
+	values.merge(flagValues)
+
+	if err := values.readSecretFiles(); err != nil {
+		return config, err
+	}
+
+	target := reflect.ValueOf(&config).Elem()
+	for _, field := range fields {
+		value := values[field.key]
+		if value == "" {
+			value = field.fallback
+		}
+		if err := setField(target.Field(field.index), value); err != nil {
+			return config, fmt.Errorf("invalid %s %q: %w", field.key, value, err)
+		}
+	}
+
+	if config.OIDCRedirectURL == "" {
+		config.OIDCRedirectURL = config.AppBaseURL + "/api/v1/auth/oidc/callback"
+	}
+
+	return config, config.Validate()
+}
+
+// settingField is a Config field together with its setting name and default
+type settingField struct {
+	index    int
+	key      string
+	fallback string
+}
+
+// settingKeys are the names of all settings
+var settingKeys = func() map[string]bool {
+	keys := map[string]bool{}
+	for _, field := range settingFields() {
+		keys[field.key] = true
+	}
+	return keys
+}()
+
+// secretFileKey returns the setting whose value the file named by key holds.
+// BREACHED_PASSWORDS_FILE is a setting itself, not a secret file.
+func secretFileKey(key string) (string, bool) {
+	plain, ok := strings.CutSuffix(key, secretFileSuffix)
+	if !ok || settingKeys[key] || !settingKeys[plain] {
+		return "", false
+	}
+	return plain, true
+}
+
+func settingFields() []settingField {
+	t := reflect.TypeOf(Config{})
+	fields := make([]settingField, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if key := field.Tag.Get("config"); key != "" {
+			fields = append(fields, settingField{index: i, key: key, fallback: field.Tag.Get("default")})
+		}
+	}
+	return fields
+}
+
+// flagName turns DB_SOURCE into db-source
+func flagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "-")
+}
+
+// settingKey turns db-source or db_source into DB_SOURCE
+func settingKey(name string) string {
+	return strings.ReplaceAll(strings.ToUpper(name), "-", "_")
+}
+
+// settings holds the raw value of every setting that was set somewhere
+type settings map[string]string
+
+// merge overrides settings with a source of higher priority. Setting a value
+// or its _FILE variant replaces the other one from the earlier sources.
+func (values settings) merge(source map[string]string) {
+	for key := range source {
+		if plain, ok := secretFileKey(key); ok {
+			if _, both := source[plain]; !both {
+				delete(values, plain)
+			}
+		} else {
+			delete(values, key+secretFileSuffix)
+		}
+	}
+	for key, value := range source {
+		values[key] = value
+	}
+}
+
+// readSecretFiles replaces every <SETTING>_FILE by the content of the file
+func (values settings) readSecretFiles() error {
+	for key, path := range values {
+		plain, ok := secretFileKey(key)
+		if !ok {
+			continue
+		}
+		if _, both := values[plain]; both {
+			return fmt.Errorf("only one of %s and %s can be set", plain, key)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %w", key, err)
+		}
+		values[plain] = strings.TrimRight(string(content), "\r\n")
+		delete(values, key)
+	}
+	return nil
+}
+
+// readConfigFile reads a flat YAML or TOML file whose keys are setting names
+// in any case, e.g. db_source: postgres://...
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("unsupported config file %q: must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		key = settingKey(key)
+		if _, secret := secretFileKey(key); !secret && !settingKeys[key] {
+			return nil, fmt.Errorf("unknown setting %q in config file", key)
+		}
+
+		switch value := value.(type) {
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case map[string]any:
+			return nil, fmt.Errorf("config file setting %q must not be a table", key)
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setField parses a raw setting into a Config field
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		if value == "" {
+			field.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint8, reflect.Uint32:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		// لیست‌ها با کاما یا فاصله جدا می‌شوند
+		items := strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+-- Sessions (refresh token های هش شده برای هر دستگاه)
+CREATE TABLE IF NOT EXISTS "sessions" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "refresh_token_hash" varchar UNIQUE NOT NULL,
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "is_revoked" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamp NOT NULL,
+  "last_used_at" timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+  "created_at" timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "sessions" ("user_id");
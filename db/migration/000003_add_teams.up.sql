@@ -0,0 +1,28 @@
+-- Teams
+CREATE TABLE IF NOT EXISTS "teams" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "owner_user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+-- Team members (accepted_at خالی یعنی دعوت هنوز پذیرفته نشده)
+CREATE TABLE IF NOT EXISTS "team_members" (
+  "team_id" INT NOT NULL REFERENCES "teams"("id") ON DELETE CASCADE,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "role" varchar NOT NULL DEFAULT 'member', -- owner | member
+  "invited_by" INT REFERENCES "users"("id") ON DELETE SET NULL,
+  "accepted_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP),
+  PRIMARY KEY ("team_id","user_id")
+);
+
+CREATE INDEX ON "team_members" ("user_id");
+
+-- پروژه‌های با visibility = team به یک تیم تعلق دارند
+ALTER TABLE "projects" ADD COLUMN "team_id" INT REFERENCES "teams"("id") ON DELETE SET NULL;
+
+UPDATE "projects" SET "visibility" = 'private' WHERE "visibility" IS NULL;
+
+ALTER TABLE "projects" ADD CONSTRAINT "projects_visibility_check"
+  CHECK ("visibility" IN ('private', 'team', 'public'));
@@ -0,0 +1,12 @@
+-- Project members (همکاران پروژه؛ مالک پروژه در این جدول نیست)
+CREATE TABLE IF NOT EXISTS "project_members" (
+  "project_id" INT NOT NULL REFERENCES "projects"("id") ON DELETE CASCADE,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "role" varchar NOT NULL DEFAULT 'viewer', -- viewer | editor | maintainer
+  "invited_by" INT REFERENCES "users"("id") ON DELETE SET NULL,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP),
+  PRIMARY KEY ("project_id","user_id"),
+  CONSTRAINT "project_members_role_check" CHECK ("role" IN ('viewer', 'editor', 'maintainer'))
+);
+
+CREATE INDEX ON "project_members" ("user_id");
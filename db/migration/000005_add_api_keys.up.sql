@@ -0,0 +1,16 @@
+-- API keys (فقط هش کلید ذخیره می‌شود)
+CREATE TABLE IF NOT EXISTS "api_keys" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "prefix" varchar NOT NULL, -- چند حرف اول کلید برای نمایش
+  "secret_hash" varchar UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL, -- read | write | predict
+  "project_id" INT REFERENCES "projects"("id") ON DELETE CASCADE,
+  "expires_at" timestamp,
+  "last_used_at" timestamp,
+  "revoked_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "api_keys" ("user_id");
@@ -0,0 +1,11 @@
+-- Password reset tokens (یک‌بار مصرف و دارای تاریخ انقضا)
+CREATE TABLE IF NOT EXISTS "password_reset_tokens" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  "used_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "password_reset_tokens" ("user_id");
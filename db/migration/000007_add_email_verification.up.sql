@@ -0,0 +1,14 @@
+-- زمان تأیید ایمیل؛ خالی یعنی ایمیل هنوز تأیید نشده
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamp;
+
+-- Email verification tokens (یک‌بار مصرف و دارای تاریخ انقضا)
+CREATE TABLE IF NOT EXISTS "email_verification_tokens" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  "used_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "email_verification_tokens" ("user_id");
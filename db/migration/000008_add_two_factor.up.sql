@@ -0,0 +1,18 @@
+-- TOTP two-factor authentication (enabled_at خالی یعنی ثبت‌نام هنوز تأیید نشده)
+CREATE TABLE IF NOT EXISTS "user_totp" (
+  "user_id" INT PRIMARY KEY REFERENCES "users"("id") ON DELETE CASCADE,
+  "secret" varchar NOT NULL,
+  "enabled_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+-- One-time recovery codes (فقط هش کدها ذخیره می‌شود)
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
@@ -0,0 +1,6 @@
+-- شمارش تلاش‌های ناموفق ورود به ازای هر IP یا ایمیل (برای چند instance)
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+  "attempt_key" varchar PRIMARY KEY, -- ip:<address> | email:<address>
+  "failures" INT NOT NULL,
+  "last_failure_at" timestamp NOT NULL
+);
@@ -0,0 +1,23 @@
+-- هویت‌های خارجی (OIDC) متصل به هر کاربر
+CREATE TABLE IF NOT EXISTS "user_identities" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT NOT NULL REFERENCES "users"("id") ON DELETE CASCADE,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "last_login_at" timestamp DEFAULT (CURRENT_TIMESTAMP),
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP),
+  UNIQUE ("issuer", "subject")
+);
+
+CREATE INDEX ON "user_identities" ("user_id");
+
+-- وضعیت ورودهای OIDC در جریان (state، nonce و PKCE verifier)
+CREATE TABLE IF NOT EXISTS "oidc_login_states" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "state_hash" varchar UNIQUE NOT NULL,
+  "nonce" varchar NOT NULL,
+  "code_verifier" varchar NOT NULL,
+  "expires_at" timestamp NOT NULL,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
@@ -0,0 +1,10 @@
+-- نقش کاربر (user | admin) و وضعیت حساب
+-- اولین مدیر را می‌توان با UPDATE users SET role = 'admin' WHERE email = '...' تعیین کرد
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'user';
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin'));
+
+-- disabled_at پر یعنی حساب توسط مدیر غیرفعال شده است
+ALTER TABLE "users" ADD COLUMN "disabled_at" timestamp;
+
+-- مدیر می‌تواند کاربر را مجبور به تعیین رمز عبور جدید کند
+ALTER TABLE "users" ADD COLUMN "password_reset_required" boolean NOT NULL DEFAULT false;
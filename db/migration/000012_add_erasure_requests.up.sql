@@ -0,0 +1,14 @@
+-- درخواست‌های حذف اطلاعات شخصی (حق فراموش شدن)
+-- پس از حذف کاربر، user_id خالی می‌شود ولی سابقه درخواست باقی می‌ماند
+CREATE TABLE IF NOT EXISTS "erasure_requests" (
+  "id" INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" INT REFERENCES "users"("id") ON DELETE SET NULL,
+  "execute_after" timestamp NOT NULL,
+  "completed_at" timestamp,
+  "cancelled_at" timestamp,
+  "created_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+-- هر کاربر حداکثر یک درخواست در انتظار دارد
+CREATE UNIQUE INDEX ON "erasure_requests" ("user_id")
+  WHERE "completed_at" IS NULL AND "cancelled_at" IS NULL;
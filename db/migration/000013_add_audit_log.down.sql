@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "logs_user_id_created_at_idx";
+
+ALTER TABLE "logs" DROP COLUMN IF EXISTS "request_id";
+ALTER TABLE "logs" DROP COLUMN IF EXISTS "ip_address";
+
+UPDATE "logs" SET "project_id" = NULL
+WHERE "project_id" NOT IN (SELECT "id" FROM "projects");
+ALTER TABLE "logs" ADD CONSTRAINT "logs_project_id_fkey"
+  FOREIGN KEY ("project_id") REFERENCES "projects"("id") ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+-- لاگ‌ها سابقه حسابرسی هستند و باید پس از حذف پروژه هم به آن اشاره کنند
+ALTER TABLE "logs" DROP CONSTRAINT IF EXISTS "logs_project_id_fkey";
+
+ALTER TABLE "logs" ADD COLUMN "ip_address" varchar;
+ALTER TABLE "logs" ADD COLUMN "request_id" varchar;
+
+CREATE INDEX ON "logs" ("user_id","created_at");
@@ -0,0 +1,2 @@
+-- شناسه trace برای پیدا کردن درخواست مربوط به هر لاگ در سیستم tracing
+ALTER TABLE "logs" ADD COLUMN "trace_id" varchar;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS used_challenge_tokens;
+ALTER TABLE "user_totp" DROP COLUMN IF EXISTS "last_used_step";
@@ -0,0 +1,11 @@
+-- آخرین گام زمانی TOTP پذیرفته شده؛ کدهای همان گام یا گام‌های قبلی دوباره پذیرفته نمی‌شوند
+ALTER TABLE "user_totp" ADD COLUMN "last_used_step" bigint NOT NULL DEFAULT 0;
+
+-- توکن‌های challenge ورود دو مرحله‌ای که مصرف شده‌اند، تا زمان انقضایشان
+CREATE TABLE IF NOT EXISTS "used_challenge_tokens" (
+  "token_id" uuid PRIMARY KEY,
+  "expires_at" timestamp NOT NULL,
+  "used_at" timestamp DEFAULT (CURRENT_TIMESTAMP)
+);
+
+CREATE INDEX ON "used_challenge_tokens" ("expires_at");
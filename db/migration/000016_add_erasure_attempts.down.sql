@@ -0,0 +1,3 @@
+ALTER TABLE "erasure_requests" DROP COLUMN IF EXISTS "last_attempt_at";
+ALTER TABLE "erasure_requests" DROP COLUMN IF EXISTS "last_error";
+ALTER TABLE "erasure_requests" DROP COLUMN IF EXISTS "attempts";
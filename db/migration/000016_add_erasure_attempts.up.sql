@@ -0,0 +1,4 @@
+-- تلاش‌های ناموفق حذف حساب؛ درخواست‌های ناموفق پس از بقیه دوباره امتحان می‌شوند
+ALTER TABLE "erasure_requests" ADD COLUMN "attempts" INT NOT NULL DEFAULT 0;
+ALTER TABLE "erasure_requests" ADD COLUMN "last_error" varchar;
+ALTER TABLE "erasure_requests" ADD COLUMN "last_attempt_at" timestamp;
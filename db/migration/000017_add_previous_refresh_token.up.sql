@@ -0,0 +1,4 @@
+-- توکن refresh قبلی سشن؛ ارائه دوباره آن یعنی توکن کپی شده و کل سشن باطل می‌شود
+ALTER TABLE "sessions" ADD COLUMN "previous_refresh_token_hash" varchar;
+
+CREATE INDEX ON "sessions" ("previous_refresh_token_hash");
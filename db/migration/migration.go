@@ -0,0 +1,37 @@
+// Package migration embeds the SQL migrations run by golang-migrate
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the version of the newest migration, which the
+// database must be at for this build to run against it
+func LatestVersion() (uint64, error) {
+	files, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint64
+	for _, file := range files {
+		// e.g. 000013_add_audit_log.up.sql
+		prefix, _, ok := strings.Cut(file, "_")
+		if !ok {
+			return 0, fmt.Errorf("invalid migration file name %q", file)
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q: %w", file, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	ups, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	// هر مهاجرت باید قابل برگشت باشد
+	for _, up := range ups {
+		_, err := fs.Stat(FS, strings.TrimSuffix(up, ".up.sql")+".down.sql")
+		require.NoError(t, err, up)
+	}
+
+	latest, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(ups)), latest)
+}
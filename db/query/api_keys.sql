@@ -0,0 +1,42 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, project_id, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetApiKeyBySecretHash :one
+SELECT * FROM api_keys WHERE secret_hash = $1 LIMIT 1;
+
+-- name: GetApiKeysByUserID :many
+SELECT * FROM api_keys
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY id;
+
+-- name: ListApiKeysByUserID :many
+SELECT * FROM api_keys
+WHERE user_id = sqlc.arg('user_id') AND revoked_at IS NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR id > sqlc.narg('after_id'))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountApiKeysByUserID :one
+SELECT COUNT(*) FROM api_keys
+WHERE user_id = sqlc.arg('user_id') AND revoked_at IS NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserApiKeys :execrows
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND revoked_at IS NULL;
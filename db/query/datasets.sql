@@ -9,6 +9,42 @@ SELECT * FROM datasets WHERE id = $1 LIMIT 1;
 -- name: GetDatasetsByUserID :many
 SELECT * FROM datasets WHERE user_id = $1 ORDER BY id;
 
+-- name: GetDatasetSummariesByUserID :many
+SELECT id, user_id, name, description, octet_length(content) AS size_bytes, uploaded_at
+FROM datasets WHERE user_id = $1 ORDER BY id;
+
+-- name: ListDatasetsByUserID :many
+SELECT id, user_id, name, description, octet_length(content) AS size_bytes, uploaded_at
+FROM datasets
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR uploaded_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR uploaded_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR CASE sqlc.arg('sort')::text
+    WHEN 'id' THEN id > sqlc.narg('after_id')
+    WHEN '-id' THEN id < sqlc.narg('after_id')
+    WHEN 'name' THEN (name, id) > (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN '-name' THEN (name, id) < (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN 'uploaded_at' THEN (COALESCE(uploaded_at, '-infinity'), id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+    WHEN '-uploaded_at' THEN (COALESCE(uploaded_at, '-infinity'), id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('sort') = 'name' THEN name END,
+  CASE WHEN sqlc.arg('sort') = '-name' THEN name END DESC,
+  CASE WHEN sqlc.arg('sort') = 'uploaded_at' THEN COALESCE(uploaded_at, '-infinity') END,
+  CASE WHEN sqlc.arg('sort') = '-uploaded_at' THEN COALESCE(uploaded_at, '-infinity') END DESC,
+  CASE WHEN sqlc.arg('sort') LIKE '-%' THEN id END DESC,
+  id
+LIMIT sqlc.arg('limit');
+
+-- name: CountDatasetsByUserID :one
+SELECT COUNT(*)
+FROM datasets
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR uploaded_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR uploaded_at < sqlc.narg('created_before'));
+
 -- name: UpdateDataset :one
 UPDATE datasets
 SET name = $2,
@@ -19,3 +55,6 @@ RETURNING *;
 
 -- name: DeleteDataset :exec
 DELETE FROM datasets WHERE id = $1;
+
+-- name: DeleteDatasetsByUserID :exec
+DELETE FROM datasets WHERE user_id = $1;
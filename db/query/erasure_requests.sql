@@ -0,0 +1,36 @@
+-- name: CreateErasureRequest :one
+INSERT INTO erasure_requests (user_id, execute_after)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetPendingErasureRequest :one
+SELECT * FROM erasure_requests
+WHERE user_id = $1 AND completed_at IS NULL AND cancelled_at IS NULL
+LIMIT 1;
+
+-- name: CancelErasureRequest :execrows
+UPDATE erasure_requests
+SET cancelled_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND completed_at IS NULL AND cancelled_at IS NULL;
+
+-- name: ListDueErasureRequests :many
+SELECT * FROM erasure_requests
+WHERE completed_at IS NULL AND cancelled_at IS NULL AND execute_after <= $1
+ORDER BY attempts, execute_after
+LIMIT $2;
+
+-- name: CompleteErasureRequest :exec
+UPDATE erasure_requests
+SET completed_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: RecordErasureFailure :exec
+UPDATE erasure_requests
+SET attempts = attempts + 1,
+    last_error = $2,
+    last_attempt_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CountDueErasureRequests :one
+SELECT COUNT(*) FROM erasure_requests
+WHERE completed_at IS NULL AND cancelled_at IS NULL AND execute_after <= $1;
@@ -0,0 +1,25 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts WHERE attempt_key = $1 LIMIT 1;
+
+-- name: AddLoginFailure :one
+INSERT INTO login_attempts (attempt_key, failures, last_failure_at)
+VALUES (sqlc.arg(attempt_key), 1, sqlc.arg(failed_at))
+ON CONFLICT (attempt_key) DO UPDATE
+SET failures = CASE
+      WHEN login_attempts.last_failure_at < sqlc.arg(window_start) THEN 1
+      ELSE login_attempts.failures + 1
+    END,
+    last_failure_at = sqlc.arg(failed_at)
+RETURNING *;
+
+-- name: RemoveLoginFailure :exec
+UPDATE login_attempts
+SET failures = failures - 1,
+    last_failure_at = CASE
+      WHEN last_failure_at = sqlc.arg(failed_at) THEN sqlc.arg(previous_failure_at)
+      ELSE last_failure_at
+    END
+WHERE attempt_key = sqlc.arg(attempt_key) AND failures > 0;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts WHERE attempt_key = $1;
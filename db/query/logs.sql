@@ -1,6 +1,6 @@
 -- name: CreateLog :one
-INSERT INTO logs (user_id, project_id, action, details)
-VALUES ($1, $2, $3, $4)
+INSERT INTO logs (user_id, project_id, action, details, ip_address, request_id, trace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetLogByID :one
@@ -13,3 +13,8 @@ ORDER BY created_at DESC;
 
 -- name: DeleteLog :exec
 DELETE FROM logs WHERE id = $1;
+
+-- name: AnonymizeUserLogs :exec
+UPDATE logs
+SET user_id = NULL
+WHERE user_id = $1;
@@ -9,6 +9,38 @@ SELECT * FROM models WHERE id = $1 LIMIT 1;
 -- name: GetModelsByUserID :many
 SELECT * FROM models WHERE user_id = $1 ORDER BY id;
 
+-- name: ListModelsByUserID :many
+SELECT id, user_id, name, description, model_type, created_at
+FROM models
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR CASE sqlc.arg('sort')::text
+    WHEN 'id' THEN id > sqlc.narg('after_id')
+    WHEN '-id' THEN id < sqlc.narg('after_id')
+    WHEN 'name' THEN (name, id) > (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN '-name' THEN (name, id) < (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN 'created_at' THEN (COALESCE(created_at, '-infinity'), id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+    WHEN '-created_at' THEN (COALESCE(created_at, '-infinity'), id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('sort') = 'name' THEN name END,
+  CASE WHEN sqlc.arg('sort') = '-name' THEN name END DESC,
+  CASE WHEN sqlc.arg('sort') = 'created_at' THEN COALESCE(created_at, '-infinity') END,
+  CASE WHEN sqlc.arg('sort') = '-created_at' THEN COALESCE(created_at, '-infinity') END DESC,
+  CASE WHEN sqlc.arg('sort') LIKE '-%' THEN id END DESC,
+  id
+LIMIT sqlc.arg('limit');
+
+-- name: CountModelsByUserID :one
+SELECT COUNT(*)
+FROM models
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('name')::text IS NULL OR name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
 -- name: UpdateModel :one
 UPDATE models
 SET name = $2,
@@ -19,3 +51,6 @@ RETURNING *;
 
 -- name: DeleteModel :exec
 DELETE FROM models WHERE id = $1;
+
+-- name: DeleteModelsByUserID :exec
+DELETE FROM models WHERE user_id = $1;
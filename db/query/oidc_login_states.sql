@@ -0,0 +1,12 @@
+-- name: CreateOidcLoginState :one
+INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ConsumeOidcLoginState :one
+DELETE FROM oidc_login_states
+WHERE state_hash = $1
+RETURNING *;
+
+-- name: DeleteExpiredOidcLoginStates :exec
+DELETE FROM oidc_login_states WHERE expires_at < $1;
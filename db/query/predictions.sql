@@ -9,6 +9,51 @@ SELECT * FROM predictions WHERE id = $1 LIMIT 1;
 -- name: GetPredictionsByUserID :many
 SELECT * FROM predictions WHERE user_id = $1 ORDER BY id;
 
+-- name: ListPredictionsByUserID :many
+SELECT id, user_id, dataset_id, model_id, project_id, status, created_at
+FROM predictions
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR CASE sqlc.arg('sort')::text
+    WHEN 'id' THEN id > sqlc.narg('after_id')
+    WHEN '-id' THEN id < sqlc.narg('after_id')
+    WHEN 'created_at' THEN (COALESCE(created_at, '-infinity'), id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+    WHEN '-created_at' THEN (COALESCE(created_at, '-infinity'), id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('sort') = 'created_at' THEN COALESCE(created_at, '-infinity') END,
+  CASE WHEN sqlc.arg('sort') = '-created_at' THEN COALESCE(created_at, '-infinity') END DESC,
+  CASE WHEN sqlc.arg('sort') LIKE '-%' THEN id END DESC,
+  id
+LIMIT sqlc.arg('limit');
+
+-- name: CountPredictionsByUserID :one
+SELECT COUNT(*)
+FROM predictions
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
+-- name: ListPredictionsByProjectID :many
+SELECT * FROM predictions
+WHERE project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR id > sqlc.narg('after_id'))
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountPredictionsByProjectID :one
+SELECT COUNT(*) FROM predictions
+WHERE project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
 -- name: UpdatePrediction :one
 UPDATE predictions
 SET result_file_path = $2
@@ -17,3 +62,16 @@ RETURNING *;
 
 -- name: DeletePrediction :exec
 DELETE FROM predictions WHERE id = $1;
+
+-- name: DeletePredictionsByUserID :exec
+DELETE FROM predictions WHERE user_id = $1;
+
+-- name: DetachUserDatasetsFromPredictions :exec
+UPDATE predictions
+SET dataset_id = NULL
+WHERE dataset_id IN (SELECT id FROM datasets WHERE datasets.user_id = $1);
+
+-- name: DetachUserModelsFromPredictions :exec
+UPDATE predictions
+SET model_id = NULL
+WHERE model_id IN (SELECT id FROM models WHERE models.user_id = $1);
@@ -2,11 +2,30 @@
 INSERT INTO project_datasets (project_id, dataset_id)
 VALUES ($1, $2);
 
--- name: GetDatasetsByProjectID :many
-SELECT d.*
+-- name: ListDatasetsByProjectID :many
+SELECT d.id, d.user_id, d.name, d.description, octet_length(d.content) AS size_bytes, d.uploaded_at
 FROM datasets d
 JOIN project_datasets pd ON d.id = pd.dataset_id
-WHERE pd.project_id = $1;
+WHERE pd.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR d.uploaded_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR d.uploaded_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR d.id > sqlc.narg('after_id'))
+ORDER BY d.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountDatasetsByProjectID :one
+SELECT COUNT(*)
+FROM datasets d
+JOIN project_datasets pd ON d.id = pd.dataset_id
+WHERE pd.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR d.uploaded_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR d.uploaded_at < sqlc.narg('created_before'));
+
+-- name: IsDatasetInProject :one
+SELECT EXISTS (
+  SELECT 1 FROM project_datasets
+  WHERE project_id = $1 AND dataset_id = $2
+);
 
 -- name: RemoveDatasetFromProject :exec
 DELETE FROM project_datasets
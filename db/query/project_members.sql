@@ -0,0 +1,38 @@
+-- name: AddProjectMember :one
+INSERT INTO project_members (project_id, user_id, role, invited_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetProjectMember :one
+SELECT * FROM project_members
+WHERE project_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListProjectMembers :many
+SELECT pm.project_id, pm.user_id, pm.role, pm.created_at, u.email, u.full_name
+FROM project_members pm
+JOIN users u ON u.id = pm.user_id
+WHERE pm.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR pm.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR pm.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL
+    OR (COALESCE(pm.created_at, '-infinity'), pm.user_id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id')))
+ORDER BY COALESCE(pm.created_at, '-infinity'), pm.user_id
+LIMIT sqlc.arg('limit');
+
+-- name: CountProjectMembers :one
+SELECT COUNT(*)
+FROM project_members pm
+WHERE pm.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR pm.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR pm.created_at < sqlc.narg('created_before'));
+
+-- name: UpdateProjectMemberRole :one
+UPDATE project_members
+SET role = $3
+WHERE project_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RemoveProjectMember :execrows
+DELETE FROM project_members
+WHERE project_id = $1 AND user_id = $2;
@@ -2,11 +2,31 @@
 INSERT INTO project_models (project_id, model_id)
 VALUES ($1, $2);
 
--- name: GetModelsByProjectID :many
+-- name: ListModelsByProjectID :many
 SELECT m.*
 FROM models m
 JOIN project_models pm ON m.id = pm.model_id
-WHERE pm.project_id = $1;
+WHERE pm.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR m.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR m.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR m.id > sqlc.narg('after_id'))
+ORDER BY m.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountModelsByProjectID :one
+SELECT COUNT(*)
+FROM models m
+JOIN project_models pm ON m.id = pm.model_id
+WHERE pm.project_id = sqlc.arg('project_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR m.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR m.created_at < sqlc.narg('created_before'));
+
+-- name: GetProjectModel :one
+SELECT m.*
+FROM models m
+JOIN project_models pm ON m.id = pm.model_id
+WHERE pm.project_id = $1 AND m.id = $2
+LIMIT 1;
 
 -- name: RemoveModelFromProject :exec
 DELETE FROM project_models
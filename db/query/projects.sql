@@ -1,6 +1,6 @@
 -- name: CreateProject :one
-INSERT INTO projects (owner_user_id, name, description)
-VALUES ($1, $2, $3)
+INSERT INTO projects (owner_user_id, name, description, visibility, team_id)
+VALUES ($1, $2, $3, COALESCE(sqlc.narg('visibility'), 'private'), sqlc.narg('team_id'))
 RETURNING *;
 
 -- name: GetProjectByID :one
@@ -9,10 +9,120 @@ SELECT * FROM projects WHERE id = $1 LIMIT 1;
 -- name: GetProjectsByOwnerID :many
 SELECT * FROM projects WHERE owner_user_id = $1 ORDER BY id;
 
+-- name: ListVisibleProjectsByOwnerID :many
+SELECT p.*
+FROM projects p
+WHERE p.owner_user_id = sqlc.arg('owner_user_id')
+  AND (
+    p.owner_user_id = sqlc.arg('viewer_id')
+    OR p.visibility = 'public'
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = sqlc.arg('viewer_id')
+    )
+    OR (p.visibility = 'team' AND EXISTS (
+      SELECT 1 FROM team_members tm
+      WHERE tm.team_id = p.team_id
+        AND tm.user_id = sqlc.arg('viewer_id')
+        AND tm.accepted_at IS NOT NULL
+    ))
+  )
+  AND (sqlc.narg('name')::text IS NULL OR p.name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('visibility')::text IS NULL OR p.visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR p.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR p.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR CASE sqlc.arg('sort')::text
+    WHEN 'id' THEN p.id > sqlc.narg('after_id')
+    WHEN '-id' THEN p.id < sqlc.narg('after_id')
+    WHEN 'name' THEN (p.name, p.id) > (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN '-name' THEN (p.name, p.id) < (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN 'created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+    WHEN '-created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('sort') = 'name' THEN p.name END,
+  CASE WHEN sqlc.arg('sort') = '-name' THEN p.name END DESC,
+  CASE WHEN sqlc.arg('sort') = 'created_at' THEN COALESCE(p.created_at, '-infinity') END,
+  CASE WHEN sqlc.arg('sort') = '-created_at' THEN COALESCE(p.created_at, '-infinity') END DESC,
+  CASE WHEN sqlc.arg('sort') LIKE '-%' THEN p.id END DESC,
+  p.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountVisibleProjectsByOwnerID :one
+SELECT COUNT(*)
+FROM projects p
+WHERE p.owner_user_id = sqlc.arg('owner_user_id')
+  AND (
+    p.owner_user_id = sqlc.arg('viewer_id')
+    OR p.visibility = 'public'
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = sqlc.arg('viewer_id')
+    )
+    OR (p.visibility = 'team' AND EXISTS (
+      SELECT 1 FROM team_members tm
+      WHERE tm.team_id = p.team_id
+        AND tm.user_id = sqlc.arg('viewer_id')
+        AND tm.accepted_at IS NOT NULL
+    ))
+  )
+  AND (sqlc.narg('name')::text IS NULL OR p.name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('visibility')::text IS NULL OR p.visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR p.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR p.created_at < sqlc.narg('created_before'));
+
+-- name: ListProjectsForUser :many
+SELECT p.*
+FROM projects p
+WHERE (
+    p.owner_user_id = sqlc.arg('user_id')
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = sqlc.arg('user_id')
+    )
+  )
+  AND (sqlc.narg('name')::text IS NULL OR p.name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('visibility')::text IS NULL OR p.visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR p.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR p.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR CASE sqlc.arg('sort')::text
+    WHEN 'id' THEN p.id > sqlc.narg('after_id')
+    WHEN '-id' THEN p.id < sqlc.narg('after_id')
+    WHEN 'name' THEN (p.name, p.id) > (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN '-name' THEN (p.name, p.id) < (sqlc.narg('after_name')::text, sqlc.narg('after_id'))
+    WHEN 'created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+    WHEN '-created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id'))
+  END)
+ORDER BY
+  CASE WHEN sqlc.arg('sort') = 'name' THEN p.name END,
+  CASE WHEN sqlc.arg('sort') = '-name' THEN p.name END DESC,
+  CASE WHEN sqlc.arg('sort') = 'created_at' THEN COALESCE(p.created_at, '-infinity') END,
+  CASE WHEN sqlc.arg('sort') = '-created_at' THEN COALESCE(p.created_at, '-infinity') END DESC,
+  CASE WHEN sqlc.arg('sort') LIKE '-%' THEN p.id END DESC,
+  p.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountProjectsForUser :one
+SELECT COUNT(*)
+FROM projects p
+WHERE (
+    p.owner_user_id = sqlc.arg('user_id')
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = sqlc.arg('user_id')
+    )
+  )
+  AND (sqlc.narg('name')::text IS NULL OR p.name ILIKE '%' || replace(replace(replace(sqlc.narg('name'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('visibility')::text IS NULL OR p.visibility = sqlc.narg('visibility'))
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR p.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR p.created_at < sqlc.narg('created_before'));
+
 -- name: UpdateProject :one
 UPDATE projects
 SET name = $2,
-    description = $3
+    description = $3,
+    visibility = COALESCE(sqlc.narg('visibility'), visibility),
+    team_id = COALESCE(sqlc.narg('team_id'), team_id)
 WHERE id = $1
 RETURNING *;
 
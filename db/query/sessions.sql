@@ -0,0 +1,56 @@
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, refresh_token_hash, user_agent, client_ip, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSessionByID :one
+SELECT * FROM sessions WHERE id = $1 LIMIT 1;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT * FROM sessions WHERE refresh_token_hash = $1 LIMIT 1;
+
+-- name: GetSessionByPreviousRefreshTokenHash :one
+SELECT * FROM sessions WHERE previous_refresh_token_hash = $1 LIMIT 1;
+
+-- name: GetSessionsByUserID :many
+SELECT * FROM sessions
+WHERE user_id = $1 AND is_revoked = false
+ORDER BY last_used_at DESC;
+
+-- name: ListSessionsByUserID :many
+SELECT * FROM sessions
+WHERE user_id = sqlc.arg('user_id') AND is_revoked = false
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR id < sqlc.narg('after_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountSessionsByUserID :one
+SELECT COUNT(*) FROM sessions
+WHERE user_id = sqlc.arg('user_id') AND is_revoked = false
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
+-- name: RotateSessionRefreshToken :one
+UPDATE sessions
+SET refresh_token_hash = $2,
+    previous_refresh_token_hash = refresh_token_hash,
+    user_agent = $3,
+    client_ip = $4,
+    last_used_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND is_revoked = false
+  AND refresh_token_hash = sqlc.arg(old_refresh_token_hash)
+  AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET is_revoked = true
+WHERE id = $1 AND user_id = $2 AND is_revoked = false;
+
+-- name: RevokeUserSessions :execrows
+UPDATE sessions
+SET is_revoked = true
+WHERE user_id = $1 AND is_revoked = false;
@@ -0,0 +1,6 @@
+-- name: GetDomainCounts :one
+SELECT
+  (SELECT COUNT(*) FROM users) AS users,
+  (SELECT COUNT(*) FROM datasets) AS datasets,
+  (SELECT COUNT(*) FROM models) AS models,
+  (SELECT COUNT(*) FROM predictions) AS predictions;
@@ -0,0 +1,54 @@
+-- name: CreateTeamMember :one
+INSERT INTO team_members (team_id, user_id, role, invited_by, accepted_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTeamMember :one
+SELECT * FROM team_members
+WHERE team_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListTeamMembers :many
+SELECT tm.team_id, tm.user_id, tm.role, tm.accepted_at, tm.created_at, u.email, u.full_name
+FROM team_members tm
+JOIN users u ON u.id = tm.user_id
+WHERE tm.team_id = sqlc.arg('team_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR tm.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR tm.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL
+    OR (COALESCE(tm.created_at, '-infinity'), tm.user_id) > (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id')))
+ORDER BY COALESCE(tm.created_at, '-infinity'), tm.user_id
+LIMIT sqlc.arg('limit');
+
+-- name: CountTeamMembers :one
+SELECT COUNT(*)
+FROM team_members tm
+WHERE tm.team_id = sqlc.arg('team_id')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR tm.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR tm.created_at < sqlc.narg('created_before'));
+
+-- name: ListPendingTeamInvites :many
+SELECT * FROM team_members
+WHERE user_id = sqlc.arg('user_id') AND accepted_at IS NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL
+    OR (COALESCE(created_at, '-infinity'), team_id) < (COALESCE(sqlc.narg('after_time')::timestamp, '-infinity'), sqlc.narg('after_id')))
+ORDER BY COALESCE(created_at, '-infinity') DESC, team_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountPendingTeamInvites :one
+SELECT COUNT(*) FROM team_members
+WHERE user_id = sqlc.arg('user_id') AND accepted_at IS NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'));
+
+-- name: AcceptTeamInvite :one
+UPDATE team_members
+SET accepted_at = CURRENT_TIMESTAMP
+WHERE team_id = $1 AND user_id = $2 AND accepted_at IS NULL
+RETURNING *;
+
+-- name: DeleteTeamMember :execrows
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2;
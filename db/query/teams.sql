@@ -0,0 +1,35 @@
+-- name: CreateTeam :one
+INSERT INTO teams (owner_user_id, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetTeamByID :one
+SELECT * FROM teams WHERE id = $1 LIMIT 1;
+
+-- name: ListTeamsByUserID :many
+SELECT t.*
+FROM teams t
+JOIN team_members tm ON t.id = tm.team_id
+WHERE tm.user_id = sqlc.arg('user_id') AND tm.accepted_at IS NOT NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR t.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR t.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR t.id > sqlc.narg('after_id'))
+ORDER BY t.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountTeamsByUserID :one
+SELECT COUNT(*)
+FROM teams t
+JOIN team_members tm ON t.id = tm.team_id
+WHERE tm.user_id = sqlc.arg('user_id') AND tm.accepted_at IS NOT NULL
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR t.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR t.created_at < sqlc.narg('created_before'));
+
+-- name: UpdateTeam :one
+UPDATE teams
+SET name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTeam :exec
+DELETE FROM teams WHERE id = $1;
@@ -24,3 +24,70 @@ DELETE FROM users WHERE id = $1;
 SELECT * FROM users
 ORDER BY id
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DisableUser :one
+UPDATE users
+SET disabled_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: EnableUser :one
+UPDATE users
+SET disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserPasswordResetRequired :one
+UPDATE users
+SET password_reset_required = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: RehashUserPassword :execrows
+UPDATE users
+SET password_hash = sqlc.arg('new_hash')
+WHERE id = sqlc.arg('id') AND password_hash = sqlc.arg('old_hash');
+
+-- name: SearchUsersWithCounts :many
+SELECT u.id, u.email, u.full_name, u.role, u.email_verified_at, u.disabled_at,
+       u.password_reset_required, u.created_at,
+       (SELECT COUNT(*) FROM projects p WHERE p.owner_user_id = u.id) AS project_count,
+       (SELECT COUNT(*) FROM datasets d WHERE d.user_id = u.id) AS dataset_count,
+       (SELECT COUNT(*) FROM models m WHERE m.user_id = u.id) AS model_count
+FROM users u
+WHERE (sqlc.narg('search')::text IS NULL
+    OR u.email ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR u.full_name ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR u.created_at < sqlc.narg('created_before'))
+  AND (sqlc.narg('after_id')::int IS NULL OR u.id > sqlc.narg('after_id'))
+ORDER BY u.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users u
+WHERE (sqlc.narg('search')::text IS NULL
+    OR u.email ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR u.full_name ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR u.created_at >= sqlc.narg('created_after'))
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR u.created_at < sqlc.narg('created_before'));
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET full_name = sqlc.narg('full_name'),
+    email = sqlc.arg('email'),
+    email_verified_at = CASE WHEN sqlc.arg('email_changed')::boolean THEN NULL ELSE email_verified_at END
+WHERE id = sqlc.arg('id')
+RETURNING *;
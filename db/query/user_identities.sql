@@ -0,0 +1,20 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, issuer, subject, email)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE issuer = $1 AND subject = $2
+LIMIT 1;
+
+-- name: ListUserIdentitiesByUserID :many
+SELECT * FROM user_identities
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET email = $2,
+    last_login_at = CURRENT_TIMESTAMP
+WHERE id = $1;
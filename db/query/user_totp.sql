@@ -0,0 +1,33 @@
+-- name: UpsertUserTotp :one
+INSERT INTO user_totp (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+    enabled_at = NULL,
+    created_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetUserTotp :one
+SELECT * FROM user_totp WHERE user_id = $1 LIMIT 1;
+
+-- name: EnableUserTotp :one
+UPDATE user_totp
+SET enabled_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+RETURNING *;
+
+-- name: DeleteUserTotp :exec
+DELETE FROM user_totp WHERE user_id = $1;
+
+-- name: UseTotpStep :execrows
+UPDATE user_totp
+SET last_used_step = sqlc.arg(step)
+WHERE user_id = sqlc.arg(user_id) AND last_used_step < sqlc.arg(step);
+
+-- name: ConsumeChallengeToken :execrows
+WITH expired AS (
+  DELETE FROM used_challenge_tokens WHERE expires_at < sqlc.arg(now)
+)
+INSERT INTO used_challenge_tokens (token_id, expires_at)
+VALUES (sqlc.arg(token_id), sqlc.arg(expires_at))
+ON CONFLICT (token_id) DO NOTHING;
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomApiKey(t *testing.T, user User) ApiKey {
+	secret := "sfp_" + util.RandomString(32)
+	arg := CreateApiKeyParams{
+		UserID:     user.ID,
+		Name:       util.RandomString(8),
+		Prefix:     secret[:10],
+		SecretHash: util.HashSecret(secret),
+		Scopes:     []string{"read", "write"},
+		ExpiresAt:  pgtype.Timestamp{Time: time.Now().UTC().Add(24 * time.Hour), Valid: true},
+	}
+
+	key, err := testQueries.CreateApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, key.ID)
+	require.Equal(t, arg.UserID, key.UserID)
+	require.Equal(t, arg.Name, key.Name)
+	require.Equal(t, arg.SecretHash, key.SecretHash)
+	require.Equal(t, arg.Scopes, key.Scopes)
+	require.False(t, key.ProjectID.Valid)
+	require.False(t, key.LastUsedAt.Valid)
+
+	return key
+}
+
+func TestCreateApiKey(t *testing.T) {
+	createRandomApiKey(t, createRandomUser(t))
+}
+
+func TestGetApiKeyBySecretHash(t *testing.T) {
+	key1 := createRandomApiKey(t, createRandomUser(t))
+
+	key2, err := testQueries.GetApiKeyBySecretHash(context.Background(), key1.SecretHash)
+	require.NoError(t, err)
+	require.Equal(t, key1.ID, key2.ID)
+}
+
+func TestTouchApiKey(t *testing.T) {
+	key1 := createRandomApiKey(t, createRandomUser(t))
+
+	err := testQueries.TouchApiKey(context.Background(), key1.ID)
+	require.NoError(t, err)
+
+	key2, err := testQueries.GetApiKeyBySecretHash(context.Background(), key1.SecretHash)
+	require.NoError(t, err)
+	require.True(t, key2.LastUsedAt.Valid)
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	user := createRandomUser(t)
+	key := createRandomApiKey(t, user)
+
+	keys, err := testQueries.GetApiKeysByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	revoked, err := testQueries.RevokeApiKey(context.Background(), RevokeApiKeyParams{
+		ID:     key.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	keys, err = testQueries.GetApiKeysByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestRevokeUserApiKeys(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	for i := 0; i < 2; i++ {
+		createRandomApiKey(t, user)
+	}
+	createRandomApiKey(t, other)
+
+	revoked, err := testQueries.RevokeUserApiKeys(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), revoked)
+
+	keys, err := testQueries.GetApiKeysByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	// کلیدهای کاربران دیگر دست نمی‌خورند
+	keys, err = testQueries.GetApiKeysByUserID(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
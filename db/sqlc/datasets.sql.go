@@ -11,6 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countDatasetsByUserID = `-- name: CountDatasetsByUserID :one
+SELECT COUNT(*)
+FROM datasets
+WHERE user_id = $1
+  AND ($2::text IS NULL OR name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::timestamp IS NULL OR uploaded_at >= $3)
+  AND ($4::timestamp IS NULL OR uploaded_at < $4)
+`
+
+type CountDatasetsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountDatasetsByUserID(ctx context.Context, arg CountDatasetsByUserIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDatasetsByUserID,
+		arg.UserID,
+		arg.Name,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createDataset = `-- name: CreateDataset :one
 INSERT INTO datasets (user_id, name, description, content)
 VALUES ($1, $2, $3, $4)
@@ -52,6 +80,15 @@ func (q *Queries) DeleteDataset(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteDatasetsByUserID = `-- name: DeleteDatasetsByUserID :exec
+DELETE FROM datasets WHERE user_id = $1
+`
+
+func (q *Queries) DeleteDatasetsByUserID(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, deleteDatasetsByUserID, userID)
+	return err
+}
+
 const getDatasetByID = `-- name: GetDatasetByID :one
 SELECT id, user_id, name, description, content, uploaded_at FROM datasets WHERE id = $1 LIMIT 1
 `
@@ -70,6 +107,47 @@ func (q *Queries) GetDatasetByID(ctx context.Context, id int32) (Dataset, error)
 	return i, err
 }
 
+const getDatasetSummariesByUserID = `-- name: GetDatasetSummariesByUserID :many
+SELECT id, user_id, name, description, octet_length(content) AS size_bytes, uploaded_at
+FROM datasets WHERE user_id = $1 ORDER BY id
+`
+
+type GetDatasetSummariesByUserIDRow struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	SizeBytes   int32            `json:"size_bytes"`
+	UploadedAt  pgtype.Timestamp `json:"uploaded_at"`
+}
+
+func (q *Queries) GetDatasetSummariesByUserID(ctx context.Context, userID pgtype.Int4) ([]GetDatasetSummariesByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, getDatasetSummariesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDatasetSummariesByUserIDRow
+	for rows.Next() {
+		var i GetDatasetSummariesByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.SizeBytes,
+			&i.UploadedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDatasetsByUserID = `-- name: GetDatasetsByUserID :many
 SELECT id, user_id, name, description, content, uploaded_at FROM datasets WHERE user_id = $1 ORDER BY id
 `
@@ -101,6 +179,89 @@ func (q *Queries) GetDatasetsByUserID(ctx context.Context, userID pgtype.Int4) (
 	return items, nil
 }
 
+const listDatasetsByUserID = `-- name: ListDatasetsByUserID :many
+SELECT id, user_id, name, description, octet_length(content) AS size_bytes, uploaded_at
+FROM datasets
+WHERE user_id = $1
+  AND ($2::text IS NULL OR name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::timestamp IS NULL OR uploaded_at >= $3)
+  AND ($4::timestamp IS NULL OR uploaded_at < $4)
+  AND ($5::int IS NULL OR CASE $6::text
+    WHEN 'id' THEN id > $5
+    WHEN '-id' THEN id < $5
+    WHEN 'name' THEN (name, id) > ($7::text, $5)
+    WHEN '-name' THEN (name, id) < ($7::text, $5)
+    WHEN 'uploaded_at' THEN (COALESCE(uploaded_at, '-infinity'), id) > (COALESCE($8::timestamp, '-infinity'), $5)
+    WHEN '-uploaded_at' THEN (COALESCE(uploaded_at, '-infinity'), id) < (COALESCE($8::timestamp, '-infinity'), $5)
+  END)
+ORDER BY
+  CASE WHEN $6 = 'name' THEN name END,
+  CASE WHEN $6 = '-name' THEN name END DESC,
+  CASE WHEN $6 = 'uploaded_at' THEN COALESCE(uploaded_at, '-infinity') END,
+  CASE WHEN $6 = '-uploaded_at' THEN COALESCE(uploaded_at, '-infinity') END DESC,
+  CASE WHEN $6 LIKE '-%' THEN id END DESC,
+  id
+LIMIT $9
+`
+
+type ListDatasetsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Sort          string           `json:"sort"`
+	AfterName     pgtype.Text      `json:"after_name"`
+	AfterTime     pgtype.Timestamp `json:"after_time"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListDatasetsByUserIDRow struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	SizeBytes   int32            `json:"size_bytes"`
+	UploadedAt  pgtype.Timestamp `json:"uploaded_at"`
+}
+
+func (q *Queries) ListDatasetsByUserID(ctx context.Context, arg ListDatasetsByUserIDParams) ([]ListDatasetsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listDatasetsByUserID,
+		arg.UserID,
+		arg.Name,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDatasetsByUserIDRow
+	for rows.Next() {
+		var i ListDatasetsByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.SizeBytes,
+			&i.UploadedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateDataset = `-- name: UpdateDataset :one
 UPDATE datasets
 SET name = $2,
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/faezefz/SFP_website/util"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -86,6 +87,65 @@ func TestGetDatasetsByUserID(t *testing.T) {
 	require.True(t, found)
 }
 
+func TestListDatasetsByUserID(t *testing.T) {
+	user := createRandomUser(t)
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+	for _, name := range []string{"gamma", "alpha", "beta"} {
+		_, err := testQueries.CreateDataset(context.Background(), CreateDatasetParams{
+			UserID:  userID,
+			Name:    name,
+			Content: []byte("a,b\n1,2\n"),
+		})
+		require.NoError(t, err)
+	}
+
+	// صفحه اول به ترتیب نام و صفحه بعد از آخرین ردیف آن
+	page, err := testQueries.ListDatasetsByUserID(context.Background(), ListDatasetsByUserIDParams{
+		UserID: userID,
+		Sort:   "name",
+		Limit:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, "alpha", page[0].Name)
+	require.Equal(t, "beta", page[1].Name)
+	require.EqualValues(t, len("a,b\n1,2\n"), page[0].SizeBytes)
+
+	last := page[1]
+	page, err = testQueries.ListDatasetsByUserID(context.Background(), ListDatasetsByUserIDParams{
+		UserID:    userID,
+		AfterID:   pgtype.Int4{Int32: last.ID, Valid: true},
+		Sort:      "name",
+		AfterName: pgtype.Text{String: last.Name, Valid: true},
+		Limit:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "gamma", page[0].Name)
+
+	// فیلتر نام بدون حساسیت به حروف بزرگ و کوچک
+	page, err = testQueries.ListDatasetsByUserID(context.Background(), ListDatasetsByUserIDParams{
+		UserID: userID,
+		Name:   pgtype.Text{String: "MM", Valid: true},
+		Sort:   "-id",
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "gamma", page[0].Name)
+
+	total, err := testQueries.CountDatasetsByUserID(context.Background(), CountDatasetsByUserIDParams{UserID: userID})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+
+	total, err = testQueries.CountDatasetsByUserID(context.Background(), CountDatasetsByUserIDParams{
+		UserID:        userID,
+		CreatedBefore: pgtype.Timestamp{Time: page[0].UploadedAt.Time.Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
 func TestUpdateDataset(t *testing.T) {
 	dataset1 := createRandomDataset(t)
 	content, err := util.ReadCSV()
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailVerificationToken(t *testing.T, user User) EmailVerificationToken {
+	arg := CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(util.RandomString(32)),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC().Add(time.Hour), Valid: true},
+	}
+
+	token, err := testQueries.CreateEmailVerificationToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, token.ID)
+	require.Equal(t, arg.UserID, token.UserID)
+	require.Equal(t, arg.TokenHash, token.TokenHash)
+	require.False(t, token.UsedAt.Valid)
+
+	return token
+}
+
+func TestCreateUserIsUnverified(t *testing.T) {
+	user := createRandomUser(t)
+	require.False(t, user.EmailVerifiedAt.Valid)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	user := createRandomUser(t)
+	token1 := createRandomEmailVerificationToken(t, user)
+	token2 := createRandomEmailVerificationToken(t, user)
+
+	verified, err := testStore.VerifyEmailTx(context.Background(), token1.ID, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, verified.ID)
+	require.True(t, verified.EmailVerifiedAt.Valid)
+
+	// توکن‌های دیگر کاربر هم باطل شده‌اند
+	other, err := testQueries.GetEmailVerificationTokenByHash(context.Background(), token2.TokenHash)
+	require.NoError(t, err)
+	require.True(t, other.UsedAt.Valid)
+
+	_, err = testStore.VerifyEmailTx(context.Background(), token1.ID, user.ID)
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+}
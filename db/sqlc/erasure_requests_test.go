@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomErasureRequest(t *testing.T, userID int32, executeAfter time.Time) ErasureRequest {
+	request, err := testQueries.CreateErasureRequest(context.Background(), CreateErasureRequestParams{
+		UserID:       pgtype.Int4{Int32: userID, Valid: true},
+		ExecuteAfter: pgtype.Timestamp{Time: executeAfter, Valid: true},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, request.ID)
+	require.Equal(t, userID, request.UserID.Int32)
+	require.False(t, request.CompletedAt.Valid)
+	require.False(t, request.CancelledAt.Valid)
+
+	return request
+}
+
+func TestErasureRequestOnePending(t *testing.T) {
+	user := createRandomUser(t)
+	owner := pgtype.Int4{Int32: user.ID, Valid: true}
+	request := createRandomErasureRequest(t, user.ID, time.Now().UTC().Add(time.Hour))
+
+	pending, err := testQueries.GetPendingErasureRequest(context.Background(), owner)
+	require.NoError(t, err)
+	require.Equal(t, request.ID, pending.ID)
+
+	// درخواست دوم تا وقتی اولی در انتظار است مجاز نیست
+	_, err = testQueries.CreateErasureRequest(context.Background(), CreateErasureRequestParams{
+		UserID:       owner,
+		ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	require.Error(t, err)
+
+	cancelled, err := testQueries.CancelErasureRequest(context.Background(), owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cancelled)
+
+	_, err = testQueries.GetPendingErasureRequest(context.Background(), owner)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	createRandomErasureRequest(t, user.ID, time.Now().UTC())
+}
+
+func TestEraseUserTx(t *testing.T) {
+	dataset := createRandomDataset(t)
+	user, err := testQueries.GetUserByID(context.Background(), dataset.UserID.Int32)
+	require.NoError(t, err)
+	request := createRandomErasureRequest(t, user.ID, time.Now().UTC().Add(-time.Minute))
+
+	// درخواستی که مهلتش تمام نشده نباید اجرا شود
+	notDue := createRandomErasureRequest(t, createRandomUser(t).ID, time.Now().UTC().Add(time.Hour))
+
+	due, err := testQueries.ListDueErasureRequests(context.Background(), ListDueErasureRequestsParams{
+		ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		Limit:        1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, due, request)
+	require.NotContains(t, due, notDue)
+
+	err = testStore.EraseUserTx(context.Background(), request)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserByID(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testQueries.GetDatasetByID(context.Background(), dataset.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	due, err = testQueries.ListDueErasureRequests(context.Background(), ListDueErasureRequestsParams{
+		ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		Limit:        1000,
+	})
+	require.NoError(t, err)
+	for _, r := range due {
+		require.NotEqual(t, request.ID, r.ID)
+	}
+}
+
+func TestCountDueErasureRequests(t *testing.T) {
+	now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+	before, err := testQueries.CountDueErasureRequests(context.Background(), now)
+	require.NoError(t, err)
+
+	createRandomErasureRequest(t, createRandomUser(t).ID, now.Time.Add(-time.Minute))
+	createRandomErasureRequest(t, createRandomUser(t).ID, now.Time.Add(time.Hour))
+
+	after, err := testQueries.CountDueErasureRequests(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, before+1, after)
+}
+
+func TestRecordErasureFailure(t *testing.T) {
+	user := createRandomUser(t)
+	request := createRandomErasureRequest(t, user.ID, time.Now().UTC().Add(-time.Hour))
+
+	for i := 0; i < 2; i++ {
+		err := testQueries.RecordErasureFailure(context.Background(), RecordErasureFailureParams{
+			ID:        request.ID,
+			LastError: pgtype.Text{String: "erase failed", Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	pending, err := testQueries.GetPendingErasureRequest(context.Background(), request.UserID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), pending.Attempts)
+	require.Equal(t, "erase failed", pending.LastError.String)
+	require.True(t, pending.LastAttemptAt.Valid)
+	require.False(t, pending.CompletedAt.Valid)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func recordLoginFailure(t *testing.T, key string, failedAt, windowStart time.Time) LoginAttempt {
+	attempt, err := testQueries.AddLoginFailure(context.Background(), AddLoginFailureParams{
+		AttemptKey:  key,
+		FailedAt:    pgtype.Timestamp{Time: failedAt, Valid: true},
+		WindowStart: pgtype.Timestamp{Time: windowStart, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, attempt.AttemptKey)
+	return attempt
+}
+
+func TestAddLoginFailure(t *testing.T) {
+	key := "email:" + util.RandomEmail()
+	now := time.Now().UTC()
+
+	attempt := recordLoginFailure(t, key, now, now.Add(-time.Hour))
+	require.Equal(t, int32(1), attempt.Failures)
+
+	attempt = recordLoginFailure(t, key, now.Add(time.Second), now.Add(-time.Hour))
+	require.Equal(t, int32(2), attempt.Failures)
+
+	// شکست‌های قدیمی‌تر از ابتدای پنجره فراموش می‌شوند
+	later := now.Add(2 * time.Hour)
+	attempt = recordLoginFailure(t, key, later, later.Add(-time.Hour))
+	require.Equal(t, int32(1), attempt.Failures)
+
+	err := testQueries.DeleteLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginAttempt(context.Background(), key)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestRemoveLoginFailure(t *testing.T) {
+	key := "ip:" + util.RandomString(8)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	recordLoginFailure(t, key, now, now.Add(-time.Hour))
+	reservedAt := now.Add(time.Second)
+	recordLoginFailure(t, key, reservedAt, now.Add(-time.Hour))
+
+	// شکست پس گرفته شده زمان آخرین شکست را هم برمی‌گرداند
+	err := testQueries.RemoveLoginFailure(context.Background(), RemoveLoginFailureParams{
+		AttemptKey:        key,
+		FailedAt:          pgtype.Timestamp{Time: reservedAt, Valid: true},
+		PreviousFailureAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+
+	attempt, err := testQueries.GetLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.Failures)
+	require.WithinDuration(t, now, attempt.LastFailureAt.Time, 0)
+
+	// اگر شکست دیگری بعد از آن ثبت شده باشد، زمان آن می‌ماند
+	reservedAt = now.Add(2 * time.Second)
+	recordLoginFailure(t, key, reservedAt, now.Add(-time.Hour))
+	later := now.Add(3 * time.Second)
+	recordLoginFailure(t, key, later, now.Add(-time.Hour))
+
+	err = testQueries.RemoveLoginFailure(context.Background(), RemoveLoginFailureParams{
+		AttemptKey:        key,
+		FailedAt:          pgtype.Timestamp{Time: reservedAt, Valid: true},
+		PreviousFailureAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+
+	attempt, err = testQueries.GetLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempt.Failures)
+	require.WithinDuration(t, later, attempt.LastFailureAt.Time, 0)
+}
@@ -11,10 +11,21 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const anonymizeUserLogs = `-- name: AnonymizeUserLogs :exec
+UPDATE logs
+SET user_id = NULL
+WHERE user_id = $1
+`
+
+func (q *Queries) AnonymizeUserLogs(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, anonymizeUserLogs, userID)
+	return err
+}
+
 const createLog = `-- name: CreateLog :one
-INSERT INTO logs (user_id, project_id, action, details)
-VALUES ($1, $2, $3, $4)
-RETURNING id, user_id, project_id, action, details, created_at
+INSERT INTO logs (user_id, project_id, action, details, ip_address, request_id, trace_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, project_id, action, details, created_at, ip_address, request_id, trace_id
 `
 
 type CreateLogParams struct {
@@ -22,6 +33,9 @@ type CreateLogParams struct {
 	ProjectID pgtype.Int4 `json:"project_id"`
 	Action    pgtype.Text `json:"action"`
 	Details   pgtype.Text `json:"details"`
+	IpAddress pgtype.Text `json:"ip_address"`
+	RequestID pgtype.Text `json:"request_id"`
+	TraceID   pgtype.Text `json:"trace_id"`
 }
 
 func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, error) {
@@ -30,6 +44,9 @@ func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, erro
 		arg.ProjectID,
 		arg.Action,
 		arg.Details,
+		arg.IpAddress,
+		arg.RequestID,
+		arg.TraceID,
 	)
 	var i Log
 	err := row.Scan(
@@ -39,6 +56,9 @@ func (q *Queries) CreateLog(ctx context.Context, arg CreateLogParams) (Log, erro
 		&i.Action,
 		&i.Details,
 		&i.CreatedAt,
+		&i.IpAddress,
+		&i.RequestID,
+		&i.TraceID,
 	)
 	return i, err
 }
@@ -53,7 +73,7 @@ func (q *Queries) DeleteLog(ctx context.Context, id int32) error {
 }
 
 const getLogByID = `-- name: GetLogByID :one
-SELECT id, user_id, project_id, action, details, created_at FROM logs WHERE id = $1 LIMIT 1
+SELECT id, user_id, project_id, action, details, created_at, ip_address, request_id, trace_id FROM logs WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetLogByID(ctx context.Context, id int32) (Log, error) {
@@ -66,12 +86,15 @@ func (q *Queries) GetLogByID(ctx context.Context, id int32) (Log, error) {
 		&i.Action,
 		&i.Details,
 		&i.CreatedAt,
+		&i.IpAddress,
+		&i.RequestID,
+		&i.TraceID,
 	)
 	return i, err
 }
 
 const getLogsByProjectOrUser = `-- name: GetLogsByProjectOrUser :many
-SELECT id, user_id, project_id, action, details, created_at FROM logs
+SELECT id, user_id, project_id, action, details, created_at, ip_address, request_id, trace_id FROM logs
 WHERE project_id = $1 OR user_id = $2
 ORDER BY created_at DESC
 `
@@ -97,6 +120,9 @@ func (q *Queries) GetLogsByProjectOrUser(ctx context.Context, arg GetLogsByProje
 			&i.Action,
 			&i.Details,
 			&i.CreatedAt,
+			&i.IpAddress,
+			&i.RequestID,
+			&i.TraceID,
 		); err != nil {
 			return nil, err
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/faezefz/SFP_website/util"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
@@ -17,6 +18,9 @@ func createRandomLog(t *testing.T) Log {
 		ProjectID: pgtype.Int4{Int32: project.ID, Valid: true},
 		Action:    pgtype.Text{String: "Created", Valid: true},
 		Details:   pgtype.Text{String: "Created a new project", Valid: true},
+		IpAddress: pgtype.Text{String: "127.0.0.1", Valid: true},
+		RequestID: pgtype.Text{String: util.RandomString(16), Valid: true},
+		TraceID:   pgtype.Text{String: util.RandomString(32), Valid: true},
 	}
 
 	logEntry, err := testQueries.CreateLog(context.Background(), arg)
@@ -27,6 +31,9 @@ func createRandomLog(t *testing.T) Log {
 	require.Equal(t, arg.ProjectID.Int32, logEntry.ProjectID.Int32)
 	require.Equal(t, arg.Action.String, logEntry.Action.String)
 	require.Equal(t, arg.Details.String, logEntry.Details.String)
+	require.Equal(t, arg.IpAddress.String, logEntry.IpAddress.String)
+	require.Equal(t, arg.RequestID.String, logEntry.RequestID.String)
+	require.Equal(t, arg.TraceID.String, logEntry.TraceID.String)
 
 	return logEntry
 }
@@ -75,3 +82,15 @@ func TestDeleteLog(t *testing.T) {
 	_, err = testQueries.GetLogByID(context.Background(), logEntry.ID)
 	require.Error(t, err)
 }
+
+func TestLogOutlivesProject(t *testing.T) {
+	logEntry := createRandomLog(t)
+
+	// سابقه حسابرسی پس از حذف پروژه هم به آن اشاره می‌کند
+	err := testQueries.DeleteProject(context.Background(), logEntry.ProjectID.Int32)
+	require.NoError(t, err)
+
+	fetched, err := testQueries.GetLogByID(context.Background(), logEntry.ID)
+	require.NoError(t, err)
+	require.Equal(t, logEntry.ProjectID, fetched.ProjectID)
+}
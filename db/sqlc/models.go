@@ -8,6 +8,20 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID         int32            `json:"id"`
+	UserID     int32            `json:"user_id"`
+	Name       string           `json:"name"`
+	Prefix     string           `json:"prefix"`
+	SecretHash string           `json:"secret_hash"`
+	Scopes     []string         `json:"scopes"`
+	ProjectID  pgtype.Int4      `json:"project_id"`
+	ExpiresAt  pgtype.Timestamp `json:"expires_at"`
+	LastUsedAt pgtype.Timestamp `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
 type Dataset struct {
 	ID          int32            `json:"id"`
 	UserID      pgtype.Int4      `json:"user_id"`
@@ -17,6 +31,27 @@ type Dataset struct {
 	UploadedAt  pgtype.Timestamp `json:"uploaded_at"`
 }
 
+type EmailVerificationToken struct {
+	ID        int32            `json:"id"`
+	UserID    int32            `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ErasureRequest struct {
+	ID            int32            `json:"id"`
+	UserID        pgtype.Int4      `json:"user_id"`
+	ExecuteAfter  pgtype.Timestamp `json:"execute_after"`
+	CompletedAt   pgtype.Timestamp `json:"completed_at"`
+	CancelledAt   pgtype.Timestamp `json:"cancelled_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	Attempts      int32            `json:"attempts"`
+	LastError     pgtype.Text      `json:"last_error"`
+	LastAttemptAt pgtype.Timestamp `json:"last_attempt_at"`
+}
+
 type Log struct {
 	ID        int32            `json:"id"`
 	UserID    pgtype.Int4      `json:"user_id"`
@@ -24,6 +59,15 @@ type Log struct {
 	Action    pgtype.Text      `json:"action"`
 	Details   pgtype.Text      `json:"details"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
+	IpAddress pgtype.Text      `json:"ip_address"`
+	RequestID pgtype.Text      `json:"request_id"`
+	TraceID   pgtype.Text      `json:"trace_id"`
+}
+
+type LoginAttempt struct {
+	AttemptKey    string           `json:"attempt_key"`
+	Failures      int32            `json:"failures"`
+	LastFailureAt pgtype.Timestamp `json:"last_failure_at"`
 }
 
 type Model struct {
@@ -36,6 +80,24 @@ type Model struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type OidcLoginState struct {
+	ID           int32            `json:"id"`
+	StateHash    string           `json:"state_hash"`
+	Nonce        string           `json:"nonce"`
+	CodeVerifier string           `json:"code_verifier"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID        int32            `json:"id"`
+	UserID    int32            `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type Prediction struct {
 	ID             int32            `json:"id"`
 	UserID         pgtype.Int4      `json:"user_id"`
@@ -54,6 +116,7 @@ type Project struct {
 	Description pgtype.Text      `json:"description"`
 	Visibility  pgtype.Text      `json:"visibility"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	TeamID      pgtype.Int4      `json:"team_id"`
 }
 
 type ProjectDataset struct {
@@ -62,16 +125,89 @@ type ProjectDataset struct {
 	AddedAt   pgtype.Timestamp `json:"added_at"`
 }
 
+type ProjectMember struct {
+	ProjectID int32            `json:"project_id"`
+	UserID    int32            `json:"user_id"`
+	Role      string           `json:"role"`
+	InvitedBy pgtype.Int4      `json:"invited_by"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type ProjectModel struct {
 	ProjectID int32            `json:"project_id"`
 	ModelID   int32            `json:"model_id"`
 	AddedAt   pgtype.Timestamp `json:"added_at"`
 }
 
+type RecoveryCode struct {
+	ID        int32            `json:"id"`
+	UserID    int32            `json:"user_id"`
+	CodeHash  string           `json:"code_hash"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Session struct {
+	ID                       int32            `json:"id"`
+	UserID                   int32            `json:"user_id"`
+	RefreshTokenHash         string           `json:"refresh_token_hash"`
+	UserAgent                string           `json:"user_agent"`
+	ClientIp                 string           `json:"client_ip"`
+	IsRevoked                bool             `json:"is_revoked"`
+	ExpiresAt                pgtype.Timestamp `json:"expires_at"`
+	LastUsedAt               pgtype.Timestamp `json:"last_used_at"`
+	CreatedAt                pgtype.Timestamp `json:"created_at"`
+	PreviousRefreshTokenHash pgtype.Text      `json:"previous_refresh_token_hash"`
+}
+
+type Team struct {
+	ID          int32            `json:"id"`
+	OwnerUserID int32            `json:"owner_user_id"`
+	Name        string           `json:"name"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type TeamMember struct {
+	TeamID     int32            `json:"team_id"`
+	UserID     int32            `json:"user_id"`
+	Role       string           `json:"role"`
+	InvitedBy  pgtype.Int4      `json:"invited_by"`
+	AcceptedAt pgtype.Timestamp `json:"accepted_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type UsedChallengeToken struct {
+	TokenID   pgtype.UUID      `json:"token_id"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+}
+
 type User struct {
-	ID           int32            `json:"id"`
-	Email        string           `json:"email"`
-	PasswordHash string           `json:"password_hash"`
-	FullName     pgtype.Text      `json:"full_name"`
+	ID                    int32            `json:"id"`
+	Email                 string           `json:"email"`
+	PasswordHash          string           `json:"password_hash"`
+	FullName              pgtype.Text      `json:"full_name"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	EmailVerifiedAt       pgtype.Timestamp `json:"email_verified_at"`
+	Role                  string           `json:"role"`
+	DisabledAt            pgtype.Timestamp `json:"disabled_at"`
+	PasswordResetRequired bool             `json:"password_reset_required"`
+}
+
+type UserIdentity struct {
+	ID          int32            `json:"id"`
+	UserID      int32            `json:"user_id"`
+	Issuer      string           `json:"issuer"`
+	Subject     string           `json:"subject"`
+	Email       string           `json:"email"`
+	LastLoginAt pgtype.Timestamp `json:"last_login_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       int32            `json:"user_id"`
+	Secret       string           `json:"secret"`
+	EnabledAt    pgtype.Timestamp `json:"enabled_at"`
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	LastUsedStep int64            `json:"last_used_step"`
 }
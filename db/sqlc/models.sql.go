@@ -11,6 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countModelsByUserID = `-- name: CountModelsByUserID :one
+SELECT COUNT(*)
+FROM models
+WHERE user_id = $1
+  AND ($2::text IS NULL OR name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+`
+
+type CountModelsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountModelsByUserID(ctx context.Context, arg CountModelsByUserIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countModelsByUserID,
+		arg.UserID,
+		arg.Name,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createModel = `-- name: CreateModel :one
 INSERT INTO models (user_id, name, description, file_path)
 VALUES ($1, $2, $3, $4)
@@ -53,6 +81,15 @@ func (q *Queries) DeleteModel(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteModelsByUserID = `-- name: DeleteModelsByUserID :exec
+DELETE FROM models WHERE user_id = $1
+`
+
+func (q *Queries) DeleteModelsByUserID(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, deleteModelsByUserID, userID)
+	return err
+}
+
 const getModelByID = `-- name: GetModelByID :one
 SELECT id, user_id, name, description, model_type, file_path, created_at FROM models WHERE id = $1 LIMIT 1
 `
@@ -104,6 +141,89 @@ func (q *Queries) GetModelsByUserID(ctx context.Context, userID pgtype.Int4) ([]
 	return items, nil
 }
 
+const listModelsByUserID = `-- name: ListModelsByUserID :many
+SELECT id, user_id, name, description, model_type, created_at
+FROM models
+WHERE user_id = $1
+  AND ($2::text IS NULL OR name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::int IS NULL OR CASE $6::text
+    WHEN 'id' THEN id > $5
+    WHEN '-id' THEN id < $5
+    WHEN 'name' THEN (name, id) > ($7::text, $5)
+    WHEN '-name' THEN (name, id) < ($7::text, $5)
+    WHEN 'created_at' THEN (COALESCE(created_at, '-infinity'), id) > (COALESCE($8::timestamp, '-infinity'), $5)
+    WHEN '-created_at' THEN (COALESCE(created_at, '-infinity'), id) < (COALESCE($8::timestamp, '-infinity'), $5)
+  END)
+ORDER BY
+  CASE WHEN $6 = 'name' THEN name END,
+  CASE WHEN $6 = '-name' THEN name END DESC,
+  CASE WHEN $6 = 'created_at' THEN COALESCE(created_at, '-infinity') END,
+  CASE WHEN $6 = '-created_at' THEN COALESCE(created_at, '-infinity') END DESC,
+  CASE WHEN $6 LIKE '-%' THEN id END DESC,
+  id
+LIMIT $9
+`
+
+type ListModelsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Sort          string           `json:"sort"`
+	AfterName     pgtype.Text      `json:"after_name"`
+	AfterTime     pgtype.Timestamp `json:"after_time"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListModelsByUserIDRow struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	ModelType   pgtype.Text      `json:"model_type"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) ListModelsByUserID(ctx context.Context, arg ListModelsByUserIDParams) ([]ListModelsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listModelsByUserID,
+		arg.UserID,
+		arg.Name,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListModelsByUserIDRow
+	for rows.Next() {
+		var i ListModelsByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.ModelType,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateModel = `-- name: UpdateModel :one
 UPDATE models
 SET name = $2,
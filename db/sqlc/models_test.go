@@ -62,6 +62,43 @@ func TestGetModelsByUserID(t *testing.T) {
 	require.True(t, found, "created model not found in list")
 }
 
+func TestListModelsByUserID(t *testing.T) {
+	user := createRandomUser(t)
+	var models []Model
+	for i := 0; i < 3; i++ {
+		models = append(models, createRandomModel(t, user))
+	}
+
+	// همه صفحه‌ها از جدیدترین به قدیمی‌ترین، دو مدل در هر صفحه
+	arg := ListModelsByUserIDParams{
+		UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+		Sort:   "-created_at",
+		Limit:  2,
+	}
+	var ids []int32
+	for {
+		page, err := testQueries.ListModelsByUserID(context.Background(), arg)
+		require.NoError(t, err)
+		for _, model := range page {
+			ids = append(ids, model.ID)
+		}
+		if len(page) < int(arg.Limit) {
+			break
+		}
+		last := page[len(page)-1]
+		arg.AfterID = pgtype.Int4{Int32: last.ID, Valid: true}
+		arg.AfterTime = last.CreatedAt
+	}
+	require.Equal(t, []int32{models[2].ID, models[1].ID, models[0].ID}, ids)
+
+	total, err := testQueries.CountModelsByUserID(context.Background(), CountModelsByUserIDParams{
+		UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+		Name:   pgtype.Text{String: "test", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+}
+
 func TestUpdateModel(t *testing.T) {
 	user := createRandomUser(t)
 	model := createRandomModel(t, user)
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordResetToken(t *testing.T, user User) PasswordResetToken {
+	arg := CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(util.RandomString(32)),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC().Add(time.Hour), Valid: true},
+	}
+
+	token, err := testQueries.CreatePasswordResetToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, token.ID)
+	require.Equal(t, arg.UserID, token.UserID)
+	require.Equal(t, arg.TokenHash, token.TokenHash)
+	require.False(t, token.UsedAt.Valid)
+
+	return token
+}
+
+func TestGetPasswordResetTokenByHash(t *testing.T) {
+	token1 := createRandomPasswordResetToken(t, createRandomUser(t))
+
+	token2, err := testQueries.GetPasswordResetTokenByHash(context.Background(), token1.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, token1.ID, token2.ID)
+}
+
+func TestUsePasswordResetTokenOnce(t *testing.T) {
+	token := createRandomPasswordResetToken(t, createRandomUser(t))
+
+	used, err := testQueries.UsePasswordResetToken(context.Background(), token.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	used, err = testQueries.UsePasswordResetToken(context.Background(), token.ID)
+	require.NoError(t, err)
+	require.Zero(t, used)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	user := createRandomUser(t)
+	token1 := createRandomPasswordResetToken(t, user)
+	token2 := createRandomPasswordResetToken(t, user)
+	createRandomSession(t, user)
+
+	newHash := util.RandomPassword()
+	updated, err := testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenID:      token1.ID,
+		User:         user,
+		PasswordHash: newHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newHash, updated.PasswordHash)
+	require.Equal(t, user.Email, updated.Email)
+
+	// توکن‌های دیگر و سشن‌های کاربر باطل شده‌اند
+	other, err := testQueries.GetPasswordResetTokenByHash(context.Background(), token2.TokenHash)
+	require.NoError(t, err)
+	require.True(t, other.UsedAt.Valid)
+
+	sessions, err := testQueries.GetSessionsByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	// توکن مصرف‌شده دوباره قابل استفاده نیست
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenID:      token1.ID,
+		User:         user,
+		PasswordHash: util.RandomPassword(),
+	})
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+}
@@ -11,6 +11,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countPredictionsByProjectID = `-- name: CountPredictionsByProjectID :one
+SELECT COUNT(*) FROM predictions
+WHERE project_id = $1
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+`
+
+type CountPredictionsByProjectIDParams struct {
+	ProjectID     pgtype.Int4      `json:"project_id"`
+	Status        pgtype.Text      `json:"status"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountPredictionsByProjectID(ctx context.Context, arg CountPredictionsByProjectIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPredictionsByProjectID,
+		arg.ProjectID,
+		arg.Status,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPredictionsByUserID = `-- name: CountPredictionsByUserID :one
+SELECT COUNT(*)
+FROM predictions
+WHERE user_id = $1
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+`
+
+type CountPredictionsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Status        pgtype.Text      `json:"status"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountPredictionsByUserID(ctx context.Context, arg CountPredictionsByUserIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPredictionsByUserID,
+		arg.UserID,
+		arg.Status,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPrediction = `-- name: CreatePrediction :one
 INSERT INTO predictions (user_id, dataset_id, model_id, project_id, result_file_path)
 VALUES ($1, $2, $3, $4, $5)
@@ -56,6 +111,37 @@ func (q *Queries) DeletePrediction(ctx context.Context, id int32) error {
 	return err
 }
 
+const deletePredictionsByUserID = `-- name: DeletePredictionsByUserID :exec
+DELETE FROM predictions WHERE user_id = $1
+`
+
+func (q *Queries) DeletePredictionsByUserID(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, deletePredictionsByUserID, userID)
+	return err
+}
+
+const detachUserDatasetsFromPredictions = `-- name: DetachUserDatasetsFromPredictions :exec
+UPDATE predictions
+SET dataset_id = NULL
+WHERE dataset_id IN (SELECT id FROM datasets WHERE datasets.user_id = $1)
+`
+
+func (q *Queries) DetachUserDatasetsFromPredictions(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, detachUserDatasetsFromPredictions, userID)
+	return err
+}
+
+const detachUserModelsFromPredictions = `-- name: DetachUserModelsFromPredictions :exec
+UPDATE predictions
+SET model_id = NULL
+WHERE model_id IN (SELECT id FROM models WHERE models.user_id = $1)
+`
+
+func (q *Queries) DetachUserModelsFromPredictions(ctx context.Context, userID pgtype.Int4) error {
+	_, err := q.db.Exec(ctx, detachUserModelsFromPredictions, userID)
+	return err
+}
+
 const getPredictionByID = `-- name: GetPredictionByID :one
 SELECT id, user_id, dataset_id, model_id, project_id, result_file_path, status, created_at FROM predictions WHERE id = $1 LIMIT 1
 `
@@ -109,6 +195,141 @@ func (q *Queries) GetPredictionsByUserID(ctx context.Context, userID pgtype.Int4
 	return items, nil
 }
 
+const listPredictionsByProjectID = `-- name: ListPredictionsByProjectID :many
+SELECT id, user_id, dataset_id, model_id, project_id, result_file_path, status, created_at FROM predictions
+WHERE project_id = $1
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::int IS NULL OR id > $5)
+ORDER BY id
+LIMIT $6
+`
+
+type ListPredictionsByProjectIDParams struct {
+	ProjectID     pgtype.Int4      `json:"project_id"`
+	Status        pgtype.Text      `json:"status"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Limit         int32            `json:"limit"`
+}
+
+func (q *Queries) ListPredictionsByProjectID(ctx context.Context, arg ListPredictionsByProjectIDParams) ([]Prediction, error) {
+	rows, err := q.db.Query(ctx, listPredictionsByProjectID,
+		arg.ProjectID,
+		arg.Status,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Prediction
+	for rows.Next() {
+		var i Prediction
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.DatasetID,
+			&i.ModelID,
+			&i.ProjectID,
+			&i.ResultFilePath,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPredictionsByUserID = `-- name: ListPredictionsByUserID :many
+SELECT id, user_id, dataset_id, model_id, project_id, status, created_at
+FROM predictions
+WHERE user_id = $1
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::int IS NULL OR CASE $6::text
+    WHEN 'id' THEN id > $5
+    WHEN '-id' THEN id < $5
+    WHEN 'created_at' THEN (COALESCE(created_at, '-infinity'), id) > (COALESCE($7::timestamp, '-infinity'), $5)
+    WHEN '-created_at' THEN (COALESCE(created_at, '-infinity'), id) < (COALESCE($7::timestamp, '-infinity'), $5)
+  END)
+ORDER BY
+  CASE WHEN $6 = 'created_at' THEN COALESCE(created_at, '-infinity') END,
+  CASE WHEN $6 = '-created_at' THEN COALESCE(created_at, '-infinity') END DESC,
+  CASE WHEN $6 LIKE '-%' THEN id END DESC,
+  id
+LIMIT $8
+`
+
+type ListPredictionsByUserIDParams struct {
+	UserID        pgtype.Int4      `json:"user_id"`
+	Status        pgtype.Text      `json:"status"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Sort          string           `json:"sort"`
+	AfterTime     pgtype.Timestamp `json:"after_time"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListPredictionsByUserIDRow struct {
+	ID        int32            `json:"id"`
+	UserID    pgtype.Int4      `json:"user_id"`
+	DatasetID pgtype.Int4      `json:"dataset_id"`
+	ModelID   pgtype.Int4      `json:"model_id"`
+	ProjectID pgtype.Int4      `json:"project_id"`
+	Status    pgtype.Text      `json:"status"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+func (q *Queries) ListPredictionsByUserID(ctx context.Context, arg ListPredictionsByUserIDParams) ([]ListPredictionsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listPredictionsByUserID,
+		arg.UserID,
+		arg.Status,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPredictionsByUserIDRow
+	for rows.Next() {
+		var i ListPredictionsByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.DatasetID,
+			&i.ModelID,
+			&i.ProjectID,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updatePrediction = `-- name: UpdatePrediction :one
 UPDATE predictions
 SET result_file_path = $2
@@ -62,3 +62,41 @@ func TestPredictions(t *testing.T) {
 	_, err = testQueries.GetPredictionByID(context.Background(), pred.ID)
 	require.Error(t, err)
 }
+
+func TestListPredictionsByUserID(t *testing.T) {
+	user := createRandomUser(t)
+	project := createRandomProject(t, user.ID)
+	dataset := createRandomDataset(t)
+	model := createRandomModel(t, user)
+
+	first := createRandomPrediction(t, user.ID, dataset.ID, model.ID, project.ID)
+	second := createRandomPrediction(t, user.ID, dataset.ID, model.ID, project.ID)
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+
+	page, err := testQueries.ListPredictionsByUserID(context.Background(), ListPredictionsByUserIDParams{
+		UserID: userID,
+		Status: pgtype.Text{String: "completed", Valid: true},
+		Sort:   "-id",
+		Limit:  1,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, second.ID, page[0].ID)
+
+	page, err = testQueries.ListPredictionsByUserID(context.Background(), ListPredictionsByUserIDParams{
+		UserID:  userID,
+		AfterID: pgtype.Int4{Int32: second.ID, Valid: true},
+		Sort:    "-id",
+		Limit:   1,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, first.ID, page[0].ID)
+
+	total, err := testQueries.CountPredictionsByUserID(context.Background(), CountPredictionsByUserIDParams{
+		UserID: userID,
+		Status: pgtype.Text{String: "failed", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addDatasetToProject = `-- name: AddDatasetToProject :exec
@@ -24,28 +26,97 @@ func (q *Queries) AddDatasetToProject(ctx context.Context, arg AddDatasetToProje
 	return err
 }
 
-const getDatasetsByProjectID = `-- name: GetDatasetsByProjectID :many
-SELECT d.id, d.user_id, d.name, d.description, d.content, d.uploaded_at
+const countDatasetsByProjectID = `-- name: CountDatasetsByProjectID :one
+SELECT COUNT(*)
 FROM datasets d
 JOIN project_datasets pd ON d.id = pd.dataset_id
 WHERE pd.project_id = $1
+  AND ($2::timestamp IS NULL OR d.uploaded_at >= $2)
+  AND ($3::timestamp IS NULL OR d.uploaded_at < $3)
+`
+
+type CountDatasetsByProjectIDParams struct {
+	ProjectID     int32            `json:"project_id"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountDatasetsByProjectID(ctx context.Context, arg CountDatasetsByProjectIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDatasetsByProjectID, arg.ProjectID, arg.CreatedAfter, arg.CreatedBefore)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const isDatasetInProject = `-- name: IsDatasetInProject :one
+SELECT EXISTS (
+  SELECT 1 FROM project_datasets
+  WHERE project_id = $1 AND dataset_id = $2
+)
 `
 
-func (q *Queries) GetDatasetsByProjectID(ctx context.Context, projectID int32) ([]Dataset, error) {
-	rows, err := q.db.Query(ctx, getDatasetsByProjectID, projectID)
+type IsDatasetInProjectParams struct {
+	ProjectID int32 `json:"project_id"`
+	DatasetID int32 `json:"dataset_id"`
+}
+
+func (q *Queries) IsDatasetInProject(ctx context.Context, arg IsDatasetInProjectParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isDatasetInProject, arg.ProjectID, arg.DatasetID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listDatasetsByProjectID = `-- name: ListDatasetsByProjectID :many
+SELECT d.id, d.user_id, d.name, d.description, octet_length(d.content) AS size_bytes, d.uploaded_at
+FROM datasets d
+JOIN project_datasets pd ON d.id = pd.dataset_id
+WHERE pd.project_id = $1
+  AND ($2::timestamp IS NULL OR d.uploaded_at >= $2)
+  AND ($3::timestamp IS NULL OR d.uploaded_at < $3)
+  AND ($4::int IS NULL OR d.id > $4)
+ORDER BY d.id
+LIMIT $5
+`
+
+type ListDatasetsByProjectIDParams struct {
+	ProjectID     int32            `json:"project_id"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Limit         int32            `json:"limit"`
+}
+
+type ListDatasetsByProjectIDRow struct {
+	ID          int32            `json:"id"`
+	UserID      pgtype.Int4      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	SizeBytes   int32            `json:"size_bytes"`
+	UploadedAt  pgtype.Timestamp `json:"uploaded_at"`
+}
+
+func (q *Queries) ListDatasetsByProjectID(ctx context.Context, arg ListDatasetsByProjectIDParams) ([]ListDatasetsByProjectIDRow, error) {
+	rows, err := q.db.Query(ctx, listDatasetsByProjectID,
+		arg.ProjectID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Dataset
+	var items []ListDatasetsByProjectIDRow
 	for rows.Next() {
-		var i Dataset
+		var i ListDatasetsByProjectIDRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
 			&i.Name,
 			&i.Description,
-			&i.Content,
+			&i.SizeBytes,
 			&i.UploadedAt,
 		); err != nil {
 			return nil, err
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func addRandomProjectMember(t *testing.T, project Project, role string) User {
+	user := createRandomUser(t)
+
+	arg := AddProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      role,
+		InvitedBy: pgtype.Int4{Int32: project.OwnerUserID, Valid: true},
+	}
+
+	member, err := testQueries.AddProjectMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ProjectID, member.ProjectID)
+	require.Equal(t, arg.UserID, member.UserID)
+	require.Equal(t, arg.Role, member.Role)
+
+	return user
+}
+
+func TestAddProjectMember(t *testing.T) {
+	owner := createRandomUser(t)
+	project := createRandomProject(t, owner.ID)
+	user := addRandomProjectMember(t, project, "viewer")
+
+	member, err := testQueries.GetProjectMember(context.Background(), GetProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "viewer", member.Role)
+}
+
+func TestAddProjectMemberInvalidRole(t *testing.T) {
+	owner := createRandomUser(t)
+	project := createRandomProject(t, owner.ID)
+	user := createRandomUser(t)
+
+	_, err := testQueries.AddProjectMember(context.Background(), AddProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      "admin",
+	})
+	require.Error(t, err)
+}
+
+func TestUpdateProjectMemberRole(t *testing.T) {
+	owner := createRandomUser(t)
+	project := createRandomProject(t, owner.ID)
+	user := addRandomProjectMember(t, project, "viewer")
+
+	member, err := testQueries.UpdateProjectMemberRole(context.Background(), UpdateProjectMemberRoleParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      "maintainer",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "maintainer", member.Role)
+}
+
+func TestListProjectMembersAndRemove(t *testing.T) {
+	owner := createRandomUser(t)
+	project := createRandomProject(t, owner.ID)
+	user1 := addRandomProjectMember(t, project, "viewer")
+	addRandomProjectMember(t, project, "editor")
+
+	members, err := testQueries.ListProjectMembers(context.Background(), ListProjectMembersParams{
+		ProjectID: project.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// پروژه برای اعضا در لیست پروژه‌هایشان دیده می‌شود
+	projects, err := testQueries.ListProjectsForUser(context.Background(), ListProjectsForUserParams{
+		UserID: user1.ID,
+		Sort:   "id",
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	require.Equal(t, project.ID, projects[0].ID)
+
+	removed, err := testQueries.RemoveProjectMember(context.Background(), RemoveProjectMemberParams{
+		ProjectID: project.ID,
+		UserID:    user1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	members, err = testQueries.ListProjectMembers(context.Background(), ListProjectMembersParams{
+		ProjectID: project.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+}
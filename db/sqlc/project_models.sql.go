@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addModelToProject = `-- name: AddModelToProject :exec
@@ -24,15 +26,84 @@ func (q *Queries) AddModelToProject(ctx context.Context, arg AddModelToProjectPa
 	return err
 }
 
-const getModelsByProjectID = `-- name: GetModelsByProjectID :many
+const countModelsByProjectID = `-- name: CountModelsByProjectID :one
+SELECT COUNT(*)
+FROM models m
+JOIN project_models pm ON m.id = pm.model_id
+WHERE pm.project_id = $1
+  AND ($2::timestamp IS NULL OR m.created_at >= $2)
+  AND ($3::timestamp IS NULL OR m.created_at < $3)
+`
+
+type CountModelsByProjectIDParams struct {
+	ProjectID     int32            `json:"project_id"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountModelsByProjectID(ctx context.Context, arg CountModelsByProjectIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countModelsByProjectID, arg.ProjectID, arg.CreatedAfter, arg.CreatedBefore)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getProjectModel = `-- name: GetProjectModel :one
+SELECT m.id, m.user_id, m.name, m.description, m.model_type, m.file_path, m.created_at
+FROM models m
+JOIN project_models pm ON m.id = pm.model_id
+WHERE pm.project_id = $1 AND m.id = $2
+LIMIT 1
+`
+
+type GetProjectModelParams struct {
+	ProjectID int32 `json:"project_id"`
+	ID        int32 `json:"id"`
+}
+
+func (q *Queries) GetProjectModel(ctx context.Context, arg GetProjectModelParams) (Model, error) {
+	row := q.db.QueryRow(ctx, getProjectModel, arg.ProjectID, arg.ID)
+	var i Model
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.ModelType,
+		&i.FilePath,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listModelsByProjectID = `-- name: ListModelsByProjectID :many
 SELECT m.id, m.user_id, m.name, m.description, m.model_type, m.file_path, m.created_at
 FROM models m
 JOIN project_models pm ON m.id = pm.model_id
 WHERE pm.project_id = $1
+  AND ($2::timestamp IS NULL OR m.created_at >= $2)
+  AND ($3::timestamp IS NULL OR m.created_at < $3)
+  AND ($4::int IS NULL OR m.id > $4)
+ORDER BY m.id
+LIMIT $5
 `
 
-func (q *Queries) GetModelsByProjectID(ctx context.Context, projectID int32) ([]Model, error) {
-	rows, err := q.db.Query(ctx, getModelsByProjectID, projectID)
+type ListModelsByProjectIDParams struct {
+	ProjectID     int32            `json:"project_id"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Limit         int32            `json:"limit"`
+}
+
+func (q *Queries) ListModelsByProjectID(ctx context.Context, arg ListModelsByProjectIDParams) ([]Model, error) {
+	rows, err := q.db.Query(ctx, listModelsByProjectID,
+		arg.ProjectID,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
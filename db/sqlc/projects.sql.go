@@ -11,20 +11,112 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countProjectsForUser = `-- name: CountProjectsForUser :one
+SELECT COUNT(*)
+FROM projects p
+WHERE (
+    p.owner_user_id = $1
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = $1
+    )
+  )
+  AND ($2::text IS NULL OR p.name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::text IS NULL OR p.visibility = $3)
+  AND ($4::timestamp IS NULL OR p.created_at >= $4)
+  AND ($5::timestamp IS NULL OR p.created_at < $5)
+`
+
+type CountProjectsForUserParams struct {
+	UserID        int32            `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	Visibility    pgtype.Text      `json:"visibility"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountProjectsForUser(ctx context.Context, arg CountProjectsForUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectsForUser,
+		arg.UserID,
+		arg.Name,
+		arg.Visibility,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countVisibleProjectsByOwnerID = `-- name: CountVisibleProjectsByOwnerID :one
+SELECT COUNT(*)
+FROM projects p
+WHERE p.owner_user_id = $1
+  AND (
+    p.owner_user_id = $2
+    OR p.visibility = 'public'
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = $2
+    )
+    OR (p.visibility = 'team' AND EXISTS (
+      SELECT 1 FROM team_members tm
+      WHERE tm.team_id = p.team_id
+        AND tm.user_id = $2
+        AND tm.accepted_at IS NOT NULL
+    ))
+  )
+  AND ($3::text IS NULL OR p.name ILIKE '%' || replace(replace(replace($3, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($4::text IS NULL OR p.visibility = $4)
+  AND ($5::timestamp IS NULL OR p.created_at >= $5)
+  AND ($6::timestamp IS NULL OR p.created_at < $6)
+`
+
+type CountVisibleProjectsByOwnerIDParams struct {
+	OwnerUserID   int32            `json:"owner_user_id"`
+	ViewerID      int32            `json:"viewer_id"`
+	Name          pgtype.Text      `json:"name"`
+	Visibility    pgtype.Text      `json:"visibility"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountVisibleProjectsByOwnerID(ctx context.Context, arg CountVisibleProjectsByOwnerIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countVisibleProjectsByOwnerID,
+		arg.OwnerUserID,
+		arg.ViewerID,
+		arg.Name,
+		arg.Visibility,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createProject = `-- name: CreateProject :one
-INSERT INTO projects (owner_user_id, name, description)
-VALUES ($1, $2, $3)
-RETURNING id, owner_user_id, name, description, visibility, created_at
+INSERT INTO projects (owner_user_id, name, description, visibility, team_id)
+VALUES ($1, $2, $3, COALESCE($4, 'private'), $5)
+RETURNING id, owner_user_id, name, description, visibility, created_at, team_id
 `
 
 type CreateProjectParams struct {
 	OwnerUserID int32       `json:"owner_user_id"`
 	Name        string      `json:"name"`
 	Description pgtype.Text `json:"description"`
+	Visibility  pgtype.Text `json:"visibility"`
+	TeamID      pgtype.Int4 `json:"team_id"`
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
-	row := q.db.QueryRow(ctx, createProject, arg.OwnerUserID, arg.Name, arg.Description)
+	row := q.db.QueryRow(ctx, createProject,
+		arg.OwnerUserID,
+		arg.Name,
+		arg.Description,
+		arg.Visibility,
+		arg.TeamID,
+	)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -33,6 +125,7 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Description,
 		&i.Visibility,
 		&i.CreatedAt,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -47,7 +140,7 @@ func (q *Queries) DeleteProject(ctx context.Context, id int32) error {
 }
 
 const getProjectByID = `-- name: GetProjectByID :one
-SELECT id, owner_user_id, name, description, visibility, created_at FROM projects WHERE id = $1 LIMIT 1
+SELECT id, owner_user_id, name, description, visibility, created_at, team_id FROM projects WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetProjectByID(ctx context.Context, id int32) (Project, error) {
@@ -60,12 +153,13 @@ func (q *Queries) GetProjectByID(ctx context.Context, id int32) (Project, error)
 		&i.Description,
 		&i.Visibility,
 		&i.CreatedAt,
+		&i.TeamID,
 	)
 	return i, err
 }
 
 const getProjectsByOwnerID = `-- name: GetProjectsByOwnerID :many
-SELECT id, owner_user_id, name, description, visibility, created_at FROM projects WHERE owner_user_id = $1 ORDER BY id
+SELECT id, owner_user_id, name, description, visibility, created_at, team_id FROM projects WHERE owner_user_id = $1 ORDER BY id
 `
 
 func (q *Queries) GetProjectsByOwnerID(ctx context.Context, ownerUserID int32) ([]Project, error) {
@@ -84,6 +178,185 @@ func (q *Queries) GetProjectsByOwnerID(ctx context.Context, ownerUserID int32) (
 			&i.Description,
 			&i.Visibility,
 			&i.CreatedAt,
+			&i.TeamID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsForUser = `-- name: ListProjectsForUser :many
+SELECT p.id, p.owner_user_id, p.name, p.description, p.visibility, p.created_at, p.team_id
+FROM projects p
+WHERE (
+    p.owner_user_id = $1
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = $1
+    )
+  )
+  AND ($2::text IS NULL OR p.name ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($3::text IS NULL OR p.visibility = $3)
+  AND ($4::timestamp IS NULL OR p.created_at >= $4)
+  AND ($5::timestamp IS NULL OR p.created_at < $5)
+  AND ($6::int IS NULL OR CASE $7::text
+    WHEN 'id' THEN p.id > $6
+    WHEN '-id' THEN p.id < $6
+    WHEN 'name' THEN (p.name, p.id) > ($8::text, $6)
+    WHEN '-name' THEN (p.name, p.id) < ($8::text, $6)
+    WHEN 'created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) > (COALESCE($9::timestamp, '-infinity'), $6)
+    WHEN '-created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) < (COALESCE($9::timestamp, '-infinity'), $6)
+  END)
+ORDER BY
+  CASE WHEN $7 = 'name' THEN p.name END,
+  CASE WHEN $7 = '-name' THEN p.name END DESC,
+  CASE WHEN $7 = 'created_at' THEN COALESCE(p.created_at, '-infinity') END,
+  CASE WHEN $7 = '-created_at' THEN COALESCE(p.created_at, '-infinity') END DESC,
+  CASE WHEN $7 LIKE '-%' THEN p.id END DESC,
+  p.id
+LIMIT $10
+`
+
+type ListProjectsForUserParams struct {
+	UserID        int32            `json:"user_id"`
+	Name          pgtype.Text      `json:"name"`
+	Visibility    pgtype.Text      `json:"visibility"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Sort          string           `json:"sort"`
+	AfterName     pgtype.Text      `json:"after_name"`
+	AfterTime     pgtype.Timestamp `json:"after_time"`
+	Limit         int32            `json:"limit"`
+}
+
+func (q *Queries) ListProjectsForUser(ctx context.Context, arg ListProjectsForUserParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listProjectsForUser,
+		arg.UserID,
+		arg.Name,
+		arg.Visibility,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerUserID,
+			&i.Name,
+			&i.Description,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.TeamID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVisibleProjectsByOwnerID = `-- name: ListVisibleProjectsByOwnerID :many
+SELECT p.id, p.owner_user_id, p.name, p.description, p.visibility, p.created_at, p.team_id
+FROM projects p
+WHERE p.owner_user_id = $1
+  AND (
+    p.owner_user_id = $2
+    OR p.visibility = 'public'
+    OR EXISTS (
+      SELECT 1 FROM project_members pm
+      WHERE pm.project_id = p.id AND pm.user_id = $2
+    )
+    OR (p.visibility = 'team' AND EXISTS (
+      SELECT 1 FROM team_members tm
+      WHERE tm.team_id = p.team_id
+        AND tm.user_id = $2
+        AND tm.accepted_at IS NOT NULL
+    ))
+  )
+  AND ($3::text IS NULL OR p.name ILIKE '%' || replace(replace(replace($3, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($4::text IS NULL OR p.visibility = $4)
+  AND ($5::timestamp IS NULL OR p.created_at >= $5)
+  AND ($6::timestamp IS NULL OR p.created_at < $6)
+  AND ($7::int IS NULL OR CASE $8::text
+    WHEN 'id' THEN p.id > $7
+    WHEN '-id' THEN p.id < $7
+    WHEN 'name' THEN (p.name, p.id) > ($9::text, $7)
+    WHEN '-name' THEN (p.name, p.id) < ($9::text, $7)
+    WHEN 'created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) > (COALESCE($10::timestamp, '-infinity'), $7)
+    WHEN '-created_at' THEN (COALESCE(p.created_at, '-infinity'), p.id) < (COALESCE($10::timestamp, '-infinity'), $7)
+  END)
+ORDER BY
+  CASE WHEN $8 = 'name' THEN p.name END,
+  CASE WHEN $8 = '-name' THEN p.name END DESC,
+  CASE WHEN $8 = 'created_at' THEN COALESCE(p.created_at, '-infinity') END,
+  CASE WHEN $8 = '-created_at' THEN COALESCE(p.created_at, '-infinity') END DESC,
+  CASE WHEN $8 LIKE '-%' THEN p.id END DESC,
+  p.id
+LIMIT $11
+`
+
+type ListVisibleProjectsByOwnerIDParams struct {
+	OwnerUserID   int32            `json:"owner_user_id"`
+	ViewerID      int32            `json:"viewer_id"`
+	Name          pgtype.Text      `json:"name"`
+	Visibility    pgtype.Text      `json:"visibility"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Sort          string           `json:"sort"`
+	AfterName     pgtype.Text      `json:"after_name"`
+	AfterTime     pgtype.Timestamp `json:"after_time"`
+	Limit         int32            `json:"limit"`
+}
+
+func (q *Queries) ListVisibleProjectsByOwnerID(ctx context.Context, arg ListVisibleProjectsByOwnerIDParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listVisibleProjectsByOwnerID,
+		arg.OwnerUserID,
+		arg.ViewerID,
+		arg.Name,
+		arg.Visibility,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterName,
+		arg.AfterTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerUserID,
+			&i.Name,
+			&i.Description,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.TeamID,
 		); err != nil {
 			return nil, err
 		}
@@ -98,19 +371,29 @@ func (q *Queries) GetProjectsByOwnerID(ctx context.Context, ownerUserID int32) (
 const updateProject = `-- name: UpdateProject :one
 UPDATE projects
 SET name = $2,
-    description = $3
+    description = $3,
+    visibility = COALESCE($4, visibility),
+    team_id = COALESCE($5, team_id)
 WHERE id = $1
-RETURNING id, owner_user_id, name, description, visibility, created_at
+RETURNING id, owner_user_id, name, description, visibility, created_at, team_id
 `
 
 type UpdateProjectParams struct {
 	ID          int32       `json:"id"`
 	Name        string      `json:"name"`
 	Description pgtype.Text `json:"description"`
+	Visibility  pgtype.Text `json:"visibility"`
+	TeamID      pgtype.Int4 `json:"team_id"`
 }
 
 func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
-	row := q.db.QueryRow(ctx, updateProject, arg.ID, arg.Name, arg.Description)
+	row := q.db.QueryRow(ctx, updateProject,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Visibility,
+		arg.TeamID,
+	)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -119,6 +402,7 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Description,
 		&i.Visibility,
 		&i.CreatedAt,
+		&i.TeamID,
 	)
 	return i, err
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 )
@@ -75,6 +76,47 @@ func TestGetProjectsByOwnerID(t *testing.T) {
 	}
 }
 
+func TestListProjectsForUser(t *testing.T) {
+	user := createRandomUser(t)
+	for _, name := range []string{"Apollo", "Gemini", "Mercury"} {
+		_, err := testQueries.CreateProject(context.Background(), CreateProjectParams{
+			OwnerUserID: user.ID,
+			Name:        name,
+			Visibility:  pgtype.Text{String: "public", Valid: name != "Gemini"},
+		})
+		require.NoError(t, err)
+	}
+
+	projects, err := testQueries.ListProjectsForUser(context.Background(), ListProjectsForUserParams{
+		UserID:     user.ID,
+		Visibility: pgtype.Text{String: "public", Valid: true},
+		Sort:       "-name",
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	require.Equal(t, "Mercury", projects[0].Name)
+	require.Equal(t, "Apollo", projects[1].Name)
+
+	projects, err = testQueries.ListProjectsForUser(context.Background(), ListProjectsForUserParams{
+		UserID:    user.ID,
+		AfterID:   pgtype.Int4{Int32: projects[0].ID, Valid: true},
+		Sort:      "-name",
+		AfterName: pgtype.Text{String: projects[0].Name, Valid: true},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	require.Equal(t, "Gemini", projects[0].Name)
+
+	total, err := testQueries.CountProjectsForUser(context.Background(), CountProjectsForUserParams{
+		UserID:     user.ID,
+		Visibility: pgtype.Text{String: "private", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+}
+
 // تست UpdateProject
 func TestUpdateProject(t *testing.T) {
 	user := createRandomUser(t)
@@ -123,10 +165,21 @@ func TestProjectDatasets(t *testing.T) {
 	require.NoError(t, err)
 
 	// بررسی گرفتن دیتاست‌ها
-	datasets, err := testQueries.GetDatasetsByProjectID(context.Background(), project.ID)
+	datasets, err := testQueries.ListDatasetsByProjectID(context.Background(), ListDatasetsByProjectIDParams{
+		ProjectID: project.ID,
+		Limit:     10,
+	})
 	require.NoError(t, err)
 	require.Len(t, datasets, 1)
 	require.Equal(t, dataset.ID, datasets[0].ID)
+	require.Equal(t, int32(len(dataset.Content)), datasets[0].SizeBytes)
+
+	attached, err := testQueries.IsDatasetInProject(context.Background(), IsDatasetInProjectParams{
+		ProjectID: project.ID,
+		DatasetID: dataset.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, attached)
 
 	// حذف دیتاست از پروژه
 	err = testQueries.RemoveDatasetFromProject(context.Background(), RemoveDatasetFromProjectParams{
@@ -136,9 +189,56 @@ func TestProjectDatasets(t *testing.T) {
 	require.NoError(t, err)
 
 	// بررسی اینکه بعد از حذف خالی شده
-	datasets, err = testQueries.GetDatasetsByProjectID(context.Background(), project.ID)
+	total, err := testQueries.CountDatasetsByProjectID(context.Background(), CountDatasetsByProjectIDParams{
+		ProjectID: project.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	attached, err = testQueries.IsDatasetInProject(context.Background(), IsDatasetInProjectParams{
+		ProjectID: project.ID,
+		DatasetID: dataset.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, attached)
+}
+
+func TestCreateProjectDatasetTx(t *testing.T) {
+	user := createRandomUser(t)
+	project := createRandomProject(t, user.ID)
+
+	dataset, err := testStore.CreateProjectDatasetTx(context.Background(), project.ID, CreateDatasetParams{
+		UserID:  pgtype.Int4{Int32: user.ID, Valid: true},
+		Name:    "Uploaded to project",
+		Content: []byte("a,b\n1,2\n"),
+	})
 	require.NoError(t, err)
-	require.Len(t, datasets, 0)
+
+	attached, err := testQueries.IsDatasetInProject(context.Background(), IsDatasetInProjectParams{
+		ProjectID: project.ID,
+		DatasetID: dataset.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, attached)
+
+	// پروژه ناموجود: دیتاست هم ساخته نمی‌شود
+	total, err := testQueries.CountDatasetsByUserID(context.Background(), CountDatasetsByUserIDParams{
+		UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.CreateProjectDatasetTx(context.Background(), -1, CreateDatasetParams{
+		UserID:  pgtype.Int4{Int32: user.ID, Valid: true},
+		Name:    "Orphan",
+		Content: []byte("a\n1\n"),
+	})
+	require.Error(t, err)
+
+	after, err := testQueries.CountDatasetsByUserID(context.Background(), CountDatasetsByUserIDParams{
+		UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, total, after)
 }
 
 func TestProjectModels(t *testing.T) {
@@ -154,11 +254,21 @@ func TestProjectModels(t *testing.T) {
 	require.NoError(t, err)
 
 	// بررسی گرفتن مدل‌ها
-	models, err := testQueries.GetModelsByProjectID(context.Background(), project.ID)
+	models, err := testQueries.ListModelsByProjectID(context.Background(), ListModelsByProjectIDParams{
+		ProjectID: project.ID,
+		Limit:     10,
+	})
 	require.NoError(t, err)
 	require.Len(t, models, 1)
 	require.Equal(t, model.ID, models[0].ID)
 
+	attached, err := testQueries.GetProjectModel(context.Background(), GetProjectModelParams{
+		ProjectID: project.ID,
+		ID:        model.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.ID, attached.ID)
+
 	// حذف مدل از پروژه
 	err = testQueries.RemoveModelFromProject(context.Background(), RemoveModelFromProjectParams{
 		ProjectID: project.ID,
@@ -167,7 +277,15 @@ func TestProjectModels(t *testing.T) {
 	require.NoError(t, err)
 
 	// بررسی اینکه بعد از حذف خالی شده
-	models, err = testQueries.GetModelsByProjectID(context.Background(), project.ID)
+	total, err := testQueries.CountModelsByProjectID(context.Background(), CountModelsByProjectIDParams{
+		ProjectID: project.ID,
+	})
 	require.NoError(t, err)
-	require.Len(t, models, 0)
+	require.Zero(t, total)
+
+	_, err = testQueries.GetProjectModel(context.Background(), GetProjectModelParams{
+		ProjectID: project.ID,
+		ID:        model.ID,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
 }
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	arg := CreateSessionParams{
+		UserID:           user.ID,
+		RefreshTokenHash: util.HashSecret(util.RandomString(32)),
+		UserAgent:        "go-test",
+		ClientIp:         "127.0.0.1",
+		ExpiresAt:        pgtype.Timestamp{Time: time.Now().UTC().Add(time.Hour), Valid: true},
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.UserID, session.UserID)
+	require.Equal(t, arg.RefreshTokenHash, session.RefreshTokenHash)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsRevoked)
+	require.WithinDuration(t, arg.ExpiresAt.Time, session.ExpiresAt.Time, time.Second)
+	require.NotZero(t, session.ID)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomSession(t, user)
+}
+
+func TestGetSessionByRefreshTokenHash(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user)
+
+	session2, err := testQueries.GetSessionByRefreshTokenHash(context.Background(), session1.RefreshTokenHash)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, session1.UserID, session2.UserID)
+}
+
+func TestRotateSessionRefreshToken(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user)
+
+	arg := RotateSessionRefreshTokenParams{
+		ID:                  session1.ID,
+		RefreshTokenHash:    util.HashSecret(util.RandomString(32)),
+		UserAgent:           "go-test/2",
+		ClientIp:            "10.0.0.1",
+		OldRefreshTokenHash: session1.RefreshTokenHash,
+		Now:                 pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+
+	session2, err := testQueries.RotateSessionRefreshToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session2.ID)
+	require.Equal(t, arg.RefreshTokenHash, session2.RefreshTokenHash)
+	require.Equal(t, arg.UserAgent, session2.UserAgent)
+
+	_, err = testQueries.GetSessionByRefreshTokenHash(context.Background(), session1.RefreshTokenHash)
+	require.Error(t, err)
+
+	// توکن قبلی نگه داشته می‌شود تا ارائه دوباره آن شناخته شود
+	require.Equal(t, session1.RefreshTokenHash, session2.PreviousRefreshTokenHash.String)
+	session3, err := testQueries.GetSessionByPreviousRefreshTokenHash(context.Background(), session2.PreviousRefreshTokenHash)
+	require.NoError(t, err)
+	require.Equal(t, session1.ID, session3.ID)
+
+	// چرخش دوم با همان توکن قبلی دیگر ردیفی پیدا نمی‌کند
+	arg.RefreshTokenHash = util.HashSecret(util.RandomString(32))
+	_, err = testQueries.RotateSessionRefreshToken(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestRotateExpiredSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	_, err := testQueries.RotateSessionRefreshToken(context.Background(), RotateSessionRefreshTokenParams{
+		ID:                  session.ID,
+		RefreshTokenHash:    util.HashSecret(util.RandomString(32)),
+		OldRefreshTokenHash: session.RefreshTokenHash,
+		Now:                 pgtype.Timestamp{Time: session.ExpiresAt.Time.Add(time.Second), Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestRevokeSession(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	// کاربر دیگری نمی‌تواند سشن را باطل کند
+	revoked, err := testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:     session.ID,
+		UserID: other.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+
+	revoked, err = testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:     session.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), revoked)
+
+	sessions, err := testQueries.GetSessionsByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user)
+	}
+
+	sessions, err := testQueries.GetSessionsByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	revoked, err := testQueries.RevokeUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), revoked)
+
+	sessions, err = testQueries.GetSessionsByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestListSessionsByUserIDPages(t *testing.T) {
+	user := createRandomUser(t)
+	var ids []int32
+	for i := 0; i < 3; i++ {
+		ids = append(ids, createRandomSession(t, user).ID)
+	}
+
+	// جدیدترین سشن‌ها اول می‌آیند و cursor از آخرین سطر صفحه ادامه می‌دهد
+	page1, err := testQueries.ListSessionsByUserID(context.Background(), ListSessionsByUserIDParams{
+		UserID: user.ID,
+		Limit:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page1, 2)
+	require.Equal(t, ids[2], page1[0].ID)
+	require.Equal(t, ids[1], page1[1].ID)
+
+	page2, err := testQueries.ListSessionsByUserID(context.Background(), ListSessionsByUserIDParams{
+		UserID:  user.ID,
+		AfterID: pgtype.Int4{Int32: page1[1].ID, Valid: true},
+		Limit:   2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page2, 1)
+	require.Equal(t, ids[0], page2[0].ID)
+
+	total, err := testQueries.CountSessionsByUserID(context.Background(), CountSessionsByUserIDParams{UserID: user.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDomainCounts(t *testing.T) {
+	before, err := testQueries.GetDomainCounts(context.Background())
+	require.NoError(t, err)
+
+	user := createRandomUser(t)
+	createRandomModel(t, user)
+
+	after, err := testQueries.GetDomainCounts(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, after.Users, before.Users+1)
+	require.GreaterOrEqual(t, after.Models, before.Models+1)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store provides all functions to execute db queries and transactions
+type Store interface {
+	Querier
+	CreateTeamTx(ctx context.Context, arg CreateTeamTxParams) (CreateTeamTxResult, error)
+	CreateProjectDatasetTx(ctx context.Context, projectID int32, arg CreateDatasetParams) (Dataset, error)
+	DeleteUserTx(ctx context.Context, userID int32) error
+	EraseUserTx(ctx context.Context, request ErasureRequest) error
+	OIDCLoginTx(ctx context.Context, arg OIDCLoginTxParams) (OIDCLoginTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	UpdateUserProfileTx(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	EnableTotpTx(ctx context.Context, userID int32, recoveryCodeHashes []string) (UserTotp, error)
+	DisableTotpTx(ctx context.Context, userID int32) error
+	VerifyEmailTx(ctx context.Context, tokenID int32, userID int32) (User, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version int64, dirty bool, err error)
+	PoolStat() *pgxpool.Stat
+}
+
+// SQLStore provides all functions to execute SQL queries and transactions
+type SQLStore struct {
+	*Queries
+	connPool *pgxpool.Pool
+}
+
+// NewStore creates a new store
+func NewStore(connPool *pgxpool.Pool) Store {
+	return &SQLStore{
+		Queries:  New(connPool),
+		connPool: connPool,
+	}
+}
+
+// execTx executes a function within a database transaction
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/faezefz/SFP_website/db/migration"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorePing(t *testing.T) {
+	require.NoError(t, testStore.Ping(context.Background()))
+}
+
+func TestMigrationVersion(t *testing.T) {
+	version, dirty, err := testStore.MigrationVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, dirty)
+
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, int64(latest), version)
+}
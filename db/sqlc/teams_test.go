@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTeam(t *testing.T, owner User) Team {
+	result, err := testStore.CreateTeamTx(context.Background(), CreateTeamTxParams{
+		OwnerUserID: owner.ID,
+		Name:        util.RandomString(8),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Team.ID)
+	require.Equal(t, owner.ID, result.Team.OwnerUserID)
+
+	require.Equal(t, result.Team.ID, result.Member.TeamID)
+	require.Equal(t, owner.ID, result.Member.UserID)
+	require.Equal(t, TeamRoleOwner, result.Member.Role)
+	require.True(t, result.Member.AcceptedAt.Valid)
+
+	return result.Team
+}
+
+func inviteRandomMember(t *testing.T, team Team) User {
+	user := createRandomUser(t)
+
+	member, err := testQueries.CreateTeamMember(context.Background(), CreateTeamMemberParams{
+		TeamID:    team.ID,
+		UserID:    user.ID,
+		Role:      TeamRoleMember,
+		InvitedBy: pgtype.Int4{Int32: team.OwnerUserID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, member.AcceptedAt.Valid)
+
+	return user
+}
+
+func TestCreateTeamTx(t *testing.T) {
+	owner := createRandomUser(t)
+	team := createRandomTeam(t, owner)
+
+	teams, err := testQueries.ListTeamsByUserID(context.Background(), ListTeamsByUserIDParams{
+		UserID: owner.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	require.Equal(t, team.ID, teams[0].ID)
+}
+
+func TestUpdateTeam(t *testing.T) {
+	team1 := createRandomTeam(t, createRandomUser(t))
+
+	team2, err := testQueries.UpdateTeam(context.Background(), UpdateTeamParams{
+		ID:   team1.ID,
+		Name: util.RandomString(10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, team1.ID, team2.ID)
+	require.NotEqual(t, team1.Name, team2.Name)
+}
+
+func TestDeleteTeam(t *testing.T) {
+	team := createRandomTeam(t, createRandomUser(t))
+
+	err := testQueries.DeleteTeam(context.Background(), team.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetTeamByID(context.Background(), team.ID)
+	require.Error(t, err)
+}
+
+func TestTeamInvite(t *testing.T) {
+	team := createRandomTeam(t, createRandomUser(t))
+	user := inviteRandomMember(t, team)
+
+	// دعوت پذیرفته نشده عضویت حساب نمی‌شود
+	teams, err := testQueries.ListTeamsByUserID(context.Background(), ListTeamsByUserIDParams{
+		UserID: user.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, teams)
+
+	invites, err := testQueries.ListPendingTeamInvites(context.Background(), ListPendingTeamInvitesParams{
+		UserID: user.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	require.Equal(t, team.ID, invites[0].TeamID)
+
+	total, err := testQueries.CountPendingTeamInvites(context.Background(), CountPendingTeamInvitesParams{
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	member, err := testQueries.AcceptTeamInvite(context.Background(), AcceptTeamInviteParams{
+		TeamID: team.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, member.AcceptedAt.Valid)
+
+	teams, err = testQueries.ListTeamsByUserID(context.Background(), ListTeamsByUserIDParams{
+		UserID: user.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+
+	members, err := testQueries.ListTeamMembers(context.Background(), ListTeamMembersParams{
+		TeamID: team.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// صفحه بعد از اولین عضو فقط عضو دوم را دارد
+	next, err := testQueries.ListTeamMembers(context.Background(), ListTeamMembersParams{
+		TeamID:    team.ID,
+		AfterID:   pgtype.Int4{Int32: members[0].UserID, Valid: true},
+		AfterTime: members[0].CreatedAt,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	require.Equal(t, members[1].UserID, next[0].UserID)
+
+	total, err = testQueries.CountTeamMembers(context.Background(), CountTeamMembersParams{
+		TeamID: team.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+
+	removed, err := testQueries.DeleteTeamMember(context.Background(), DeleteTeamMemberParams{
+		TeamID: team.ID,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+}
+
+func TestListVisibleProjectsByOwnerID(t *testing.T) {
+	owner := createRandomUser(t)
+	team := createRandomTeam(t, owner)
+	teammate := inviteRandomMember(t, team)
+	_, err := testQueries.AcceptTeamInvite(context.Background(), AcceptTeamInviteParams{
+		TeamID: team.ID,
+		UserID: teammate.ID,
+	})
+	require.NoError(t, err)
+	stranger := createRandomUser(t)
+
+	visibilities := []string{"private", "team", "public"}
+	for _, visibility := range visibilities {
+		arg := CreateProjectParams{
+			OwnerUserID: owner.ID,
+			Name:        util.RandomString(10),
+			Visibility:  pgtype.Text{String: visibility, Valid: true},
+		}
+		if visibility == "team" {
+			arg.TeamID = pgtype.Int4{Int32: team.ID, Valid: true}
+		}
+		_, err := testQueries.CreateProject(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name   string
+		viewer User
+		count  int
+	}{
+		{"owner", owner, 3},
+		{"teammate", teammate, 2},
+		{"stranger", stranger, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projects, err := testQueries.ListVisibleProjectsByOwnerID(context.Background(), ListVisibleProjectsByOwnerIDParams{
+				OwnerUserID: owner.ID,
+				ViewerID:    tc.viewer.ID,
+				Sort:        "id",
+				Limit:       10,
+			})
+			require.NoError(t, err)
+			require.Len(t, projects, tc.count)
+
+			total, err := testQueries.CountVisibleProjectsByOwnerID(context.Background(), CountVisibleProjectsByOwnerIDParams{
+				OwnerUserID: owner.ID,
+				ViewerID:    tc.viewer.ID,
+			})
+			require.NoError(t, err)
+			require.Equal(t, int64(tc.count), total)
+		})
+	}
+}
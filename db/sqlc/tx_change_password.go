@@ -0,0 +1,38 @@
+package db
+
+import "context"
+
+// ChangePasswordTxParams contains the input parameters of the change password transaction
+type ChangePasswordTxParams struct {
+	User         User
+	PasswordHash string
+}
+
+// ChangePasswordTx stores the new password hash and revokes all sessions and
+// API keys of the user, since they were made with the old password
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.UpdateUser(ctx, UpdateUserParams{
+			ID:           arg.User.ID,
+			Email:        arg.User.Email,
+			PasswordHash: arg.PasswordHash,
+			FullName:     arg.User.FullName,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.RevokeUserSessions(ctx, arg.User.ID); err != nil {
+			return err
+		}
+
+		_, err = q.RevokeUserApiKeys(ctx, arg.User.ID)
+		return err
+	})
+
+	return user, err
+}
@@ -0,0 +1,25 @@
+package db
+
+import "context"
+
+// CreateProjectDatasetTx uploads a dataset and attaches it to a project in
+// one transaction, so a failed attach doesn't leave a stray dataset behind
+func (store *SQLStore) CreateProjectDatasetTx(ctx context.Context, projectID int32, arg CreateDatasetParams) (Dataset, error) {
+	var dataset Dataset
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		dataset, err = q.CreateDataset(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.AddDatasetToProject(ctx, AddDatasetToProjectParams{
+			ProjectID: projectID,
+			DatasetID: dataset.ID,
+		})
+	})
+
+	return dataset, err
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Team member roles
+const (
+	TeamRoleOwner  = "owner"
+	TeamRoleMember = "member"
+)
+
+// CreateTeamTxParams contains the input parameters of the create team transaction
+type CreateTeamTxParams struct {
+	OwnerUserID int32
+	Name        string
+}
+
+// CreateTeamTxResult is the result of the create team transaction
+type CreateTeamTxResult struct {
+	Team   Team
+	Member TeamMember
+}
+
+// CreateTeamTx creates a team and adds its owner as an accepted member in one transaction
+func (store *SQLStore) CreateTeamTx(ctx context.Context, arg CreateTeamTxParams) (CreateTeamTxResult, error) {
+	var result CreateTeamTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Team, err = q.CreateTeam(ctx, CreateTeamParams{
+			OwnerUserID: arg.OwnerUserID,
+			Name:        arg.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Member, err = q.CreateTeamMember(ctx, CreateTeamMemberParams{
+			TeamID:     result.Team.ID,
+			UserID:     arg.OwnerUserID,
+			Role:       TeamRoleOwner,
+			InvitedBy:  pgtype.Int4{Int32: arg.OwnerUserID, Valid: true},
+			AcceptedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
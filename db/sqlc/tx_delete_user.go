@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DeleteUserTx removes a user together with the rows whose foreign keys
+// don't cascade: their predictions, datasets and models are deleted,
+// predictions of other users lose the reference to them, and the user's
+// logs are kept without the user ID
+func (store *SQLStore) DeleteUserTx(ctx context.Context, userID int32) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return deleteUserData(ctx, q, userID)
+	})
+}
+
+func deleteUserData(ctx context.Context, q *Queries, userID int32) error {
+	owner := pgtype.Int4{Int32: userID, Valid: true}
+
+	if err := q.DeletePredictionsByUserID(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.DetachUserDatasetsFromPredictions(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.DetachUserModelsFromPredictions(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.DeleteDatasetsByUserID(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.DeleteModelsByUserID(ctx, owner); err != nil {
+		return err
+	}
+	if err := q.AnonymizeUserLogs(ctx, owner); err != nil {
+		return err
+	}
+
+	// سشن‌ها، کلیدها، پروژه‌ها و عضویت‌ها با ON DELETE CASCADE حذف می‌شوند
+	return q.DeleteUser(ctx, userID)
+}
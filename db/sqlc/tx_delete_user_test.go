@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUserTx(t *testing.T) {
+	dataset := createRandomDataset(t)
+	user, err := testQueries.GetUserByID(context.Background(), dataset.UserID.Int32)
+	require.NoError(t, err)
+	model := createRandomModel(t, user)
+	project := createRandomProject(t, user.ID)
+	prediction := createRandomPrediction(t, user.ID, dataset.ID, model.ID, project.ID)
+
+	// پیش‌بینی کاربر دیگری که از دیتاست و مدل این کاربر استفاده کرده
+	other := createRandomUser(t)
+	otherProject := createRandomProject(t, other.ID)
+	otherPrediction := createRandomPrediction(t, other.ID, dataset.ID, model.ID, otherProject.ID)
+
+	logEntry, err := testQueries.CreateLog(context.Background(), CreateLogParams{
+		UserID: pgtype.Int4{Int32: user.ID, Valid: true},
+		Action: pgtype.Text{String: "login_succeeded", Valid: true},
+	})
+	require.NoError(t, err)
+
+	err = testStore.DeleteUserTx(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserByID(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testQueries.GetDatasetByID(context.Background(), dataset.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testQueries.GetModelByID(context.Background(), model.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testQueries.GetPredictionByID(context.Background(), prediction.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	kept, err := testQueries.GetPredictionByID(context.Background(), otherPrediction.ID)
+	require.NoError(t, err)
+	require.False(t, kept.DatasetID.Valid)
+	require.False(t, kept.ModelID.Valid)
+
+	// لاگ‌ها باقی می‌مانند ولی دیگر به کاربر اشاره نمی‌کنند
+	anonymized, err := testQueries.GetLogByID(context.Background(), logEntry.ID)
+	require.NoError(t, err)
+	require.False(t, anonymized.UserID.Valid)
+}
+
+func TestSearchUsersWithCounts(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomProject(t, user.ID)
+	createRandomModel(t, user)
+
+	search := pgtype.Text{String: user.Email, Valid: true}
+	rows, err := testQueries.SearchUsersWithCounts(context.Background(), SearchUsersWithCountsParams{
+		Search: search,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, user.ID, rows[0].ID)
+	require.Equal(t, int64(1), rows[0].ProjectCount)
+	require.Equal(t, int64(0), rows[0].DatasetCount)
+	require.Equal(t, int64(1), rows[0].ModelCount)
+
+	total, err := testQueries.CountUsers(context.Background(), CountUsersParams{Search: search})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+}
+
+func TestSearchUsersMatchesWildcardsLiterally(t *testing.T) {
+	prefix := util.RandomString(12)
+	user, err := testQueries.CreateUser(context.Background(), CreateUserParams{
+		Email:    util.RandomEmail(),
+		FullName: pgtype.Text{String: prefix + `%_\` + util.RandomString(6), Valid: true},
+	})
+	require.NoError(t, err)
+
+	total, err := testQueries.CountUsers(context.Background(), CountUsersParams{
+		Search: pgtype.Text{String: prefix + `%_\`, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	// بدون escape، "_" هر نویسه‌ای از جمله "%" را می‌پذیرفت
+	for _, search := range []string{prefix + "_", "%" + prefix} {
+		rows, err := testQueries.SearchUsersWithCounts(context.Background(), SearchUsersWithCountsParams{
+			Search: pgtype.Text{String: search, Valid: true},
+			Limit:  10,
+		})
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NotEqual(t, user.ID, row.ID, search)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package db
+
+import "context"
+
+// EraseUserTx carries out an erasure request: it marks the request completed
+// and deletes the user like DeleteUserTx, all in one transaction
+func (store *SQLStore) EraseUserTx(ctx context.Context, request ErasureRequest) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.CompleteErasureRequest(ctx, request.ID); err != nil {
+			return err
+		}
+
+		// کاربر ممکن است پیش از اجرای درخواست حذف شده باشد
+		if !request.UserID.Valid {
+			return nil
+		}
+		return deleteUserData(ctx, q, request.UserID.Int32)
+	})
+}
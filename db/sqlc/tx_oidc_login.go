@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrEmailNotVerifiedByIssuer is returned when an unknown external identity
+// claims the email of an existing account without the issuer vouching for it
+var ErrEmailNotVerifiedByIssuer = errors.New("email is already registered and not verified by the identity provider")
+
+// ErrTwoFactorAccount is returned when an unknown external identity claims the
+// email of an account with two-factor authentication. Linking it would let the
+// identity provider skip the second factor, so it isn't done automatically.
+var ErrTwoFactorAccount = errors.New("email is already registered to an account with two-factor authentication")
+
+// OIDCLoginTxParams contains the claims of a validated ID token
+type OIDCLoginTxParams struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FullName      string
+}
+
+// OIDCLoginTxResult is the result of the OIDC login transaction
+type OIDCLoginTxResult struct {
+	User     User
+	Identity UserIdentity
+	Created  bool
+}
+
+// OIDCLoginTx finds the user linked to an external identity. A new identity is
+// linked to the account with the same email if the issuer verified that email
+// and the account doesn't use two-factor authentication, otherwise a new user
+// without a password is provisioned.
+func (store *SQLStore) OIDCLoginTx(ctx context.Context, arg OIDCLoginTxParams) (OIDCLoginTxResult, error) {
+	var result OIDCLoginTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Identity, err = q.GetUserIdentity(ctx, GetUserIdentityParams{
+			Issuer:  arg.Issuer,
+			Subject: arg.Subject,
+		})
+		if err == nil {
+			err = q.TouchUserIdentity(ctx, TouchUserIdentityParams{
+				ID:    result.Identity.ID,
+				Email: arg.Email,
+			})
+			if err != nil {
+				return err
+			}
+			result.User, err = q.GetUserByID(ctx, result.Identity.UserID)
+			return err
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		result.User, err = q.GetUserByEmail(ctx, arg.Email)
+		switch {
+		case err == nil:
+			if !arg.EmailVerified {
+				return ErrEmailNotVerifiedByIssuer
+			}
+			userTotp, err := q.GetUserTotp(ctx, result.User.ID)
+			if err == nil && userTotp.EnabledAt.Valid {
+				return ErrTwoFactorAccount
+			}
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+		case errors.Is(err, pgx.ErrNoRows):
+			// کاربر جدید بدون رمز عبور؛ در صورت نیاز می‌تواند با بازیابی رمز، رمز تعیین کند
+			result.User, err = q.CreateUser(ctx, CreateUserParams{
+				Email:    arg.Email,
+				FullName: pgtype.Text{String: arg.FullName, Valid: arg.FullName != ""},
+			})
+			if err != nil {
+				return err
+			}
+			result.Created = true
+		default:
+			return err
+		}
+
+		if arg.EmailVerified && !result.User.EmailVerifiedAt.Valid {
+			result.User, err = q.MarkUserEmailVerified(ctx, result.User.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Identity, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			UserID:  result.User.ID,
+			Issuer:  arg.Issuer,
+			Subject: arg.Subject,
+			Email:   arg.Email,
+		})
+		return err
+	})
+
+	return result, err
+}
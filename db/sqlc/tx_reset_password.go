@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTokenAlreadyUsed is returned when a single-use token was consumed concurrently
+var ErrTokenAlreadyUsed = errors.New("token has already been used")
+
+// ResetPasswordTxParams contains the input parameters of the reset password transaction
+type ResetPasswordTxParams struct {
+	TokenID      int32
+	User         User
+	PasswordHash string
+}
+
+// ResetPasswordTx consumes a reset token, stores the new password hash,
+// clears a forced reset, invalidates the user's other reset tokens and
+// revokes all their sessions
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		used, err := q.UsePasswordResetToken(ctx, arg.TokenID)
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			return ErrTokenAlreadyUsed
+		}
+
+		user, err = q.UpdateUser(ctx, UpdateUserParams{
+			ID:           arg.User.ID,
+			Email:        arg.User.Email,
+			PasswordHash: arg.PasswordHash,
+			FullName:     arg.User.FullName,
+		})
+		if err != nil {
+			return err
+		}
+
+		// اگر مدیر تعیین رمز جدید را الزامی کرده بود، اکنون انجام شده است
+		user, err = q.SetUserPasswordResetRequired(ctx, SetUserPasswordResetRequiredParams{
+			ID:                    arg.User.ID,
+			PasswordResetRequired: false,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := q.InvalidateUserPasswordResetTokens(ctx, arg.User.ID); err != nil {
+			return err
+		}
+
+		_, err = q.RevokeUserSessions(ctx, arg.User.ID)
+		return err
+	})
+
+	return user, err
+}
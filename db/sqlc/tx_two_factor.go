@@ -0,0 +1,44 @@
+package db
+
+import "context"
+
+// EnableTotpTx turns on two-factor authentication for the user and replaces
+// their recovery codes with the given hashes
+func (store *SQLStore) EnableTotpTx(ctx context.Context, userID int32, recoveryCodeHashes []string) (UserTotp, error) {
+	var userTotp UserTotp
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		userTotp, err = q.EnableUserTotp(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+
+		for _, codeHash := range recoveryCodeHashes {
+			err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				UserID:   userID,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return userTotp, err
+}
+
+// DisableTotpTx removes the user's TOTP secret and recovery codes
+func (store *SQLStore) DisableTotpTx(ctx context.Context, userID int32) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+		return q.DeleteUserTotp(ctx, userID)
+	})
+}
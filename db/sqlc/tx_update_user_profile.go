@@ -0,0 +1,26 @@
+package db
+
+import "context"
+
+// UpdateUserProfileTx changes the full name and email of a user. When the
+// email changed, the verification links sent to the old one stop working in
+// the same transaction.
+func (store *SQLStore) UpdateUserProfileTx(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.UpdateUserProfile(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		if !arg.EmailChanged {
+			return nil
+		}
+		return q.InvalidateUserEmailVerificationTokens(ctx, user.ID)
+	})
+
+	return user, err
+}
@@ -0,0 +1,28 @@
+package db
+
+import "context"
+
+// VerifyEmailTx consumes a verification token, marks the user's email as
+// verified and invalidates the user's other verification tokens
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, tokenID int32, userID int32) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		used, err := q.UseEmailVerificationToken(ctx, tokenID)
+		if err != nil {
+			return err
+		}
+		if used == 0 {
+			return ErrTokenAlreadyUsed
+		}
+
+		user, err = q.MarkUserEmailVerified(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.InvalidateUserEmailVerificationTokens(ctx, userID)
+	})
+
+	return user, err
+}
@@ -11,10 +11,32 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users u
+WHERE ($1::text IS NULL
+    OR u.email ILIKE '%' || replace(replace(replace($1, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR u.full_name ILIKE '%' || replace(replace(replace($1, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($2::timestamp IS NULL OR u.created_at >= $2)
+  AND ($3::timestamp IS NULL OR u.created_at < $3)
+`
+
+type CountUsersParams struct {
+	Search        pgtype.Text      `json:"search"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Search, arg.CreatedAfter, arg.CreatedBefore)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (email, password_hash, full_name)
 VALUES ($1, $2, $3)
-RETURNING id, email, password_hash, full_name, created_at
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
 `
 
 type CreateUserParams struct {
@@ -32,6 +54,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.FullName,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
@@ -45,8 +71,56 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const disableUser = `-- name: DisableUser :one
+UPDATE users
+SET disabled_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+func (q *Queries) DisableUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, disableUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
+const enableUser = `-- name: EnableUser :one
+UPDATE users
+SET disabled_at = NULL
+WHERE id = $1
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+func (q *Queries) EnableUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, enableUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, full_name, created_at FROM users WHERE email = $1 LIMIT 1
+SELECT id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required FROM users WHERE email = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -58,12 +132,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.FullName,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, password_hash, full_name, created_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required FROM users WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
@@ -75,12 +153,16 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.PasswordHash,
 		&i.FullName,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, password_hash, full_name, created_at FROM users
+SELECT id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required FROM users
 ORDER BY id
 LIMIT $2 OFFSET $1
 `
@@ -105,6 +187,10 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.PasswordHash,
 			&i.FullName,
 			&i.CreatedAt,
+			&i.EmailVerifiedAt,
+			&i.Role,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
 		); err != nil {
 			return nil, err
 		}
@@ -116,13 +202,192 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, markUserEmailVerified, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET password_hash = $1
+WHERE id = $2 AND password_hash = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string `json:"new_hash"`
+	ID      int32  `json:"id"`
+	OldHash string `json:"old_hash"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchUsersWithCounts = `-- name: SearchUsersWithCounts :many
+SELECT u.id, u.email, u.full_name, u.role, u.email_verified_at, u.disabled_at,
+       u.password_reset_required, u.created_at,
+       (SELECT COUNT(*) FROM projects p WHERE p.owner_user_id = u.id) AS project_count,
+       (SELECT COUNT(*) FROM datasets d WHERE d.user_id = u.id) AS dataset_count,
+       (SELECT COUNT(*) FROM models m WHERE m.user_id = u.id) AS model_count
+FROM users u
+WHERE ($1::text IS NULL
+    OR u.email ILIKE '%' || replace(replace(replace($1, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR u.full_name ILIKE '%' || replace(replace(replace($1, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+  AND ($2::timestamp IS NULL OR u.created_at >= $2)
+  AND ($3::timestamp IS NULL OR u.created_at < $3)
+  AND ($4::int IS NULL OR u.id > $4)
+ORDER BY u.id
+LIMIT $5
+`
+
+type SearchUsersWithCountsParams struct {
+	Search        pgtype.Text      `json:"search"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	AfterID       pgtype.Int4      `json:"after_id"`
+	Limit         int32            `json:"limit"`
+}
+
+type SearchUsersWithCountsRow struct {
+	ID                    int32            `json:"id"`
+	Email                 string           `json:"email"`
+	FullName              pgtype.Text      `json:"full_name"`
+	Role                  string           `json:"role"`
+	EmailVerifiedAt       pgtype.Timestamp `json:"email_verified_at"`
+	DisabledAt            pgtype.Timestamp `json:"disabled_at"`
+	PasswordResetRequired bool             `json:"password_reset_required"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	ProjectCount          int64            `json:"project_count"`
+	DatasetCount          int64            `json:"dataset_count"`
+	ModelCount            int64            `json:"model_count"`
+}
+
+func (q *Queries) SearchUsersWithCounts(ctx context.Context, arg SearchUsersWithCountsParams) ([]SearchUsersWithCountsRow, error) {
+	rows, err := q.db.Query(ctx, searchUsersWithCounts,
+		arg.Search,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersWithCountsRow
+	for rows.Next() {
+		var i SearchUsersWithCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.FullName,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.DisabledAt,
+			&i.PasswordResetRequired,
+			&i.CreatedAt,
+			&i.ProjectCount,
+			&i.DatasetCount,
+			&i.ModelCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserPasswordResetRequired = `-- name: SetUserPasswordResetRequired :one
+UPDATE users
+SET password_reset_required = $2
+WHERE id = $1
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+type SetUserPasswordResetRequiredParams struct {
+	ID                    int32 `json:"id"`
+	PasswordResetRequired bool  `json:"password_reset_required"`
+}
+
+func (q *Queries) SetUserPasswordResetRequired(ctx context.Context, arg SetUserPasswordResetRequiredParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPasswordResetRequired, arg.ID, arg.PasswordResetRequired)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+type SetUserRoleParams struct {
+	ID   int32  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $2,
     password_hash = $3,
     full_name = $4
 WHERE id = $1
-RETURNING id, email, password_hash, full_name, created_at
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
 `
 
 type UpdateUserParams struct {
@@ -146,6 +411,48 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordHash,
 		&i.FullName,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET full_name = $1,
+    email = $2,
+    email_verified_at = CASE WHEN $3::boolean THEN NULL ELSE email_verified_at END
+WHERE id = $4
+RETURNING id, email, password_hash, full_name, created_at, email_verified_at, role, disabled_at, password_reset_required
+`
+
+type UpdateUserProfileParams struct {
+	FullName     pgtype.Text `json:"full_name"`
+	Email        string      `json:"email"`
+	EmailChanged bool        `json:"email_changed"`
+	ID           int32       `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile,
+		arg.FullName,
+		arg.Email,
+		arg.EmailChanged,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FullName,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.Role,
+		&i.DisabledAt,
+		&i.PasswordResetRequired,
 	)
 	return i, err
 }
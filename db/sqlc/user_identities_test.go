@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCLoginTxProvisionsUser(t *testing.T) {
+	arg := OIDCLoginTxParams{
+		Issuer:        "https://idp.example.com",
+		Subject:       util.RandomString(12),
+		Email:         util.RandomEmail(),
+		EmailVerified: true,
+		FullName:      util.RandomName(),
+	}
+
+	result1, err := testStore.OIDCLoginTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result1.Created)
+	require.Equal(t, arg.Email, result1.User.Email)
+	require.Equal(t, arg.FullName, result1.User.FullName.String)
+	require.True(t, result1.User.EmailVerifiedAt.Valid)
+	require.Equal(t, result1.User.ID, result1.Identity.UserID)
+
+	// ورود دوم همان کاربر را برمی‌گرداند
+	result2, err := testStore.OIDCLoginTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result2.Created)
+	require.Equal(t, result1.User.ID, result2.User.ID)
+	require.Equal(t, result1.Identity.ID, result2.Identity.ID)
+}
+
+func TestOIDCLoginTxLinksVerifiedEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	result, err := testStore.OIDCLoginTx(context.Background(), OIDCLoginTxParams{
+		Issuer:        "https://idp.example.com",
+		Subject:       util.RandomString(12),
+		Email:         user.Email,
+		EmailVerified: true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.Created)
+	require.Equal(t, user.ID, result.User.ID)
+
+	identities, err := testQueries.ListUserIdentitiesByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+}
+
+func TestOIDCLoginTxUnverifiedEmailInUse(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testStore.OIDCLoginTx(context.Background(), OIDCLoginTxParams{
+		Issuer:  "https://idp.example.com",
+		Subject: util.RandomString(12),
+		Email:   user.Email,
+	})
+	require.ErrorIs(t, err, ErrEmailNotVerifiedByIssuer)
+}
+
+func TestOIDCLoginTxTwoFactorAccount(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.UpsertUserTotp(context.Background(), UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	_, err = testStore.EnableTotpTx(context.Background(), user.ID, nil)
+	require.NoError(t, err)
+
+	// حساب دارای ورود دو مرحله‌ای خودکار به هویت خارجی متصل نمی‌شود
+	_, err = testStore.OIDCLoginTx(context.Background(), OIDCLoginTxParams{
+		Issuer:        "https://idp.example.com",
+		Subject:       util.RandomString(12),
+		Email:         user.Email,
+		EmailVerified: true,
+	})
+	require.ErrorIs(t, err, ErrTwoFactorAccount)
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUserProfile(t *testing.T) {
+	user, err := testQueries.MarkUserEmailVerified(context.Background(), createRandomUser(t).ID)
+	require.NoError(t, err)
+	require.True(t, user.EmailVerifiedAt.Valid)
+
+	// تغییر نام یا بزرگی حروف ایمیل، تأیید ایمیل را حفظ می‌کند
+	fullName := pgtype.Text{String: util.RandomName(), Valid: true}
+	updated, err := testQueries.UpdateUserProfile(context.Background(), UpdateUserProfileParams{
+		ID:       user.ID,
+		FullName: fullName,
+		Email:    strings.ToUpper(user.Email),
+	})
+	require.NoError(t, err)
+	require.Equal(t, fullName.String, updated.FullName.String)
+	require.Equal(t, strings.ToUpper(user.Email), updated.Email)
+	require.True(t, updated.EmailVerifiedAt.Valid)
+
+	// ایمیل جدید باید دوباره تأیید شود
+	updated, err = testQueries.UpdateUserProfile(context.Background(), UpdateUserProfileParams{
+		ID:           user.ID,
+		FullName:     fullName,
+		Email:        util.RandomEmail(),
+		EmailChanged: true,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, user.Email, updated.Email)
+	require.False(t, updated.EmailVerifiedAt.Valid)
+	require.Equal(t, user.PasswordHash, updated.PasswordHash)
+}
+
+func TestUpdateUserProfileTx(t *testing.T) {
+	user := createRandomUser(t)
+	token := createRandomEmailVerificationToken(t, user)
+
+	// تغییر نام لینک تأیید را باطل نمی‌کند
+	_, err := testStore.UpdateUserProfileTx(context.Background(), UpdateUserProfileParams{
+		ID:    user.ID,
+		Email: user.Email,
+	})
+	require.NoError(t, err)
+
+	token, err = testQueries.GetEmailVerificationTokenByHash(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.False(t, token.UsedAt.Valid)
+
+	updated, err := testStore.UpdateUserProfileTx(context.Background(), UpdateUserProfileParams{
+		ID:           user.ID,
+		Email:        util.RandomEmail(),
+		EmailChanged: true,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, user.Email, updated.Email)
+
+	token, err = testQueries.GetEmailVerificationTokenByHash(context.Background(), token.TokenHash)
+	require.NoError(t, err)
+	require.True(t, token.UsedAt.Valid)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+	apiKey := createRandomApiKey(t, user)
+
+	passwordHash := util.RandomPassword()
+	updated, err := testStore.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		User:         user,
+		PasswordHash: passwordHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, passwordHash, updated.PasswordHash)
+	require.Equal(t, user.Email, updated.Email)
+
+	session, err = testQueries.GetSessionByID(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsRevoked)
+
+	apiKey, err = testQueries.GetApiKeyBySecretHash(context.Background(), apiKey.SecretHash)
+	require.NoError(t, err)
+	require.True(t, apiKey.RevokedAt.Valid)
+}
@@ -102,3 +102,29 @@ func TestDeleteUser(t *testing.T) {
 	_, err = testQueries.GetUserByID(context.Background(), user1.ID)
 	require.Error(t, err)
 }
+
+func TestRehashUserPassword(t *testing.T) {
+	user := createRandomUser(t)
+	newHash := util.RandomString(32)
+
+	// اگر رمز عبور در این فاصله عوض شده باشد، هش جدید نوشته نمی‌شود
+	rows, err := testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHash: newHash,
+		ID:      user.ID,
+		OldHash: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	rows, err = testQueries.RehashUserPassword(context.Background(), RehashUserPasswordParams{
+		NewHash: newHash,
+		ID:      user.ID,
+		OldHash: user.PasswordHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	updated, err := testQueries.GetUserByID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, newHash, updated.PasswordHash)
+}
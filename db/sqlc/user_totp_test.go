@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertUserTotp(t *testing.T) {
+	user := createRandomUser(t)
+
+	totp1, err := testQueries.UpsertUserTotp(context.Background(), UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.False(t, totp1.EnabledAt.Valid)
+
+	// ثبت‌نام دوباره کلید قبلی را جایگزین می‌کند
+	totp2, err := testQueries.UpsertUserTotp(context.Background(), UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, totp1.Secret, totp2.Secret)
+}
+
+func TestEnableAndDisableTotpTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.UpsertUserTotp(context.Background(), UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	codeHash := util.HashSecret(util.RandomString(8))
+	userTotp, err := testStore.EnableTotpTx(context.Background(), user.ID, []string{codeHash})
+	require.NoError(t, err)
+	require.True(t, userTotp.EnabledAt.Valid)
+
+	// هر کد بازیابی فقط یک بار قابل استفاده است
+	arg := UseRecoveryCodeParams{UserID: user.ID, CodeHash: codeHash}
+	used, err := testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	used, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	err = testStore.DisableTotpTx(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserTotp(context.Background(), user.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestUseTotpStep(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.UpsertUserTotp(context.Background(), UpsertUserTotpParams{
+		UserID: user.ID,
+		Secret: util.RandomString(32),
+	})
+	require.NoError(t, err)
+
+	arg := UseTotpStepParams{Step: 100, UserID: user.ID}
+	used, err := testQueries.UseTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	// همان گام یا گام قبلی دوباره پذیرفته نمی‌شود
+	for _, step := range []int64{100, 99} {
+		arg.Step = step
+		used, err = testQueries.UseTotpStep(context.Background(), arg)
+		require.NoError(t, err)
+		require.Zero(t, used)
+	}
+
+	arg.Step = 101
+	used, err = testQueries.UseTotpStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+}
+
+func TestConsumeChallengeToken(t *testing.T) {
+	now := time.Now().UTC()
+	arg := ConsumeChallengeTokenParams{
+		Now:       pgtype.Timestamp{Time: now, Valid: true},
+		TokenID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ExpiresAt: pgtype.Timestamp{Time: now.Add(time.Minute), Valid: true},
+	}
+
+	consumed, err := testQueries.ConsumeChallengeToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), consumed)
+
+	consumed, err = testQueries.ConsumeChallengeToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, consumed)
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "warn")
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept", "request_id", "abc")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "WARN", record["level"])
+	require.Equal(t, "kept", record["msg"])
+	require.Equal(t, "abc", record["request_id"])
+}
+
+func TestTextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatText, "debug")
+	require.NoError(t, err)
+
+	logger.Debug("hello", "user_id", 7)
+	require.Contains(t, buf.String(), "level=DEBUG msg=hello user_id=7")
+}
+
+func TestInvalidLogger(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml", "info")
+	require.ErrorContains(t, err, `invalid log format "xml"`)
+
+	_, err = New(&bytes.Buffer{}, FormatJSON, "loud")
+	require.ErrorContains(t, err, `invalid log level "loud"`)
+}
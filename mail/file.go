@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes every email to a .eml file instead of sending it.
+// It is meant for development and tests, where no mail server is available.
+type FileMailer struct {
+	dir     string
+	from    string
+	counter atomic.Int64
+}
+
+// NewFileMailer creates a new FileMailer writing into dir
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send writes the message to a new file in the mail directory
+func (mailer *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("cannot write email: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%d.eml", time.Now().UnixNano(), mailer.counter.Add(1))
+	path := filepath.Join(mailer.dir, name)
+
+	if err := os.WriteFile(path, format(mailer.from, msg), 0o644); err != nil {
+		return fmt.Errorf("cannot write email: %w", err)
+	}
+
+	slog.Info("Email written to file", "subject", msg.Subject, "to", msg.To, "path", path)
+	return nil
+}
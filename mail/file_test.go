@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	mailer, err := NewFileMailer(dir, "no-reply@sfp.local")
+	require.NoError(t, err)
+
+	msg := Message{
+		To:      []string{util.RandomEmail()},
+		Subject: "Test email",
+		Body:    "Hello\nWorld",
+	}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, mailer.Send(context.Background(), msg))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(content), "To: "+msg.To[0])
+	require.Contains(t, string(content), "Subject: Test email")
+	require.Contains(t, string(content), "Hello\r\nWorld")
+}
+
+func TestFileMailerCanceled(t *testing.T) {
+	mailer, err := NewFileMailer(t.TempDir(), "no-reply@sfp.local")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, mailer.Send(ctx, Message{To: []string{util.RandomEmail()}}), context.Canceled)
+}
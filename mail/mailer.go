@@ -0,0 +1,15 @@
+package mail
+
+import "context"
+
+// Message is a plain-text email
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Send gives up when ctx is done.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
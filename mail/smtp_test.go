@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPMailerGivesUpWithContext(t *testing.T) {
+	// سروری که اتصال را می‌پذیرد ولی هرگز پاسخ نمی‌دهد
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	mailer := NewSMTPMailer(host, portNumber, "", "", "no-reply@sfp.local")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = mailer.Send(ctx, Message{To: []string{util.RandomEmail()}, Subject: "Test email"})
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,207 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bearerAuth is the name of the security scheme of authenticated endpoints
+const bearerAuth = "bearerAuth"
+
+// OneOf is a response that is one of several types
+type OneOf []any
+
+// Endpoint describes one operation of the API
+type Endpoint struct {
+	Method string
+	// Path uses the gin syntax, e.g. /projects/:project_id
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	// Auth means the endpoint needs an access token or an API key
+	Auth bool
+
+	// Query is bound from the query string, Body from a JSON body and Form
+	// from a multipart form that also carries the files named in Files
+	Query any
+	Body  any
+	Form  any
+	Files []string
+
+	// Status is the status of a successful response, 200 when zero
+	Status int
+	// Response is the body of a successful response, sent as JSON unless
+	// ResponseType names another media type
+	Response     any
+	ResponseType string
+	// Headers are sent with a successful response
+	Headers map[string]Header
+}
+
+// Build creates the document of the endpoints. Every operation answers errors
+// with errorType.
+func Build(info Info, servers []Server, errorType any, endpoints []Endpoint) (*Document, error) {
+	r := newReflector()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: r.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "An access token from login, or a personal API key",
+				},
+			},
+		},
+	}
+	errorResponse := Response{
+		Description: "Error",
+		Content:     jsonContent(r.schema(reflect.TypeOf(errorType), false)),
+	}
+
+	operationIDs := map[string]bool{}
+	for _, endpoint := range endpoints {
+		if operationIDs[endpoint.OperationID] {
+			return nil, fmt.Errorf("duplicate operation ID %q", endpoint.OperationID)
+		}
+		operationIDs[endpoint.OperationID] = true
+
+		path := specPath(endpoint.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		if item.Operation(endpoint.Method) != nil {
+			return nil, fmt.Errorf("duplicate endpoint %s %s", endpoint.Method, endpoint.Path)
+		}
+
+		op := r.operation(endpoint)
+		op.Responses["default"] = errorResponse
+		if !item.setOperation(endpoint.Method, op) {
+			return nil, fmt.Errorf("unsupported method %s of %s", endpoint.Method, endpoint.Path)
+		}
+	}
+	return doc, nil
+}
+
+func (r *reflector) operation(endpoint Endpoint) *Operation {
+	op := &Operation{
+		OperationID: endpoint.OperationID,
+		Summary:     endpoint.Summary,
+		Responses:   map[string]Response{},
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+	if endpoint.Auth {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	for _, name := range pathParams(endpoint.Path) {
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = &Schema{Type: "integer", Format: "int32"}
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	if endpoint.Query != nil {
+		query := r.object(reflect.TypeOf(endpoint.Query), true)
+		for _, name := range sortedKeys(query.Properties) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "query",
+				Required: slices.Contains(query.Required, name),
+				Schema:   query.Properties[name],
+			})
+		}
+	}
+
+	switch {
+	case endpoint.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(r.schema(reflect.TypeOf(endpoint.Body), true)),
+		}
+	case endpoint.Form != nil:
+		form := r.object(reflect.TypeOf(endpoint.Form), true)
+		for _, name := range endpoint.Files {
+			form.Properties[name] = &Schema{Type: "string", Format: "binary"}
+			form.Required = append(form.Required, name)
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := Response{Description: http.StatusText(status), Headers: endpoint.Headers}
+	switch {
+	case endpoint.ResponseType != "":
+		response.Content = map[string]MediaType{endpoint.ResponseType: {}}
+	case endpoint.Response != nil:
+		response.Content = jsonContent(r.response(endpoint.Response))
+	}
+	op.Responses[strconv.Itoa(status)] = response
+	return op
+}
+
+func (r *reflector) response(value any) *Schema {
+	oneOf, ok := value.(OneOf)
+	if !ok {
+		return r.schema(reflect.TypeOf(value), false)
+	}
+	schema := &Schema{}
+	for _, value := range oneOf {
+		schema.OneOf = append(schema.OneOf, r.schema(reflect.TypeOf(value), false))
+	}
+	return schema
+}
+
+// specPath converts the gin parameters of path to OpenAPI templates, e.g.
+// /projects/:project_id to /projects/{project_id}
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
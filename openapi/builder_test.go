@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+type testError struct {
+	Message string `json:"error"`
+}
+
+type createItemRequest struct {
+	Name  string   `json:"name" binding:"required,min=3,max=50"`
+	Kind  string   `json:"kind" binding:"omitempty,oneof=a b"`
+	Tags  []string `json:"tags" binding:"max=5,dive,min=1"`
+	Email *string  `json:"email" binding:"omitempty,email"`
+}
+
+type listItemsRequest struct {
+	PageSize int32  `form:"page_size,default=20" binding:"min=1,max=100"`
+	Search   string `form:"search"`
+}
+
+type item struct {
+	ID        int32            `json:"id"`
+	Note      pgtype.Text      `json:"note"`
+	CreatedAt time.Time        `json:"created_at"`
+	Parent    *item            `json:"parent"`
+	Extra     string           `json:"extra,omitempty"`
+	Content   []byte           `json:"content"`
+	Labels    map[string]int64 `json:"labels"`
+}
+
+func buildTestDocument(t *testing.T) *Document {
+	doc, err := Build(Info{Title: "test", Version: "1"}, nil, testError{}, []Endpoint{
+		{Method: http.MethodPost, Path: "/items", OperationID: "createItem", Auth: true, Body: createItemRequest{}, Status: http.StatusCreated, Response: item{}},
+		{Method: http.MethodGet, Path: "/items", OperationID: "listItems", Query: listItemsRequest{}, Response: []item{},
+			Headers: map[string]Header{"X-Total-Count": {Schema: &Schema{Type: "integer"}}}},
+		{Method: http.MethodGet, Path: "/items/:item_id/files/:name", OperationID: "getItemFile", ResponseType: "application/octet-stream"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, Version, doc.OpenAPI)
+	return doc
+}
+
+func TestBuildRequestBody(t *testing.T) {
+	doc := buildTestDocument(t)
+
+	op := doc.Paths["/items"].Post
+	require.NotNil(t, op)
+	require.Equal(t, []map[string][]string{{bearerAuth: {}}}, op.Security)
+	require.Equal(t, "#/components/schemas/CreateItemRequest", op.RequestBody.Content["application/json"].Schema.Ref)
+	require.Contains(t, op.Responses, "201")
+	require.Equal(t, "#/components/schemas/TestError", op.Responses["default"].Content["application/json"].Schema.Ref)
+
+	request := doc.Components.Schemas["CreateItemRequest"]
+	require.Equal(t, []string{"name"}, request.Required)
+	require.Equal(t, 3, *request.Properties["name"].MinLength)
+	require.Equal(t, 50, *request.Properties["name"].MaxLength)
+	require.Equal(t, []string{"a", "b"}, request.Properties["kind"].Enum)
+	require.Equal(t, 5, *request.Properties["tags"].MaxItems)
+	require.Equal(t, 1, *request.Properties["tags"].Items.MinLength)
+	require.Equal(t, []string{"string", "null"}, request.Properties["email"].Type)
+	require.Equal(t, "email", request.Properties["email"].Format)
+}
+
+func TestBuildQueryAndPath(t *testing.T) {
+	doc := buildTestDocument(t)
+
+	params := doc.Paths["/items"].Get.Parameters
+	require.Len(t, params, 2)
+	require.Equal(t, "page_size", params[0].Name)
+	require.Equal(t, "query", params[0].In)
+	require.EqualValues(t, 20, params[0].Schema.Default)
+	require.EqualValues(t, 100, *params[0].Schema.Maximum)
+	require.Equal(t, "search", params[1].Name)
+
+	op := doc.Paths["/items/{item_id}/files/{name}"].Get
+	require.NotNil(t, op)
+	require.Equal(t, "integer", op.Parameters[0].Schema.Type)
+	require.Equal(t, "string", op.Parameters[1].Schema.Type)
+	require.Contains(t, op.Responses["200"].Content, "application/octet-stream")
+}
+
+func TestBuildResponse(t *testing.T) {
+	doc := buildTestDocument(t)
+
+	response := doc.Paths["/items"].Get.Responses["200"]
+	require.Contains(t, response.Headers, "X-Total-Count")
+	require.Empty(t, doc.Paths["/items"].Post.Responses["201"].Headers)
+
+	list := response.Content["application/json"].Schema
+	require.Equal(t, []string{"array", "null"}, list.Type)
+	require.Equal(t, "#/components/schemas/Item", list.Items.Ref)
+
+	schema := doc.Components.Schemas["Item"]
+	require.Equal(t, []string{"id", "note", "created_at", "parent", "content", "labels"}, schema.Required)
+	require.Equal(t, []string{"string", "null"}, schema.Properties["note"].Type)
+	require.Equal(t, "date-time", schema.Properties["created_at"].Format)
+	require.Equal(t, "#/components/schemas/Item", schema.Properties["parent"].AnyOf[0].Ref)
+	require.Equal(t, "base64", schema.Properties["content"].ContentEncoding)
+	require.Equal(t, "int64", schema.Properties["labels"].AdditionalProperties.Format)
+}
+
+func TestBuildDuplicates(t *testing.T) {
+	_, err := Build(Info{}, nil, testError{}, []Endpoint{
+		{Method: http.MethodGet, Path: "/a", OperationID: "a"},
+		{Method: http.MethodGet, Path: "/b", OperationID: "a"},
+	})
+	require.ErrorContains(t, err, `duplicate operation ID "a"`)
+
+	_, err = Build(Info{}, nil, testError{}, []Endpoint{
+		{Method: http.MethodGet, Path: "/a/:id", OperationID: "a"},
+		{Method: http.MethodGet, Path: "/a/:id", OperationID: "b"},
+	})
+	require.ErrorContains(t, err, "duplicate endpoint GET /a/:id")
+}
@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// pgtypeSchemas describes the pgtype values that appear in responses. They
+// marshal to null when not valid; timestamps have no time zone.
+var pgtypeSchemas = map[string]func() *Schema{
+	"Text":      func() *Schema { return &Schema{Type: []string{"string", "null"}} },
+	"Int4":      func() *Schema { return &Schema{Type: []string{"integer", "null"}, Format: "int32"} },
+	"Int8":      func() *Schema { return &Schema{Type: []string{"integer", "null"}, Format: "int64"} },
+	"Bool":      func() *Schema { return &Schema{Type: []string{"boolean", "null"}} },
+	"Float8":    func() *Schema { return &Schema{Type: []string{"number", "null"}, Format: "double"} },
+	"Timestamp": func() *Schema { return &Schema{Type: []string{"string", "null"}} },
+}
+
+const pgtypePath = "github.com/jackc/pgx/v5/pgtype"
+
+// reflector turns Go types into schemas. Named structs become components and
+// are referenced, so each is described once.
+type reflector struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newReflector() *reflector {
+	return &reflector{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// schema describes t. In requests, fields are required when their binding
+// tag says so; in responses, every field without omitempty is always sent.
+func (r *reflector) schema(t reflect.Type, request bool) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.PkgPath() == pgtypePath && pgtypeSchemas[t.Name()] != nil:
+		return pgtypeSchemas[t.Name()]()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(r.schema(t.Elem(), request))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		schema := &Schema{Type: "array", Items: r.schema(t.Elem(), request)}
+		// یک slice خالی (nil) به صورت null ارسال می‌شود
+		if t.Kind() == reflect.Slice && !request {
+			schema = nullable(schema)
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem(), request)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t, request)
+		}
+		return &Schema{Ref: "#/components/schemas/" + r.component(t, request)}
+	default:
+		// interface و انواع دیگر: هر مقدار JSON
+		return &Schema{}
+	}
+}
+
+// component adds the schema of a named struct to the components and returns
+// its name
+func (r *reflector) component(t reflect.Type, request bool) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := exported(t.Name())
+	if _, taken := r.schemas[name]; taken {
+		// نام یکسان در دو پکیج، مثل db.Project و api.Project
+		name = exported(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+	}
+	r.names[t] = name
+	r.schemas[name] = nil // جلوگیری از بازگشت بی‌پایان در نوع‌های خودارجاع
+	r.schemas[name] = r.object(t, request)
+	return name
+}
+
+// object describes the fields of a struct as they appear in JSON, or in a
+// query string or form when they only have a form tag
+func (r *reflector) object(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, field := range fields(t) {
+		name, omitempty := fieldName(field)
+
+		property := r.schema(field.Type, request)
+		rules := applyBinding(property, field)
+		property.Default = defaultValue(field)
+		schema.Properties[name] = property
+
+		if request && rules.required || !request && !omitempty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// fields lists the fields of t that are encoded, including those of
+// embedded structs
+func fields(t reflect.Type) []reflect.StructField {
+	var result []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			result = append(result, fields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("uri") != "" {
+			continue
+		}
+		if name, _ := fieldName(field); name == "-" {
+			continue
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// fieldName returns the name of the field in JSON, or in the query string or
+// form for request fields bound with a form tag
+func fieldName(field reflect.StructField) (name string, omitempty bool) {
+	for _, key := range []string{"json", "form"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		return name, strings.Contains(","+options+",", ",omitempty,")
+	}
+	return field.Name, false
+}
+
+// defaultValue reads the default of a form field, e.g. form:"page_size,default=20"
+func defaultValue(field reflect.StructField) any {
+	_, options, _ := strings.Cut(field.Tag.Get("form"), ",")
+	for _, option := range strings.Split(options, ",") {
+		value, ok := strings.CutPrefix(option, "default=")
+		if !ok {
+			continue
+		}
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+		return value
+	}
+	return nil
+}
+
+// bindingRules is what the binding tag of a field says about its value
+type bindingRules struct {
+	required bool
+}
+
+// applyBinding copies the validation rules of the binding tag into the
+// schema. Rules after dive apply to the items of a slice.
+func applyBinding(schema *Schema, field reflect.StructField) bindingRules {
+	var rules bindingRules
+	target := schema
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	kind := fieldType.Kind()
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if target == schema {
+				rules.required = true
+			}
+		case "dive":
+			if target.Items == nil {
+				return rules
+			}
+			target, kind = target.Items, fieldType.Elem().Kind()
+		case "email":
+			target.Format = "email"
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "min", "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setLimit(target, kind, name == "min", limit)
+		}
+	}
+	return rules
+}
+
+func setLimit(schema *Schema, kind reflect.Kind, min bool, limit int) {
+	switch kind {
+	case reflect.String:
+		if min {
+			schema.MinLength = &limit
+		} else {
+			schema.MaxLength = &limit
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if min {
+			schema.MinItems = &limit
+		} else {
+			schema.MaxItems = &limit
+		}
+	default:
+		value := float64(limit)
+		if min {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
+
+// nullable allows null in addition to the values of schema
+func nullable(schema *Schema) *Schema {
+	switch t := schema.Type.(type) {
+	case string:
+		schema.Type = []string{t, "null"}
+		return schema
+	case nil:
+		if schema.Ref != "" {
+			return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+		}
+	}
+	return schema
+}
+
+// exported capitalizes the first letter of a Go type name
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,92 @@
+package sso
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/faezefz/SFP_website/sso/ssotest"
+	"github.com/faezefz/SFP_website/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURL = "http://localhost:8080/auth/oidc/callback"
+
+func newTestProvider(t *testing.T) (*Provider, *ssotest.Server) {
+	idp, err := ssotest.NewServer("sfp", util.RandomString(32))
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+
+	provider, err := NewProvider(context.Background(), idp.URL, idp.ClientID, idp.ClientSecret, testRedirectURL, []string{"email", "profile"})
+	require.NoError(t, err)
+
+	return provider, idp
+}
+
+// authorize follows the login redirect like a browser and returns the callback query
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	rsp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusFound, rsp.StatusCode)
+
+	callback, err := url.Parse(rsp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query()
+}
+
+func TestExchange(t *testing.T) {
+	provider, idp := newTestProvider(t)
+	user := ssotest.User{
+		Subject:       util.RandomString(12),
+		Email:         util.RandomEmail(),
+		EmailVerified: true,
+		Name:          util.RandomName(),
+	}
+	idp.SetUser(user)
+
+	state, nonce, verifier := util.RandomString(16), util.RandomString(16), NewVerifier()
+	callback := authorize(t, provider.AuthCodeURL(state, nonce, verifier))
+	require.Equal(t, state, callback.Get("state"))
+
+	claims, err := provider.Exchange(context.Background(), callback.Get("code"), nonce, verifier)
+	require.NoError(t, err)
+	require.Equal(t, idp.URL, claims.Issuer)
+	require.Equal(t, user.Subject, claims.Subject)
+	require.Equal(t, user.Email, claims.Email)
+	require.True(t, claims.EmailVerified)
+	require.Equal(t, user.Name, claims.Name)
+
+	// کد مصرف‌شده دوباره پذیرفته نمی‌شود
+	_, err = provider.Exchange(context.Background(), callback.Get("code"), nonce, verifier)
+	require.Error(t, err)
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	provider, idp := newTestProvider(t)
+	idp.SetUser(ssotest.User{Subject: util.RandomString(12), Email: util.RandomEmail()})
+
+	nonce := util.RandomString(16)
+	callback := authorize(t, provider.AuthCodeURL(util.RandomString(16), nonce, NewVerifier()))
+
+	_, err := provider.Exchange(context.Background(), callback.Get("code"), nonce, NewVerifier())
+	require.Error(t, err)
+}
+
+func TestExchangeWrongNonce(t *testing.T) {
+	provider, idp := newTestProvider(t)
+	idp.SetUser(ssotest.User{Subject: util.RandomString(12), Email: util.RandomEmail()})
+
+	verifier := NewVerifier()
+	callback := authorize(t, provider.AuthCodeURL(util.RandomString(16), util.RandomString(16), verifier))
+
+	_, err := provider.Exchange(context.Background(), callback.Get("code"), util.RandomString(16), verifier)
+	require.ErrorIs(t, err, ErrNonceMismatch)
+}
@@ -0,0 +1,146 @@
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Policy decides how long a key has to wait after a number of failures
+type Policy struct {
+	// FreeFailures are allowed without any delay
+	FreeFailures int
+	// BaseDelay is the wait after the first failure past FreeFailures; it
+	// doubles with every further failure
+	BaseDelay time.Duration
+	// MaxFailures locks the key out for LockoutDuration
+	MaxFailures     int
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered
+	Window time.Duration
+}
+
+// Delay returns how long to wait after the given number of failures
+func (policy Policy) Delay(failures int) time.Duration {
+	if failures >= policy.MaxFailures {
+		return policy.LockoutDuration
+	}
+	if failures <= policy.FreeFailures {
+		return 0
+	}
+
+	delay := policy.BaseDelay
+	for i := policy.FreeFailures + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= policy.LockoutDuration {
+			return policy.LockoutDuration
+		}
+	}
+	return delay
+}
+
+// Limiter applies a Policy to the records of a Store
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+// NewLimiter creates a Limiter
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy, now: time.Now}
+}
+
+// Wait returns how long the key still has to wait before its next attempt;
+// zero means the attempt may go ahead
+func (limiter *Limiter) Wait(ctx context.Context, key string) (time.Duration, error) {
+	record, err := limiter.store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return limiter.wait(record, limiter.now()), nil
+}
+
+func (limiter *Limiter) wait(record Record, now time.Time) time.Duration {
+	if record.Failures == 0 || record.LastFailure.Before(now.Add(-limiter.policy.Window)) {
+		return 0
+	}
+
+	until := record.LastFailure.Add(limiter.policy.Delay(record.Failures))
+	if !until.After(now) {
+		return 0
+	}
+	return until.Sub(now)
+}
+
+// Reservation is an attempt counted by Reserve
+type Reservation struct {
+	// Wait is how long the key has to wait; the attempt must be refused when
+	// it isn't zero. Refused attempts aren't counted.
+	Wait time.Duration
+	// Failures is the number of attempts counted in the window, this one included
+	Failures int
+
+	// reservedAt is when the attempt was counted and previous the last
+	// failure before it; Release puts previous back
+	reservedAt time.Time
+	previous   time.Time
+}
+
+// Reserve counts an attempt for the key before it is made, so parallel
+// attempts can't all pass the check before any of them is recorded. Release
+// the reservation if the attempt doesn't fail, or Reset the key.
+func (limiter *Limiter) Reserve(ctx context.Context, key string) (Reservation, error) {
+	record, err := limiter.store.Get(ctx, key)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	now := limiter.now()
+	if wait := limiter.wait(record, now); wait > 0 {
+		return Reservation{Wait: wait, Failures: record.Failures}, nil
+	}
+	seen, previous := record.Failures, record.LastFailure
+	if record.LastFailure.Before(now.Add(-limiter.policy.Window)) {
+		seen = 0
+	}
+
+	record, err = limiter.store.AddFailure(ctx, key, now, now.Add(-limiter.policy.Window))
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	// تلاش‌هایی که بین بررسی و ثبت این تلاش شمرده شده‌اند، تأخیر خودشان را دارند
+	if record.Failures-1 != seen {
+		if wait := limiter.policy.Delay(record.Failures - 1); wait > 0 {
+			// زمان آخرین شکست آن‌ها معلوم نیست، پس now می‌ماند
+			if err := limiter.store.RemoveFailure(ctx, key, now, now); err != nil {
+				return Reservation{}, err
+			}
+			return Reservation{Wait: wait, Failures: record.Failures - 1}, nil
+		}
+	}
+	return Reservation{Failures: record.Failures, reservedAt: now, previous: previous}, nil
+}
+
+// Release takes back a reserved attempt that didn't fail. The wait of the
+// key is then what it was before the reservation.
+func (limiter *Limiter) Release(ctx context.Context, key string, reservation Reservation) error {
+	return limiter.store.RemoveFailure(ctx, key, reservation.reservedAt, reservation.previous)
+}
+
+// StartsLockout tells whether the reserved attempt locks the key out if it fails
+func (limiter *Limiter) StartsLockout(reservation Reservation) bool {
+	return reservation.Wait == 0 && reservation.Failures == limiter.policy.MaxFailures
+}
+
+// Fail records a failed attempt for the key
+func (limiter *Limiter) Fail(ctx context.Context, key string) error {
+	now := limiter.now()
+	_, err := limiter.store.AddFailure(ctx, key, now, now.Add(-limiter.policy.Window))
+	return err
+}
+
+// Reset clears the failures of the key, e.g. after a successful login
+func (limiter *Limiter) Reset(ctx context.Context, key string) error {
+	return limiter.store.Reset(ctx, key)
+}
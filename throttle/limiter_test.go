@@ -0,0 +1,192 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	FreeFailures:    2,
+	BaseDelay:       time.Second,
+	MaxFailures:     6,
+	LockoutDuration: time.Minute,
+	Window:          time.Hour,
+}
+
+func TestPolicyDelay(t *testing.T) {
+	expected := []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Minute, time.Minute}
+	for failures, delay := range expected {
+		require.Equal(t, delay, testPolicy.Delay(failures), "failures=%d", failures)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	key := "email:user@example.com"
+	for i := 0; i < testPolicy.FreeFailures; i++ {
+		require.NoError(t, limiter.Fail(ctx, key))
+		wait, err := limiter.Wait(ctx, key)
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+
+	require.NoError(t, limiter.Fail(ctx, key))
+	wait, err := limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+
+	// بعد از گذشت زمان انتظار، تلاش بعدی مجاز است
+	now = now.Add(time.Second)
+	wait, err = limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	for i := 0; i < testPolicy.MaxFailures; i++ {
+		require.NoError(t, limiter.Fail(ctx, key))
+	}
+	wait, err = limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, testPolicy.LockoutDuration, wait)
+
+	require.NoError(t, limiter.Reset(ctx, key))
+	wait, err = limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestLimiterForgetsOldFailures(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	key := "ip:10.0.0.1"
+	for i := 0; i < testPolicy.MaxFailures; i++ {
+		require.NoError(t, limiter.Fail(ctx, key))
+	}
+
+	now = now.Add(testPolicy.Window + time.Second)
+	wait, err := limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	// شمارش پس از پایان پنجره از نو شروع می‌شود
+	require.NoError(t, limiter.Fail(ctx, key))
+	record, err := limiter.store.Get(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 1, record.Failures)
+}
+
+func TestLimiterReserve(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	key := "email:user@example.com"
+	var last Reservation
+	for i := 1; i <= testPolicy.FreeFailures+1; i++ {
+		reservation, err := limiter.Reserve(ctx, key)
+		require.NoError(t, err)
+		require.Zero(t, reservation.Wait)
+		require.Equal(t, i, reservation.Failures)
+		last = reservation
+	}
+
+	// تلاش رد شده شمرده نمی‌شود
+	reservation, err := limiter.Reserve(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, reservation.Wait)
+	require.Equal(t, testPolicy.FreeFailures+1, reservation.Failures)
+
+	// تلاشی که شکست نخورده پس گرفته می‌شود
+	require.NoError(t, limiter.Release(ctx, key, last))
+	reservation, err = limiter.Reserve(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, reservation.Wait)
+	require.Equal(t, testPolicy.FreeFailures+1, reservation.Failures)
+}
+
+func TestLimiterReleaseDoesNotExtendWait(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+	limiter.now = func() time.Time { return now }
+
+	key := "ip:10.0.0.1"
+	for i := 0; i < testPolicy.FreeFailures+1; i++ {
+		require.NoError(t, limiter.Fail(ctx, key))
+	}
+
+	// پس از پایان انتظار، تلاشی که موفق شد زمان انتظار را از نو شروع نمی‌کند
+	now = now.Add(time.Second)
+	reservation, err := limiter.Reserve(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, reservation.Wait)
+
+	now = now.Add(time.Millisecond)
+	require.NoError(t, limiter.Release(ctx, key, reservation))
+	wait, err := limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	// شکستی که بعد از رزرو ثبت شده زمان خودش را نگه می‌دارد
+	reservation, err = limiter.Reserve(ctx, key)
+	require.NoError(t, err)
+	require.Zero(t, reservation.Wait)
+	now = now.Add(time.Millisecond)
+	require.NoError(t, limiter.Fail(ctx, key))
+	require.NoError(t, limiter.Release(ctx, key, reservation))
+	wait, err = limiter.Wait(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, wait)
+}
+
+func TestLimiterReserveParallel(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+
+	const attempts = 50
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := limiter.Reserve(ctx, "ip:10.0.0.1")
+			require.NoError(t, err)
+			if reservation.Wait == 0 {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// مانند تلاش‌های پشت سر هم، فقط تلاش‌های رایگان و یک تلاش بعد از آن‌ها مجازند
+	require.EqualValues(t, testPolicy.FreeFailures+1, allowed.Load())
+
+	record, err := limiter.store.Get(ctx, "ip:10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, testPolicy.FreeFailures+1, record.Failures)
+}
+
+func TestLimiterStartsLockout(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(), testPolicy)
+
+	require.False(t, limiter.StartsLockout(Reservation{Failures: testPolicy.MaxFailures - 1}))
+	require.True(t, limiter.StartsLockout(Reservation{Failures: testPolicy.MaxFailures}))
+	require.False(t, limiter.StartsLockout(Reservation{Wait: time.Minute, Failures: testPolicy.MaxFailures}))
+}
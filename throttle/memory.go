@@ -0,0 +1,78 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory. It is the default and is
+// fine for a single instance; use PostgresStore when running several.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Get returns the record of a key
+func (store *MemoryStore) Get(ctx context.Context, key string) (Record, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.records[key], nil
+}
+
+// AddFailure counts one more failure for a key
+func (store *MemoryStore) AddFailure(ctx context.Context, key string, now, windowStart time.Time) (Record, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// پاک کردن رکوردهای قدیمی تا حافظه بی‌نهایت رشد نکند
+	if store.lastSweep.Before(windowStart) {
+		for k, record := range store.records {
+			if record.LastFailure.Before(windowStart) {
+				delete(store.records, k)
+			}
+		}
+		store.lastSweep = now
+	}
+
+	record := store.records[key]
+	if record.LastFailure.Before(windowStart) {
+		record.Failures = 0
+	}
+	record.Failures++
+	record.LastFailure = now
+	store.records[key] = record
+
+	return record, nil
+}
+
+// RemoveFailure takes back one failure of a key
+func (store *MemoryStore) RemoveFailure(ctx context.Context, key string, failedAt, previous time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if record, ok := store.records[key]; ok && record.Failures > 0 {
+		record.Failures--
+		if record.LastFailure.Equal(failedAt) {
+			record.LastFailure = previous
+		}
+		store.records[key] = record
+	}
+	return nil
+}
+
+// Reset forgets the failures of a key
+func (store *MemoryStore) Reset(ctx context.Context, key string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.records, key)
+	return nil
+}
@@ -0,0 +1,68 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresStore keeps records in the login_attempts table, so every
+// instance behind a load balancer sees the same counts
+type PostgresStore struct {
+	queries db.Querier
+}
+
+// NewPostgresStore creates a PostgresStore
+func NewPostgresStore(queries db.Querier) *PostgresStore {
+	return &PostgresStore{queries: queries}
+}
+
+// Get returns the record of a key
+func (store *PostgresStore) Get(ctx context.Context, key string) (Record, error) {
+	attempt, err := store.queries.GetLoginAttempt(ctx, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, nil
+		}
+		return Record{}, err
+	}
+	return newRecord(attempt), nil
+}
+
+// AddFailure counts one more failure for a key
+func (store *PostgresStore) AddFailure(ctx context.Context, key string, now, windowStart time.Time) (Record, error) {
+	attempt, err := store.queries.AddLoginFailure(ctx, db.AddLoginFailureParams{
+		AttemptKey:  key,
+		FailedAt:    pgtype.Timestamp{Time: now.UTC(), Valid: true},
+		WindowStart: pgtype.Timestamp{Time: windowStart.UTC(), Valid: true},
+	})
+	if err != nil {
+		return Record{}, err
+	}
+	return newRecord(attempt), nil
+}
+
+// RemoveFailure takes back one failure of a key
+func (store *PostgresStore) RemoveFailure(ctx context.Context, key string, failedAt, previous time.Time) error {
+	return store.queries.RemoveLoginFailure(ctx, db.RemoveLoginFailureParams{
+		AttemptKey:        key,
+		FailedAt:          pgtype.Timestamp{Time: failedAt.UTC(), Valid: true},
+		PreviousFailureAt: pgtype.Timestamp{Time: previous.UTC(), Valid: true},
+	})
+}
+
+// Reset forgets the failures of a key
+func (store *PostgresStore) Reset(ctx context.Context, key string) error {
+	return store.queries.DeleteLoginAttempt(ctx, key)
+}
+
+func newRecord(attempt db.LoginAttempt) Record {
+	return Record{
+		Failures:    int(attempt.Failures),
+		LastFailure: attempt.LastFailureAt.Time,
+	}
+}
@@ -0,0 +1,30 @@
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Record is the failure history of one key (an IP address or an email)
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store keeps failure records; it must be safe for concurrent use
+type Store interface {
+	// Get returns the record of a key, or a zero Record if there is none
+	Get(ctx context.Context, key string) (Record, error)
+
+	// AddFailure counts one more failure at now and returns the updated record.
+	// Failures older than windowStart are forgotten first.
+	AddFailure(ctx context.Context, key string, now, windowStart time.Time) (Record, error)
+
+	// RemoveFailure takes back the failure counted at failedAt, if the key has
+	// any. When it is still the last failure, the last failure goes back to
+	// previous, so the taken back failure doesn't extend the wait.
+	RemoveFailure(ctx context.Context, key string, failedAt, previous time.Time) error
+
+	// Reset forgets the failures of a key
+	Reset(ctx context.Context, key string) error
+}
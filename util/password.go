@@ -0,0 +1,178 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrMismatchedPassword  = errors.New("password does not match")
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// PasswordHasher is an interface for hashing and checking passwords.
+// Hashes are self-describing: they name their algorithm and parameters.
+type PasswordHasher interface {
+	// Hash hashes the password with the configured algorithm
+	Hash(password string) (string, error)
+
+	// Verify checks the password against a hash made by any supported algorithm
+	Verify(hash, password string) error
+
+	// NeedsRehash reports whether the hash was made with another algorithm
+	// or other parameters than the configured ones
+	NeedsRehash(hash string) bool
+}
+
+// Argon2idParams are the cost parameters of argon2id; Memory is in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the recommendation of RFC 9106 for
+// memory-constrained environments
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type passwordHasher struct {
+	algorithm  string
+	argon2id   Argon2idParams
+	bcryptCost int
+}
+
+// NewPasswordHasher creates a hasher that hashes new passwords with algorithm
+// and still verifies hashes made by the other supported algorithms
+func NewPasswordHasher(algorithm string, argon2id Argon2idParams, bcryptCost int) (PasswordHasher, error) {
+	switch algorithm {
+	case PasswordAlgorithmArgon2id:
+		if argon2id.Memory == 0 || argon2id.Iterations == 0 || argon2id.Parallelism == 0 ||
+			argon2id.SaltLength == 0 || argon2id.KeyLength == 0 {
+			return nil, fmt.Errorf("invalid argon2id parameters: %+v", argon2id)
+		}
+	case PasswordAlgorithmBcrypt:
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d: must be between %d and %d", bcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm %q", algorithm)
+	}
+
+	return &passwordHasher{
+		algorithm:  algorithm,
+		argon2id:   argon2id,
+		bcryptCost: bcryptCost,
+	}, nil
+}
+
+func (hasher *passwordHasher) Hash(password string) (string, error) {
+	if hasher.algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), hasher.bcryptCost)
+		return string(hash), err
+	}
+
+	p := hasher.argon2id
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return encodeArgon2idHash(p, salt, key), nil
+}
+
+func (hasher *passwordHasher) Verify(hash, password string) error {
+	switch {
+	case isBcryptHash(hash):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrMismatchedPassword
+			}
+			return ErrUnknownPasswordHash
+		}
+		return nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		p, salt, key, err := decodeArgon2idHash(hash)
+		if err != nil {
+			return err
+		}
+		other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+		if subtle.ConstantTimeCompare(key, other) != 1 {
+			return ErrMismatchedPassword
+		}
+		return nil
+	default:
+		// مثلاً حساب‌های ساخته‌شده با SSO که رمز عبور ندارند
+		return ErrUnknownPasswordHash
+	}
+}
+
+func (hasher *passwordHasher) NeedsRehash(hash string) bool {
+	if hasher.algorithm == PasswordAlgorithmBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != hasher.bcryptCost
+	}
+
+	p, _, _, err := decodeArgon2idHash(hash)
+	return err != nil || p != hasher.argon2id
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// encodeArgon2idHash uses the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func encodeArgon2idHash(p Argon2idParams, salt, key []byte) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2idHash(hash string) (p Argon2idParams, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnknownPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrUnknownPasswordHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, ErrUnknownPasswordHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, nil, nil, ErrUnknownPasswordHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
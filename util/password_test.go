@@ -0,0 +1,108 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keeps the tests fast
+var testArgon2idParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2idPassword(t *testing.T) {
+	hasher, err := NewPasswordHasher(PasswordAlgorithmArgon2id, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	password := RandomPassword()
+	hash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	require.NoError(t, hasher.Verify(hash, password))
+	require.ErrorIs(t, hasher.Verify(hash, RandomPassword()), ErrMismatchedPassword)
+	require.False(t, hasher.NeedsRehash(hash))
+
+	// هش هر رمز با salt متفاوتی ساخته می‌شود
+	other, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other)
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	password := RandomPassword()
+	legacy, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	hasher, err := NewPasswordHasher(PasswordAlgorithmArgon2id, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	// bcrypt hashes are still accepted, but should be upgraded
+	require.NoError(t, hasher.Verify(string(legacy), password))
+	require.True(t, hasher.NeedsRehash(string(legacy)))
+
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+	strongerHasher, err := NewPasswordHasher(PasswordAlgorithmArgon2id, stronger, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NoError(t, strongerHasher.Verify(hash, password))
+	require.True(t, strongerHasher.NeedsRehash(hash))
+
+	bcryptHasher, err := NewPasswordHasher(PasswordAlgorithmBcrypt, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+	require.False(t, bcryptHasher.NeedsRehash(string(legacy)))
+	require.True(t, bcryptHasher.NeedsRehash(hash))
+}
+
+func TestVerifyUnknownPasswordHash(t *testing.T) {
+	hasher, err := NewPasswordHasher(PasswordAlgorithmArgon2id, testArgon2idParams, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1024$abc$def", "$argon2i$v=19$m=1024,t=1,p=1$abc$def"} {
+		require.ErrorIs(t, hasher.Verify(hash, ""), ErrUnknownPasswordHash)
+	}
+}
+
+func TestNewPasswordHasherInvalid(t *testing.T) {
+	_, err := NewPasswordHasher("md5", testArgon2idParams, bcrypt.MinCost)
+	require.Error(t, err)
+
+	_, err = NewPasswordHasher(PasswordAlgorithmArgon2id, Argon2idParams{}, bcrypt.MinCost)
+	require.Error(t, err)
+
+	_, err = NewPasswordHasher(PasswordAlgorithmBcrypt, testArgon2idParams, bcrypt.MaxCost+1)
+	require.Error(t, err)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(file, []byte("# common passwords\npassword123\n\nqwertyuiop\n"), 0o600)
+	require.NoError(t, err)
+
+	policy, err := NewPasswordPolicy(8, 16, file)
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Check(RandomString(8)))
+	require.Error(t, policy.Check(RandomString(7)))
+	require.Error(t, policy.Check(RandomString(17)))
+	require.ErrorIs(t, policy.Check("password123"), ErrBreachedPassword)
+	require.ErrorIs(t, policy.Check("qwertyuiop"), ErrBreachedPassword)
+
+	// طول بر اساس کاراکتر است، نه بایت
+	require.NoError(t, policy.Check("رمزعبورخوب"))
+
+	_, err = NewPasswordPolicy(8, 16, filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// erasureBatchSize limits how many accounts one run erases
+const erasureBatchSize = 50
+
+var tracer = otel.Tracer("github.com/faezefz/SFP_website/worker")
+
+// ErasureJob erases the accounts whose erasure request passed its grace period
+type ErasureJob struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewErasureJob creates a job that checks for due requests every interval
+func NewErasureJob(store db.Store, interval time.Duration) *ErasureJob {
+	return &ErasureJob{store: store, interval: interval}
+}
+
+// Run processes due requests until ctx is cancelled
+func (job *ErasureJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := job.RunOnce(ctx); err != nil {
+			slog.Error("Error running erasure job", "error", err)
+		} else if n > 0 {
+			slog.Info("Erased accounts", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce erases the accounts of all due requests and returns how many were
+// erased. Each account is erased in its own transaction; when one fails, the
+// error is recorded on its request and the others are still erased. When ctx
+// is cancelled, the account being erased is finished but no other is started.
+func (job *ErasureJob) RunOnce(ctx context.Context) (n int, err error) {
+	ctx, span := tracer.Start(ctx, "erasure.run")
+	defer func() {
+		span.SetAttributes(attribute.Int("erasure.erased", n))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	requests, err := job.store.ListDueErasureRequests(ctx, db.ListDueErasureRequestsParams{
+		ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		Limit:        erasureBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for _, request := range requests {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := job.erase(context.WithoutCancel(ctx), request); err != nil {
+			failed++
+			job.recordFailure(context.WithoutCancel(ctx), request, err)
+			continue
+		}
+		n++
+	}
+	span.SetAttributes(attribute.Int("erasure.failed", failed))
+	return n, nil
+}
+
+// recordFailure logs a failed erasure and keeps the error on its request, so
+// the request is retried after the others on the next run
+func (job *ErasureJob) recordFailure(ctx context.Context, request db.ErasureRequest, erasureErr error) {
+	slog.Error("Error erasing account", "request_id", request.ID, "user_id", request.UserID.Int32,
+		"attempts", request.Attempts+1, "error", erasureErr)
+
+	err := job.store.RecordErasureFailure(ctx, db.RecordErasureFailureParams{
+		ID:        request.ID,
+		LastError: pgtype.Text{String: erasureErr.Error(), Valid: true},
+	})
+	if err != nil {
+		slog.Error("Error recording erasure failure", "request_id", request.ID, "error", err)
+	}
+}
+
+// erase erases the account of one request in its own span
+func (job *ErasureJob) erase(ctx context.Context, request db.ErasureRequest) error {
+	ctx, span := tracer.Start(ctx, "erasure.erase_user", trace.WithAttributes(
+		attribute.Int("erasure.request_id", int(request.ID)),
+		attribute.Int("erasure.user_id", int(request.UserID.Int32)),
+	))
+	defer span.End()
+
+	err := job.store.EraseUserTx(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	db "github.com/faezefz/SFP_website/db/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// erasureStore serves the due requests from memory; erasing failing fails
+type erasureStore struct {
+	db.Store
+
+	due      []db.ErasureRequest
+	failing  int32
+	erased   []int32
+	failures map[int32]string
+}
+
+func (store *erasureStore) ListDueErasureRequests(context.Context, db.ListDueErasureRequestsParams) ([]db.ErasureRequest, error) {
+	return store.due, nil
+}
+
+func (store *erasureStore) EraseUserTx(_ context.Context, request db.ErasureRequest) error {
+	if request.ID == store.failing {
+		return errors.New("erase failed")
+	}
+	store.erased = append(store.erased, request.ID)
+	return nil
+}
+
+func (store *erasureStore) RecordErasureFailure(_ context.Context, arg db.RecordErasureFailureParams) error {
+	store.failures[arg.ID] = arg.LastError.String
+	return nil
+}
+
+func TestRunOnceContinuesAfterFailure(t *testing.T) {
+	store := &erasureStore{failing: 2, failures: map[int32]string{}}
+	for id := int32(1); id <= 3; id++ {
+		store.due = append(store.due, db.ErasureRequest{
+			ID:           id,
+			UserID:       pgtype.Int4{Int32: id * 10, Valid: true},
+			ExecuteAfter: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		})
+	}
+
+	n, err := NewErasureJob(store, time.Minute).RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	// درخواست ناموفق بقیه را متوقف نمی‌کند و خطای آن ثبت می‌شود
+	require.Equal(t, []int32{1, 3}, store.erased)
+	require.Equal(t, map[int32]string{2: "erase failed"}, store.failures)
+}
+
+func TestRunOnceStopsWhenCancelled(t *testing.T) {
+	store := &erasureStore{failures: map[int32]string{}, due: []db.ErasureRequest{{ID: 1}, {ID: 2}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n, err := NewErasureJob(store, time.Minute).RunOnce(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.Empty(t, store.erased)
+}